@@ -5,6 +5,7 @@ import (
 	"reflect"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/luiccn/espresso-controller/internal/helpers"
 	"github.com/spf13/cobra"
@@ -45,9 +46,15 @@ func (k Key) EnvKey() string {
 
 func (k Key) BindFlag(cmd *cobra.Command) {
 	flag := FormatFlag(k.Path)
+	if _, ok := k.Default.(time.Duration); ok {
+		cmd.Flags().DurationP(flag, k.ShortFlag, viper.GetDuration(k.Path), k.Description)
+		return
+	}
 	switch reflect.ValueOf(k.Default).Kind() {
 	case reflect.Int:
 		cmd.Flags().IntP(flag, k.ShortFlag, viper.GetInt(k.Path), k.Description)
+	case reflect.Float64:
+		cmd.Flags().Float64P(flag, k.ShortFlag, viper.GetFloat64(k.Path), k.Description)
 	case reflect.String:
 		cmd.Flags().StringP(FormatFlag(k.Path), k.ShortFlag, viper.GetString(k.Path), k.Description)
 	case reflect.Slice:
@@ -0,0 +1,272 @@
+// Package brew detects shots being pulled, from the brew switch or, without
+// one, from the drop of the boiler temperature caused by cold water entering
+// the boiler.
+package brew
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/luiccn/espresso-controller/internal/espresso/gpio"
+	"github.com/luiccn/espresso-controller/internal/espresso/temperature"
+	"github.com/luiccn/espresso-controller/internal/log"
+	"go.uber.org/zap"
+)
+
+const (
+	subscriptionBufferSize = 10
+	switchPollInterval     = 50 * time.Millisecond
+)
+
+// Config selects how shots are detected
+type Config struct {
+	// GPIO connected to the brew switch, high while brewing. The boiler
+	// temperature is watched instead if negative.
+	SwitchPin int
+	// A drop of the boiler temperature by DropThreshold °C within DropWindow
+	// starts a shot, which stops once the temperature has not reached a new
+	// low for DropWindow
+	DropThreshold float32
+	DropWindow    time.Duration
+	// Time after which the shot timer stops a shot that did not stop, e.g.
+	// one started with the button
+	MaxShotDuration time.Duration
+}
+
+// Source is how a shot was detected
+type Source string
+
+const (
+	SourceSwitch      Source = "switch"
+	SourceTemperature Source = "temperature"
+	SourceButton      Source = "button"
+)
+
+type EventType string
+
+const (
+	EventStarted EventType = "started"
+	EventStopped EventType = "stopped"
+)
+
+type Event struct {
+	Type EventType
+	At   time.Time
+}
+
+// PowerSource reports whether the machine is powered on
+type PowerSource interface {
+	IsMachinePowerOn() bool
+}
+
+// SteamSource reports whether the machine is in steam mode
+type SteamSource interface {
+	IsSteaming() bool
+}
+
+// Detector publishes an event to its subscribers when a shot starts and stops
+type Detector struct {
+	c         Config
+	switchPin gpio.Pin
+	monitor   *temperature.Monitor
+	// nil unless watched, read without mu held as they take their own locks
+	powerSource PowerSource
+	steamSource SteamSource
+
+	mu                sync.Mutex
+	subscriptionChans map[uuid.UUID]chan Event
+	brewing           bool
+	// samples of the last DropWindow, oldest first
+	samples []*temperature.Sample
+	// lowest temperature of the current shot, or since drops stopped being
+	// ignored
+	low *temperature.Sample
+	// whether the temperature is still falling after drops were ignored,
+	// e.g. from the steam temperature
+	settling bool
+
+	shutdownCh chan struct{}
+}
+
+// NewDetector watches the brew switch if c.SwitchPin is not negative, or the
+// boiler monitor otherwise
+func NewDetector(c Config, driver gpio.Driver, monitor *temperature.Monitor) *Detector {
+	d := &Detector{
+		c:                 c,
+		monitor:           monitor,
+		subscriptionChans: map[uuid.UUID]chan Event{},
+		shutdownCh:        make(chan struct{}),
+	}
+	if c.SwitchPin >= 0 {
+		d.switchPin = driver.Pin(c.SwitchPin)
+		d.switchPin.Input()
+		d.switchPin.PullDown()
+	}
+	return d
+}
+
+// WatchMachine ignores temperature drops while the machine is powered off or
+// steaming, and until the temperature stops falling afterwards. Either source
+// may be nil. It must be called before Run.
+func (d *Detector) WatchMachine(power PowerSource, steam SteamSource) {
+	d.powerSource = power
+	d.steamSource = steam
+}
+
+func (d *Detector) Run() {
+	if d.switchPin != nil {
+		go d.watchSwitch()
+		return
+	}
+	go d.watchTemperature()
+}
+
+// watchSwitch polls the brew switch, debouncing it by requiring two equal
+// reads in a row
+func (d *Detector) watchSwitch() {
+	ticker := time.NewTicker(switchPollInterval)
+	defer ticker.Stop()
+	last := d.switchPin.Read()
+	for {
+		select {
+		case <-d.shutdownCh:
+			return
+		case now := <-ticker.C:
+			state := d.switchPin.Read()
+			if state == last {
+				d.mu.Lock()
+				d.setBrewing(state == gpio.High, now)
+				d.mu.Unlock()
+			}
+			last = state
+		}
+	}
+}
+
+func (d *Detector) watchTemperature() {
+	subId, samples := d.monitor.Subscribe()
+	defer d.monitor.Unsubscribe(subId)
+	for {
+		select {
+		case <-d.shutdownCh:
+			return
+		case sample, ok := <-samples:
+			if !ok {
+				return
+			}
+			ignored := d.ignoringDrops()
+			d.mu.Lock()
+			d.inspect(sample, ignored)
+			d.mu.Unlock()
+		}
+	}
+}
+
+// ignoringDrops reports whether the machine is powered off or steaming, when
+// the temperature drops without a shot being pulled, e.g. from using the steam
+// wand
+func (d *Detector) ignoringDrops() bool {
+	if d.powerSource != nil && !d.powerSource.IsMachinePowerOn() {
+		return true
+	}
+	return d.steamSource != nil && d.steamSource.IsSteaming()
+}
+
+// inspect detects a shot from a boiler temperature sample, unless drops are
+// ignored. mu must be held.
+func (d *Detector) inspect(sample *temperature.Sample, ignored bool) {
+	if ignored {
+		if d.brewing {
+			d.setBrewing(false, sample.ObservedAt)
+		}
+		d.samples = nil
+		d.low = sample
+		d.settling = true
+		return
+	}
+
+	d.samples = append(d.samples, sample)
+	for len(d.samples) > 0 && sample.ObservedAt.Sub(d.samples[0].ObservedAt) > d.c.DropWindow {
+		d.samples = d.samples[1:]
+	}
+
+	if d.brewing || d.settling {
+		if sample.Value < d.low.Value {
+			d.low = sample
+		} else if sample.ObservedAt.Sub(d.low.ObservedAt) >= d.c.DropWindow {
+			// the water stopped flowing about when the temperature bottomed
+			// out
+			d.setBrewing(false, d.low.ObservedAt)
+			d.settling = false
+		}
+		return
+	}
+
+	// the shot started after the last sample the temperature dropped from
+	for i := len(d.samples) - 1; i >= 0; i-- {
+		if s := d.samples[i]; s.Value-sample.Value >= d.c.DropThreshold {
+			d.low = sample
+			d.setBrewing(true, s.ObservedAt)
+			return
+		}
+	}
+}
+
+// setBrewing publishes an event if brewing changed. mu must be held.
+func (d *Detector) setBrewing(brewing bool, at time.Time) {
+	if brewing == d.brewing {
+		return
+	}
+	d.brewing = brewing
+	e := Event{Type: EventStopped, At: at}
+	if brewing {
+		e.Type = EventStarted
+	}
+	log.Info("Brew "+string(e.Type), zap.Time("at", at))
+	for subId, ch := range d.subscriptionChans {
+		select {
+		case ch <- e:
+		default:
+			log.Debug("Dropped brew event for slow subscriber", zap.Stringer("subId", subId))
+		}
+	}
+}
+
+// Source is how the detector detects shots
+func (d *Detector) Source() Source {
+	if d.switchPin != nil {
+		return SourceSwitch
+	}
+	return SourceTemperature
+}
+
+// IsBrewing reports whether a shot is being pulled
+func (d *Detector) IsBrewing() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.brewing
+}
+
+func (d *Detector) Subscribe() (uuid.UUID, chan Event) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	subId := uuid.New()
+	ch := make(chan Event, subscriptionBufferSize)
+	d.subscriptionChans[subId] = ch
+	return subId, ch
+}
+
+// Unsubscribe stops publishing to the subscription and closes its channel
+func (d *Detector) Unsubscribe(subId uuid.UUID) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if ch, ok := d.subscriptionChans[subId]; ok {
+		delete(d.subscriptionChans, subId)
+		close(ch)
+	}
+}
+
+func (d *Detector) Shutdown() {
+	close(d.shutdownCh)
+}
@@ -0,0 +1,123 @@
+package brew
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/luiccn/espresso-controller/internal/espresso/gpio"
+	"github.com/luiccn/espresso-controller/internal/espresso/temperature"
+)
+
+func TestDetector_inspect(t *testing.T) {
+	start := time.Date(2026, 10, 19, 7, 0, 0, 0, time.UTC)
+	at := func(s int) time.Time { return start.Add(time.Duration(s) * time.Second) }
+
+	tests := []struct {
+		name   string
+		values []float32
+		// indexes of the samples taken while drops are ignored
+		ignored []int
+		want    []Event
+	}{
+		{
+			name:   "steady",
+			values: []float32{93, 93.2, 92.9, 93.1, 93, 92.8, 93},
+		},
+		{
+			name:   "slow drift",
+			values: []float32{93, 92.6, 92.2, 91.8, 91.4, 91, 90.6, 90.2},
+		},
+		{
+			name:   "shot",
+			values: []float32{93, 93, 92, 90.5, 89.5, 89, 89.2, 90, 91, 92},
+			want: []Event{
+				{Type: EventStarted, At: at(1)},
+				{Type: EventStopped, At: at(5)},
+			},
+		},
+		{
+			name:    "steam wand",
+			values:  []float32{140, 139, 136, 133, 131, 130, 131, 133},
+			ignored: []int{0, 1, 2, 3, 4, 5, 6, 7},
+		},
+		{
+			name:    "cooling down after steaming",
+			values:  []float32{140, 138, 135, 132, 129, 126, 123, 120, 117},
+			ignored: []int{0, 1},
+		},
+		{
+			name:    "shot after cooling down from steaming",
+			values:  []float32{96, 95, 94, 93.5, 93, 93, 93, 93, 93, 91, 90, 89.5, 90, 90.5, 91},
+			ignored: []int{0},
+			want: []Event{
+				{Type: EventStarted, At: at(8)},
+				{Type: EventStopped, At: at(11)},
+			},
+		},
+		{
+			name:    "powered off during a shot",
+			values:  []float32{93, 93, 90.5, 89, 88, 87},
+			ignored: []int{4, 5},
+			want: []Event{
+				{Type: EventStarted, At: at(1)},
+				{Type: EventStopped, At: at(4)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDetector(Config{SwitchPin: -1, DropThreshold: 2, DropWindow: 3 * time.Second}, gpio.NewFakeDriver(), nil)
+			subId, events := d.Subscribe()
+			defer d.Unsubscribe(subId)
+
+			ignored := map[int]bool{}
+			for _, i := range tt.ignored {
+				ignored[i] = true
+			}
+			for i, v := range tt.values {
+				d.mu.Lock()
+				d.inspect(&temperature.Sample{Value: v, ObservedAt: at(i)}, ignored[i])
+				d.mu.Unlock()
+			}
+
+			var got []Event
+			for len(events) > 0 {
+				got = append(got, <-events)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("events = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetector_switch(t *testing.T) {
+	driver := gpio.NewFakeDriver()
+	pin := driver.FakePin(22)
+	d := NewDetector(Config{SwitchPin: 22}, driver, nil)
+	subId, events := d.Subscribe()
+	defer d.Unsubscribe(subId)
+	d.Run()
+	defer d.Shutdown()
+
+	next := func() Event {
+		select {
+		case e := <-events:
+			return e
+		case <-time.After(time.Second):
+			t.Fatal("no brew event")
+			return Event{}
+		}
+	}
+
+	pin.Set(gpio.High)
+	if e := next(); e.Type != EventStarted || !d.IsBrewing() {
+		t.Errorf("event = %v, want %s", e, EventStarted)
+	}
+	pin.Set(gpio.Low)
+	if e := next(); e.Type != EventStopped || d.IsBrewing() {
+		t.Errorf("event = %v, want %s", e, EventStopped)
+	}
+}
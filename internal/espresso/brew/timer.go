@@ -0,0 +1,197 @@
+package brew
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/luiccn/espresso-controller/internal/espresso/button"
+	"github.com/luiccn/espresso-controller/internal/log"
+	"go.uber.org/zap"
+)
+
+const timerCheckInterval = 100 * time.Millisecond
+
+// Shot is a shot being or having been pulled
+type Shot struct {
+	Start time.Time
+	// zero while the shot is being pulled
+	End    time.Time
+	Source Source
+	// when the timer learned of the shot, some seconds after its start for
+	// shots detected from the temperature
+	DetectedAt time.Time
+}
+
+// Running reports whether the shot is being pulled
+func (s Shot) Running() bool {
+	return !s.Start.IsZero() && s.End.IsZero()
+}
+
+// Duration of the shot, up to now while it is being pulled
+func (s Shot) Duration(now time.Time) time.Duration {
+	if s.Start.IsZero() {
+		return 0
+	}
+	if s.Running() {
+		return now.Sub(s.Start)
+	}
+	return s.End.Sub(s.Start)
+}
+
+// Timer times the shots started and stopped by the detector or with the power
+// button, publishing a shot to its subscribers when it starts and stops
+type Timer struct {
+	detector    *Detector
+	maxDuration time.Duration
+
+	mu                sync.Mutex
+	shot              Shot
+	subscriptionChans map[uuid.UUID]chan Shot
+	// the clock, replaced in tests
+	now func() time.Time
+
+	shutdownCh chan struct{}
+}
+
+// NewTimer stops shots after maxDuration, never if 0
+func NewTimer(detector *Detector, maxDuration time.Duration) *Timer {
+	return &Timer{
+		detector:          detector,
+		maxDuration:       maxDuration,
+		subscriptionChans: map[uuid.UUID]chan Shot{},
+		now:               time.Now,
+		shutdownCh:        make(chan struct{}),
+	}
+}
+
+func (t *Timer) Run() {
+	subId, events := t.detector.Subscribe()
+	go func() {
+		defer t.detector.Unsubscribe(subId)
+		ticker := time.NewTicker(timerCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-t.shutdownCh:
+				return
+			case e, ok := <-events:
+				if !ok {
+					return
+				}
+				t.mu.Lock()
+				t.handle(e)
+				t.mu.Unlock()
+			case <-ticker.C:
+				t.mu.Lock()
+				t.maxDurationBehaviour()
+				t.mu.Unlock()
+			}
+		}
+	}()
+}
+
+// handle starts or stops the shot on a detector event. A shot started with
+// the button is stopped by the detector, but not restarted. mu must be held.
+func (t *Timer) handle(e Event) {
+	switch e.Type {
+	case EventStarted:
+		if !t.shot.Running() {
+			t.start(t.detector.Source(), e.At)
+		}
+	case EventStopped:
+		if t.shot.Running() {
+			t.stop(e.At)
+		}
+	}
+}
+
+// maxDurationBehaviour stops a shot running for longer than the maximum
+// duration. mu must be held.
+func (t *Timer) maxDurationBehaviour() {
+	if t.maxDuration <= 0 || !t.shot.Running() {
+		return
+	}
+	if end := t.shot.Start.Add(t.maxDuration); !t.now().Before(end) {
+		log.Info("Stopping shot timer after the maximum shot duration")
+		t.stop(end)
+	}
+}
+
+// start starts timing a new shot. mu must be held.
+func (t *Timer) start(source Source, at time.Time) {
+	t.shot = Shot{Start: at, Source: source, DetectedAt: t.now()}
+	log.Info("Shot started", zap.String("source", string(source)), zap.Time("at", at))
+	t.publish()
+}
+
+// stop stops timing the shot. mu must be held.
+func (t *Timer) stop(at time.Time) {
+	if at.Before(t.shot.Start) {
+		at = t.shot.Start
+	}
+	t.shot.End = at
+	log.Info("Shot stopped", zap.Duration("duration", t.shot.Duration(at)))
+	t.publish()
+}
+
+// publish sends the shot to the subscribers. mu must be held.
+func (t *Timer) publish() {
+	for subId, ch := range t.subscriptionChans {
+		select {
+		case ch <- t.shot:
+		default:
+			log.Debug("Dropped shot for slow subscriber", zap.Stringer("subId", subId))
+		}
+	}
+}
+
+// Toggle starts timing a shot, or stops the one being timed
+func (t *Timer) Toggle(source Source) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.shot.Running() {
+		t.stop(t.now())
+	} else {
+		t.start(source, t.now())
+	}
+}
+
+// Shot returns the shot being pulled, or the last one. Its Start is zero if
+// there was none yet.
+func (t *Timer) Shot() Shot {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.shot
+}
+
+// HandleButton starts or stops the shot timer on the power button's
+// start_shot_timer gesture
+func (t *Timer) HandleButton(b *button.Button) {
+	b.Handle(button.ActionStartShotTimer, func() {
+		t.Toggle(SourceButton)
+	})
+}
+
+func (t *Timer) Subscribe() (uuid.UUID, chan Shot) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	subId := uuid.New()
+	ch := make(chan Shot, subscriptionBufferSize)
+	t.subscriptionChans[subId] = ch
+	return subId, ch
+}
+
+// Unsubscribe stops publishing to the subscription and closes its channel
+func (t *Timer) Unsubscribe(subId uuid.UUID) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if ch, ok := t.subscriptionChans[subId]; ok {
+		delete(t.subscriptionChans, subId)
+		close(ch)
+	}
+}
+
+func (t *Timer) Shutdown() {
+	close(t.shutdownCh)
+}
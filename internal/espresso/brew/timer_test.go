@@ -0,0 +1,142 @@
+package brew
+
+import (
+	"testing"
+	"time"
+
+	"github.com/luiccn/espresso-controller/internal/espresso/gpio"
+)
+
+func TestTimer_handle(t *testing.T) {
+	start := time.Date(2026, 10, 19, 7, 0, 0, 0, time.UTC)
+	at := func(s int) time.Time { return start.Add(time.Duration(s) * time.Second) }
+
+	// detector events arrive some seconds after the shot started
+	const detectionDelay = 4
+	type step struct {
+		// a detector event, or a press of the button if empty
+		event EventType
+		at    int
+	}
+	tests := []struct {
+		name      string
+		steps     []step
+		want      Shot
+		published int
+	}{
+		{
+			name:      "detected",
+			steps:     []step{{EventStarted, 0}, {EventStopped, 27}},
+			want:      Shot{Start: at(0), End: at(27), Source: SourceTemperature, DetectedAt: at(4)},
+			published: 2,
+		},
+		{
+			name:      "running",
+			steps:     []step{{EventStarted, 0}},
+			want:      Shot{Start: at(0), Source: SourceTemperature, DetectedAt: at(4)},
+			published: 1,
+		},
+		{
+			name:      "button",
+			steps:     []step{{"", 0}, {"", 25}},
+			want:      Shot{Start: at(0), End: at(25), Source: SourceButton, DetectedAt: at(0)},
+			published: 2,
+		},
+		{
+			name:      "started with the button and stopped by the detector",
+			steps:     []step{{"", 0}, {EventStarted, 1}, {EventStopped, 28}},
+			want:      Shot{Start: at(0), End: at(28), Source: SourceButton, DetectedAt: at(0)},
+			published: 2,
+		},
+		{
+			name:      "stopped before the detector noticed the shot",
+			steps:     []step{{EventStarted, 2}, {"", 1}},
+			want:      Shot{Start: at(2), End: at(2), Source: SourceTemperature, DetectedAt: at(6)},
+			published: 2,
+		},
+		{
+			name:      "second shot",
+			steps:     []step{{EventStarted, 0}, {EventStopped, 27}, {EventStarted, 60}, {EventStopped, 90}},
+			want:      Shot{Start: at(60), End: at(90), Source: SourceTemperature, DetectedAt: at(64)},
+			published: 4,
+		},
+		{
+			name:  "stop without a shot",
+			steps: []step{{EventStopped, 27}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDetector(Config{SwitchPin: -1}, gpio.NewFakeDriver(), nil)
+			timer := NewTimer(d, 0)
+			subId, shots := timer.Subscribe()
+			defer timer.Unsubscribe(subId)
+
+			for _, s := range tt.steps {
+				if s.event == "" {
+					timer.now = func() time.Time { return at(s.at) }
+					timer.Toggle(SourceButton)
+					continue
+				}
+				timer.now = func() time.Time { return at(s.at + detectionDelay) }
+				timer.mu.Lock()
+				timer.handle(Event{Type: s.event, At: at(s.at)})
+				timer.mu.Unlock()
+			}
+
+			if got := timer.Shot(); got != tt.want {
+				t.Errorf("Shot() = %+v, want %+v", got, tt.want)
+			}
+			if len(shots) != tt.published {
+				t.Errorf("published %d shots, want %d", len(shots), tt.published)
+			}
+		})
+	}
+}
+
+func TestTimer_maxDurationBehaviour(t *testing.T) {
+	start := time.Date(2026, 10, 19, 7, 0, 0, 0, time.UTC)
+	now := start
+	timer := NewTimer(NewDetector(Config{SwitchPin: -1}, gpio.NewFakeDriver(), nil), time.Minute)
+	timer.now = func() time.Time { return now }
+
+	timer.Toggle(SourceButton)
+	check := func(elapsed time.Duration, wantRunning bool) {
+		t.Helper()
+		now = start.Add(elapsed)
+		timer.mu.Lock()
+		timer.maxDurationBehaviour()
+		timer.mu.Unlock()
+		if shot := timer.Shot(); shot.Running() != wantRunning {
+			t.Errorf("shot after %s = %+v, want running %v", elapsed, shot, wantRunning)
+		}
+	}
+	check(59*time.Second, true)
+	check(61*time.Second, false)
+	if d := timer.Shot().Duration(now); d != time.Minute {
+		t.Errorf("Duration() = %s, want the maximum duration", d)
+	}
+}
+
+func TestShot_Duration(t *testing.T) {
+	start := time.Date(2026, 10, 19, 7, 0, 0, 0, time.UTC)
+	now := start.Add(time.Minute)
+	tests := []struct {
+		name string
+		shot Shot
+		want time.Duration
+	}{
+		{name: "none", shot: Shot{}, want: 0},
+		{name: "running", shot: Shot{Start: start.Add(50 * time.Second)}, want: 10 * time.Second},
+		{name: "stopped", shot: Shot{Start: start, End: start.Add(28 * time.Second)}, want: 28 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.shot.Duration(now); got != tt.want {
+				t.Errorf("Duration() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+// Package button turns presses of a push button into gestures, a short, long
+// or double press, and runs the action configured for each gesture.
+package button
+
+import (
+	"sync"
+	"time"
+
+	"github.com/luiccn/espresso-controller/internal/espresso/gpio"
+	"github.com/luiccn/espresso-controller/internal/log"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const (
+	pollInterval     = 10 * time.Millisecond
+	debounceDuration = 30 * time.Millisecond
+)
+
+type Gesture string
+
+const (
+	GestureShortPress  Gesture = "short_press"
+	GestureLongPress   Gesture = "long_press"
+	GestureDoublePress Gesture = "double_press"
+)
+
+// Action is what a gesture does. Actions are handled by whoever registered
+// for them with Handle, e.g. the power manager.
+type Action string
+
+const (
+	ActionNone             Action = "none"
+	ActionTogglePower      Action = "toggle"
+	ActionTotalOff         Action = "total_off"
+	ActionSuppressSchedule Action = "suppress_schedule"
+	ActionToggleSteam      Action = "toggle_steam"
+	ActionStartShotTimer   Action = "start_shot_timer"
+)
+
+var actions = []Action{ActionNone, ActionTogglePower, ActionTotalOff, ActionSuppressSchedule, ActionToggleSteam, ActionStartShotTimer}
+
+func ParseAction(s string) (Action, error) {
+	if s == "" {
+		return ActionNone, nil
+	}
+	for _, a := range actions {
+		if string(a) == s {
+			return a, nil
+		}
+	}
+	return "", errors.Errorf("invalid button action %q, must be one of %v", s, actions)
+}
+
+// Config maps the gestures to actions and sets how they are told apart
+type Config struct {
+	ShortPress  Action
+	LongPress   Action
+	DoublePress Action
+	// A press held this long is a long press
+	LongPressDuration time.Duration
+	// A second press starting within this time of the end of a short press
+	// makes a double press. Short presses are run at once if DoublePress is
+	// none, and after this time otherwise.
+	DoublePressWindow time.Duration
+}
+
+// Button polls the pin of a push button, high while pressed, and runs the
+// handler of the action mapped to each gesture. Handlers run on the button's
+// own goroutine, one at a time.
+type Button struct {
+	pin      gpio.Pin
+	actions  map[Gesture]Action
+	detector detector
+
+	mu       sync.Mutex
+	handlers map[Action]func()
+
+	shutdownCh chan struct{}
+}
+
+func NewButton(c Config, driver gpio.Driver, pinNum int) (*Button, error) {
+	if c.LongPressDuration <= 0 || c.DoublePressWindow <= 0 {
+		return nil, errors.New("button long press duration and double press window must be positive")
+	}
+	mapped := map[Gesture]Action{
+		GestureShortPress:  c.ShortPress,
+		GestureLongPress:   c.LongPress,
+		GestureDoublePress: c.DoublePress,
+	}
+	actions := map[Gesture]Action{}
+	for g, s := range mapped {
+		a, err := ParseAction(string(s))
+		if err != nil {
+			return nil, errors.Wrapf(err, "button %s", g)
+		}
+		actions[g] = a
+	}
+
+	pin := driver.Pin(pinNum)
+	pin.Input()
+	pin.PullDown()
+
+	return &Button{
+		pin:     pin,
+		actions: actions,
+		detector: detector{
+			longPress:   c.LongPressDuration,
+			doublePress: c.DoublePressWindow,
+			waitDouble:  actions[GestureDoublePress] != ActionNone,
+		},
+		handlers:   map[Action]func(){},
+		shutdownCh: make(chan struct{}),
+	}, nil
+}
+
+// Handle runs fn whenever a gesture mapped to the action is made, replacing
+// the previous handler of the action
+func (b *Button) Handle(a Action, fn func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[a] = fn
+}
+
+func (b *Button) Run() {
+	go func() {
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		d := debouncer{}
+		for {
+			select {
+			case <-b.shutdownCh:
+				return
+			case now := <-ticker.C:
+				pressed := d.update(b.pin.Read() == gpio.High, now)
+				if g, ok := b.detector.update(pressed, now); ok {
+					b.run(g)
+				}
+			}
+		}
+	}()
+}
+
+func (b *Button) run(g Gesture) {
+	a := b.actions[g]
+	log.Info("Button pressed", zap.String("gesture", string(g)), zap.String("action", string(a)))
+	if a == ActionNone {
+		return
+	}
+	b.mu.Lock()
+	fn, ok := b.handlers[a]
+	b.mu.Unlock()
+	if !ok {
+		log.Info("Ignoring button action without a handler", zap.String("action", string(a)))
+		return
+	}
+	fn()
+}
+
+func (b *Button) Shutdown() {
+	close(b.shutdownCh)
+}
+
+// debouncer ignores changes of the pin shorter than debounceDuration, e.g.
+// the contacts bouncing
+type debouncer struct {
+	stable     bool
+	last       bool
+	lastChange time.Time
+}
+
+func (d *debouncer) update(pressed bool, now time.Time) bool {
+	if pressed != d.last {
+		d.last = pressed
+		d.lastChange = now
+	}
+	if now.Sub(d.lastChange) >= debounceDuration {
+		d.stable = d.last
+	}
+	return d.stable
+}
+
+type detectorState int
+
+const (
+	idle detectorState = iota
+	pressed
+	// released after a short press, waiting for a second press
+	releasedOnce
+	// a gesture was made, waiting for the button to be released
+	held
+)
+
+// detector tells gestures apart from the debounced state of the button,
+// updated on every poll
+type detector struct {
+	longPress   time.Duration
+	doublePress time.Duration
+	waitDouble  bool
+
+	state detectorState
+	since time.Time
+}
+
+func (d *detector) update(isPressed bool, now time.Time) (Gesture, bool) {
+	switch d.state {
+	case idle:
+		if isPressed {
+			d.state, d.since = pressed, now
+		}
+	case pressed:
+		if isPressed {
+			if now.Sub(d.since) >= d.longPress {
+				d.state = held
+				return GestureLongPress, true
+			}
+		} else if d.waitDouble {
+			d.state, d.since = releasedOnce, now
+		} else {
+			d.state = idle
+			return GestureShortPress, true
+		}
+	case releasedOnce:
+		if isPressed {
+			d.state = held
+			return GestureDoublePress, true
+		} else if now.Sub(d.since) >= d.doublePress {
+			d.state = idle
+			return GestureShortPress, true
+		}
+	case held:
+		if !isPressed {
+			d.state = idle
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,155 @@
+package button
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/luiccn/espresso-controller/internal/espresso/gpio"
+)
+
+// press is the button held from Down to Up, in ms from the start
+type press struct {
+	Down int
+	Up   int
+}
+
+func TestDetector(t *testing.T) {
+	tests := []struct {
+		name       string
+		waitDouble bool
+		presses    []press
+		want       []Gesture
+	}{
+		{
+			name:    "short press",
+			presses: []press{{100, 250}},
+			want:    []Gesture{GestureShortPress},
+		},
+		{
+			name:       "short press waiting for a second one",
+			waitDouble: true,
+			presses:    []press{{100, 250}},
+			want:       []Gesture{GestureShortPress},
+		},
+		{
+			name:    "long press",
+			presses: []press{{100, 2500}},
+			want:    []Gesture{GestureLongPress},
+		},
+		{
+			name:       "double press",
+			waitDouble: true,
+			presses:    []press{{100, 200}, {400, 500}},
+			want:       []Gesture{GestureDoublePress},
+		},
+		{
+			name:    "two short presses without double press",
+			presses: []press{{100, 200}, {400, 500}},
+			want:    []Gesture{GestureShortPress, GestureShortPress},
+		},
+		{
+			name:       "two short presses too far apart",
+			waitDouble: true,
+			presses:    []press{{100, 200}, {800, 900}},
+			want:       []Gesture{GestureShortPress, GestureShortPress},
+		},
+		{
+			name:       "long press after a short press",
+			waitDouble: true,
+			presses:    []press{{100, 200}, {1000, 2500}},
+			want:       []Gesture{GestureShortPress, GestureLongPress},
+		},
+	}
+
+	start := time.Date(2026, 10, 19, 7, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := detector{longPress: time.Second, doublePress: 400 * time.Millisecond, waitDouble: tt.waitDouble}
+			var got []Gesture
+			for ms := 0; ms < 4000; ms += 10 {
+				isPressed := false
+				for _, p := range tt.presses {
+					isPressed = isPressed || ms >= p.Down && ms < p.Up
+				}
+				if g, ok := d.update(isPressed, start.Add(time.Duration(ms)*time.Millisecond)); ok {
+					got = append(got, g)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("gestures = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDebouncer(t *testing.T) {
+	start := time.Date(2026, 10, 19, 7, 0, 0, 0, time.UTC)
+	d := debouncer{}
+	// bouncing contacts, then held
+	reads := []bool{true, false, true, false, true, true, true, true, true}
+	var got []bool
+	for i, r := range reads {
+		got = append(got, d.update(r, start.Add(time.Duration(i)*pollInterval)))
+	}
+	want := []bool{false, false, false, false, false, false, false, true, true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("debounced = %v, want %v", got, want)
+	}
+}
+
+func TestNewButton(t *testing.T) {
+	c := Config{
+		ShortPress:        ActionTogglePower,
+		LongPress:         "reboot",
+		LongPressDuration: time.Second,
+		DoublePressWindow: 400 * time.Millisecond,
+	}
+	if _, err := NewButton(c, gpio.NewFakeDriver(), 17); err == nil {
+		t.Errorf("NewButton() with an unknown action should fail")
+	}
+	c.LongPress = ActionTotalOff
+	if _, err := NewButton(c, gpio.NewFakeDriver(), 17); err != nil {
+		t.Errorf("NewButton() error = %v", err)
+	}
+}
+
+func TestButton_Run(t *testing.T) {
+	driver := gpio.NewFakeDriver()
+	b, err := NewButton(Config{
+		ShortPress:        ActionTogglePower,
+		LongPress:         ActionTotalOff,
+		LongPressDuration: 200 * time.Millisecond,
+		DoublePressWindow: 200 * time.Millisecond,
+	}, driver, 17)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actions := make(chan Action, 2)
+	b.Handle(ActionTogglePower, func() { actions <- ActionTogglePower })
+	b.Handle(ActionTotalOff, func() { actions <- ActionTotalOff })
+	b.Run()
+	defer b.Shutdown()
+
+	pin := driver.FakePin(17)
+	for _, tt := range []struct {
+		held time.Duration
+		want Action
+	}{
+		{held: 80 * time.Millisecond, want: ActionTogglePower},
+		{held: 400 * time.Millisecond, want: ActionTotalOff},
+	} {
+		pin.Set(gpio.High)
+		time.Sleep(tt.held)
+		pin.Set(gpio.Low)
+		select {
+		case a := <-actions:
+			if a != tt.want {
+				t.Errorf("held %s: action = %s, want %s", tt.held, a, tt.want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("held %s: no action, want %s", tt.held, tt.want)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
@@ -0,0 +1,270 @@
+// Package event_log keeps an append-only log of what happened to the machine,
+// e.g. why it was powered on, persisted as JSON lines in rotated files.
+package event_log
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+type Type string
+
+const (
+	TypePowerOn            Type = "power_on"
+	TypePowerOff           Type = "power_off"
+	TypeScheduleSuppressed Type = "schedule_suppressed"
+	TypeAutoOff            Type = "auto_off"
+	TypeTotalOff           Type = "total_off"
+	TypeSetpointChanged    Type = "setpoint_changed"
+	TypeParametersChanged  Type = "parameters_changed"
+	TypeModeChanged        Type = "mode_changed"
+	TypeFault              Type = "fault"
+	TypeFaultReset         Type = "fault_reset"
+)
+
+// Event is an entry of the log. Cause is the typed reason of the event, e.g.
+// "power_button" or "schedule_end", and Details hold event specific values.
+type Event struct {
+	Id      uint64
+	At      time.Time
+	Type    Type
+	Cause   string            `json:",omitempty"`
+	Message string            `json:",omitempty"`
+	Details map[string]string `json:",omitempty"`
+}
+
+// Config sets where the log is written and how it is rotated
+type Config struct {
+	// File the events are appended to. Rotated files are kept next to it.
+	Path string
+	// Size in MB at which the file is rotated
+	MaxSize int
+	// Number of rotated files kept
+	MaxBackups int
+}
+
+const (
+	defaultMaxSize   = 1
+	defaultLimit     = 100
+	maxLimit         = 1000
+	maxLineSize      = 1024 * 1024
+	backupTimeFormat = "2006-01-02T15-04-05.000"
+)
+
+// Log appends events to the file, rotating it once it reaches the maximum
+// size. It is safe for concurrent use.
+type Log struct {
+	path string
+
+	mu     sync.Mutex
+	writer *lumberjack.Logger
+	lastId uint64
+}
+
+// Open opens the log at the path, continuing the ids of the events already in
+// it
+func Open(c Config) (*Log, error) {
+	if c.Path == "" {
+		return nil, errors.New("event log path is empty")
+	}
+	if err := os.MkdirAll(filepath.Dir(c.Path), 0755); err != nil {
+		return nil, errors.Wrap(err, "creating event log directory")
+	}
+	maxSize := c.MaxSize
+	if maxSize <= 0 {
+		maxSize = defaultMaxSize
+	}
+	l := &Log{
+		path: c.Path,
+		writer: &lumberjack.Logger{
+			Filename:   c.Path,
+			MaxSize:    maxSize,
+			MaxBackups: c.MaxBackups,
+		},
+	}
+
+	events, err := l.read()
+	if err != nil {
+		return nil, err
+	}
+	if len(events) > 0 {
+		l.lastId = events[len(events)-1].Id
+	}
+	return l, nil
+}
+
+// Append assigns the event the next id, and the current time if it has none,
+// and writes it
+func (l *Log) Append(e Event) (Event, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if e.At.IsZero() {
+		e.At = time.Now()
+	}
+	e.Id = l.lastId + 1
+	line, err := json.Marshal(e)
+	if err != nil {
+		return Event{}, errors.Wrap(err, "encoding event")
+	}
+	if _, err := l.writer.Write(append(line, '\n')); err != nil {
+		return Event{}, errors.Wrap(err, "writing event")
+	}
+	l.lastId = e.Id
+	return e, nil
+}
+
+// Query selects events, newest first. The zero Query selects the last
+// defaultLimit events of any type.
+type Query struct {
+	// events at or after From and before To, unbounded if zero
+	From time.Time
+	To   time.Time
+	// any type if empty
+	Types []Type
+	// events with an id below Before, the next page token of the previous
+	// page, from the newest if zero
+	Before uint64
+	Limit  int
+}
+
+// Page is a page of events, newest first. Next is the Before of the next page,
+// zero if this is the last one.
+type Page struct {
+	Events []Event
+	Next   uint64
+}
+
+// Query reads the events matching the query from the log and its rotated
+// files
+func (l *Log) Query(q Query) (Page, error) {
+	limit := q.Limit
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	if limit > maxLimit {
+		return Page{}, errors.Errorf("limit must be in range [1, %d]", maxLimit)
+	}
+	if !q.From.IsZero() && !q.To.IsZero() && !q.From.Before(q.To) {
+		return Page{}, errors.New("the start of the time range must be before its end")
+	}
+	types := map[Type]bool{}
+	for _, t := range q.Types {
+		types[t] = true
+	}
+
+	l.mu.Lock()
+	events, err := l.read()
+	l.mu.Unlock()
+	if err != nil {
+		return Page{}, err
+	}
+
+	page := Page{}
+	for i := len(events) - 1; i >= 0; i-- {
+		e := events[i]
+		if q.Before != 0 && e.Id >= q.Before {
+			continue
+		}
+		if !q.From.IsZero() && e.At.Before(q.From) || !q.To.IsZero() && !e.At.Before(q.To) {
+			continue
+		}
+		if len(types) > 0 && !types[e.Type] {
+			continue
+		}
+		if len(page.Events) == limit {
+			page.Next = page.Events[limit-1].Id
+			break
+		}
+		page.Events = append(page.Events, e)
+	}
+	return page, nil
+}
+
+// read returns the events of the rotated files and the log, oldest first. mu
+// must be held.
+func (l *Log) read() ([]Event, error) {
+	files, err := l.backups()
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, l.path)
+
+	var events []Event
+	for _, file := range files {
+		fileEvents, err := readFile(file)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, fileEvents...)
+	}
+	return events, nil
+}
+
+// backups returns the rotated files, oldest first. Their names end with the
+// time they were rotated at, e.g. events-2026-10-16T07-00-00.000.jsonl.
+func (l *Log) backups() ([]string, error) {
+	ext := filepath.Ext(l.path)
+	prefix := strings.TrimSuffix(filepath.Base(l.path), ext) + "-"
+	infos, err := os.ReadDir(filepath.Dir(l.path))
+	if err != nil {
+		return nil, errors.Wrap(err, "listing rotated event logs")
+	}
+
+	var backups []string
+	for _, info := range infos {
+		name := info.Name()
+		if info.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		if _, err := time.Parse(backupTimeFormat, strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext)); err != nil {
+			continue
+		}
+		backups = append(backups, filepath.Join(filepath.Dir(l.path), name))
+	}
+	sort.Strings(backups)
+	return backups, nil
+}
+
+// readFile reads the events of a file, skipping lines that cannot be decoded,
+// e.g. one cut short by a power loss
+func readFile(path string) ([]Event, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "opening event log")
+	}
+	defer f.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		events = append(events, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrapf(err, "reading event log %s", path)
+	}
+	return events, nil
+}
+
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.writer.Close()
+}
@@ -0,0 +1,185 @@
+package event_log
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func openTestLog(t *testing.T) (*Log, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "espresso", "events.jsonl")
+	l, err := Open(Config{Path: path, MaxBackups: 3})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+	return l, path
+}
+
+func ids(events []Event) []uint64 {
+	var ids []uint64
+	for _, e := range events {
+		ids = append(ids, e.Id)
+	}
+	return ids
+}
+
+func TestLog_Append(t *testing.T) {
+	l, path := openTestLog(t)
+
+	at := time.Date(2026, 10, 19, 6, 0, 0, 0, time.UTC)
+	e, err := l.Append(Event{At: at, Type: TypePowerOn, Cause: "schedule_start", Details: map[string]string{"to": "scheduled_on"}})
+	if err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	if e.Id != 1 {
+		t.Errorf("Append() id = %d, want 1", e.Id)
+	}
+	if e, _ := l.Append(Event{Type: TypePowerOff}); e.Id != 2 || e.At.IsZero() {
+		t.Errorf("Append() = %+v, want id 2 at the current time", e)
+	}
+	l.Close()
+
+	// a line cut short by a power loss
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"Id":3,"At":"2026-10`)
+	f.Close()
+
+	reopened, err := Open(Config{Path: path})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer reopened.Close()
+	if e, _ := reopened.Append(Event{Type: TypeTotalOff}); e.Id != 3 {
+		t.Errorf("Append() after reopening id = %d, want 3", e.Id)
+	}
+
+	page, err := reopened.Query(Query{Types: []Type{TypePowerOn}})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	want := []Event{{Id: 1, At: at, Type: TypePowerOn, Cause: "schedule_start", Details: map[string]string{"to": "scheduled_on"}}}
+	if !reflect.DeepEqual(page.Events, want) {
+		t.Errorf("Query() = %+v, want %+v", page.Events, want)
+	}
+}
+
+func TestLog_Query(t *testing.T) {
+	l, _ := openTestLog(t)
+
+	start := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	types := []Type{TypePowerOn, TypePowerOff, TypeFault}
+	for i := 0; i < 30; i++ {
+		if _, err := l.Append(Event{At: start.Add(time.Duration(i) * time.Hour), Type: types[i%len(types)]}); err != nil {
+			t.Fatal(err)
+		}
+		if i == 9 || i == 19 {
+			if err := l.writer.Rotate(); err != nil {
+				t.Fatal(err)
+			}
+			// rotated files are named after the time they were rotated at
+			time.Sleep(2 * time.Millisecond)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		q        Query
+		wantIds  []uint64
+		wantNext uint64
+	}{
+		{
+			name:     "newest first",
+			q:        Query{Limit: 3},
+			wantIds:  []uint64{30, 29, 28},
+			wantNext: 28,
+		},
+		{
+			name:     "next page",
+			q:        Query{Limit: 3, Before: 28},
+			wantIds:  []uint64{27, 26, 25},
+			wantNext: 25,
+		},
+		{
+			name:    "across rotated files",
+			q:       Query{From: start.Add(8 * time.Hour), To: start.Add(12 * time.Hour)},
+			wantIds: []uint64{12, 11, 10, 9},
+		},
+		{
+			name:     "by type",
+			q:        Query{Types: []Type{TypeFault}, Limit: 4},
+			wantIds:  []uint64{30, 27, 24, 21},
+			wantNext: 21,
+		},
+		{
+			name:    "last page",
+			q:       Query{Types: []Type{TypeFault, TypePowerOn}, Before: 5},
+			wantIds: []uint64{4, 3, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := l.Query(tt.q)
+			if err != nil {
+				t.Fatalf("Query() error = %v", err)
+			}
+			if got := ids(page.Events); !reflect.DeepEqual(got, tt.wantIds) {
+				t.Errorf("Query() ids = %v, want %v", got, tt.wantIds)
+			}
+			if page.Next != tt.wantNext {
+				t.Errorf("Query() next = %d, want %d", page.Next, tt.wantNext)
+			}
+		})
+	}
+
+	if _, err := l.Query(Query{Limit: maxLimit + 1}); err == nil {
+		t.Errorf("Query() with a limit above %d should fail", maxLimit)
+	}
+	if _, err := l.Query(Query{From: start, To: start}); err == nil {
+		t.Errorf("Query() with an empty time range should fail")
+	}
+}
+
+func TestLog_rotation(t *testing.T) {
+	l, path := openTestLog(t)
+	for i := 0; i < 6; i++ {
+		if _, err := l.Append(Event{Type: TypePowerOn}); err != nil {
+			t.Fatal(err)
+		}
+		if err := l.writer.Rotate(); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	// lumberjack removes the oldest backups in the background
+	deadline := time.Now().Add(time.Second)
+	for {
+		backups, err := l.backups()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(backups) == 3 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("backups = %v, want 3 of them next to %s", backups, path)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	page, err := l.Query(Query{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := ids(page.Events), []uint64{6, 5, 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Query() after rotation ids = %v, want %v", got, want)
+	}
+}
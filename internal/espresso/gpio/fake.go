@@ -0,0 +1,123 @@
+package gpio
+
+import (
+	"sync"
+)
+
+// FakeDriver is an in-memory Driver. Output pins read back the last written
+// state, input pins read whatever was last Set on them, falling back to the
+// level of their pull resistor.
+type FakeDriver struct {
+	mu   sync.Mutex
+	pins map[int]*FakePin
+}
+
+func NewFakeDriver() *FakeDriver {
+	return &FakeDriver{
+		pins: map[int]*FakePin{},
+	}
+}
+
+func (d *FakeDriver) Open() error {
+	return nil
+}
+
+func (d *FakeDriver) Close() error {
+	return nil
+}
+
+func (d *FakeDriver) Pin(num int) Pin {
+	return d.FakePin(num)
+}
+
+// FakePin returns the pin with the given number, so that tests can drive its
+// inputs and inspect its outputs.
+func (d *FakeDriver) FakePin(num int) *FakePin {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	p, ok := d.pins[num]
+	if !ok {
+		p = &FakePin{num: num}
+		d.pins[num] = p
+	}
+	return p
+}
+
+type FakePin struct {
+	mu     sync.Mutex
+	num    int
+	output bool
+	pull   State
+	state  State
+	isSet  bool
+	writes int
+}
+
+func (p *FakePin) Input() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.output = false
+}
+
+func (p *FakePin) Output() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.output = true
+}
+
+func (p *FakePin) PullUp() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pull = High
+}
+
+func (p *FakePin) PullDown() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pull = Low
+}
+
+func (p *FakePin) High() {
+	p.write(High)
+}
+
+func (p *FakePin) Low() {
+	p.write(Low)
+}
+
+func (p *FakePin) write(s State) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.state = s
+	p.isSet = true
+	p.writes++
+}
+
+func (p *FakePin) Read() State {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.output && !p.isSet {
+		return p.pull
+	}
+	return p.state
+}
+
+// Set drives the pin to the given state from the outside, e.g. to simulate
+// a button press on an input pin.
+func (p *FakePin) Set(s State) {
+	p.write(s)
+}
+
+// IsOutput reports whether the pin was last configured as an output.
+func (p *FakePin) IsOutput() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.output
+}
+
+// Writes returns the number of times the pin's state was written.
+func (p *FakePin) Writes() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.writes
+}
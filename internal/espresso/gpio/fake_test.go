@@ -0,0 +1,84 @@
+package gpio
+
+import (
+	"testing"
+)
+
+func TestFakePin_Read(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(p *FakePin)
+		want  State
+	}{
+		{
+			name:  "Unconfigured pin reads low",
+			setup: func(p *FakePin) {},
+			want:  Low,
+		},
+		{
+			name: "Input pin reads its pull-up",
+			setup: func(p *FakePin) {
+				p.Input()
+				p.PullUp()
+			},
+			want: High,
+		},
+		{
+			name: "Input pin reads externally set state over its pull",
+			setup: func(p *FakePin) {
+				p.Input()
+				p.PullDown()
+				p.Set(High)
+			},
+			want: High,
+		},
+		{
+			name: "Output pin reads back last written state",
+			setup: func(p *FakePin) {
+				p.Output()
+				p.High()
+				p.Low()
+			},
+			want: Low,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewFakeDriver().FakePin(1)
+			tt.setup(p)
+			if got := p.Read(); got != tt.want {
+				t.Errorf("FakePin.Read() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFakeDriver_PinIsShared(t *testing.T) {
+	d := NewFakeDriver()
+	d.Pin(4).High()
+	if got := d.Pin(4).Read(); got != High {
+		t.Errorf("FakeDriver.Pin(4).Read() = %v, want %v", got, High)
+	}
+	if got := d.Pin(5).Read(); got != Low {
+		t.Errorf("FakeDriver.Pin(5).Read() = %v, want %v", got, Low)
+	}
+}
+
+func TestNewDriver(t *testing.T) {
+	tests := []struct {
+		backend string
+		wantErr bool
+	}{
+		{backend: BackendRpio},
+		{backend: BackendFake},
+		{backend: ""},
+		{backend: "sysfs", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.backend, func(t *testing.T) {
+			if _, err := NewDriver(tt.backend); (err != nil) != tt.wantErr {
+				t.Errorf("NewDriver(%q) error = %v, wantErr %v", tt.backend, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+// Package gpio abstracts the GPIO pins used to drive the espresso machine so
+// that the controller can run against real hardware (rpio) or an in-memory
+// fake, e.g. on a laptop, in CI or in unit tests.
+package gpio
+
+import (
+	"fmt"
+)
+
+const (
+	// BackendRpio drives the Raspberry Pi's GPIO through /dev/gpiomem.
+	BackendRpio = "rpio"
+	// BackendFake keeps pin state in memory.
+	BackendFake = "fake"
+)
+
+type State uint8
+
+const (
+	Low State = iota
+	High
+)
+
+func (s State) String() string {
+	if s == High {
+		return "high"
+	}
+	return "low"
+}
+
+// Pin is a single GPIO pin.
+type Pin interface {
+	Input()
+	Output()
+	PullUp()
+	PullDown()
+	High()
+	Low()
+	Read() State
+}
+
+// Driver provides access to the GPIO pins of a board. Open must be called
+// before any pin is used, and Close once all pins are released.
+type Driver interface {
+	Open() error
+	Close() error
+	Pin(num int) Pin
+}
+
+// NewDriver returns the driver for the given backend name.
+func NewDriver(backend string) (Driver, error) {
+	switch backend {
+	case BackendRpio, "":
+		return NewRpioDriver(), nil
+	case BackendFake:
+		return NewFakeDriver(), nil
+	default:
+		return nil, fmt.Errorf("unknown gpio backend %q, expected one of [%s, %s]", backend, BackendRpio, BackendFake)
+	}
+}
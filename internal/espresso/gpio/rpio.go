@@ -0,0 +1,57 @@
+package gpio
+
+import (
+	"github.com/stianeikeland/go-rpio/v4"
+)
+
+// RpioDriver accesses the Raspberry Pi's GPIO registers through rpio.
+type RpioDriver struct{}
+
+func NewRpioDriver() *RpioDriver {
+	return &RpioDriver{}
+}
+
+func (d *RpioDriver) Open() error {
+	return rpio.Open()
+}
+
+func (d *RpioDriver) Close() error {
+	return rpio.Close()
+}
+
+func (d *RpioDriver) Pin(num int) Pin {
+	return rpioPin(num)
+}
+
+type rpioPin rpio.Pin
+
+func (p rpioPin) Input() {
+	rpio.Pin(p).Input()
+}
+
+func (p rpioPin) Output() {
+	rpio.Pin(p).Output()
+}
+
+func (p rpioPin) PullUp() {
+	rpio.Pin(p).PullUp()
+}
+
+func (p rpioPin) PullDown() {
+	rpio.Pin(p).PullDown()
+}
+
+func (p rpioPin) High() {
+	rpio.Pin(p).High()
+}
+
+func (p rpioPin) Low() {
+	rpio.Pin(p).Low()
+}
+
+func (p rpioPin) Read() State {
+	if rpio.Pin(p).Read() == rpio.High {
+		return High
+	}
+	return Low
+}
@@ -2,16 +2,30 @@ package espresso
 
 import (
 	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
 
 	"github.com/golang/protobuf/ptypes"
+	"github.com/google/uuid"
+	"github.com/luiccn/espresso-controller/internal/espresso/brew"
+	"github.com/luiccn/espresso-controller/internal/espresso/event_log"
 	"github.com/luiccn/espresso-controller/internal/espresso/heating_element"
 	"github.com/luiccn/espresso-controller/internal/espresso/power_manager"
+	"github.com/luiccn/espresso-controller/internal/espresso/safety"
+	"github.com/luiccn/espresso-controller/internal/espresso/shot_history"
+	"github.com/luiccn/espresso-controller/internal/espresso/steam"
 	"github.com/luiccn/espresso-controller/internal/espresso/temperature"
+	"github.com/luiccn/espresso-controller/internal/log"
+	"github.com/luiccn/espresso-controller/pkg/control"
+	"github.com/luiccn/espresso-controller/pkg/control/autotune"
 	"github.com/luiccn/espresso-controller/pkg/control/pid"
 	"github.com/luiccn/espresso-controller/pkg/espressopb"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
 )
 
 var (
@@ -22,11 +36,32 @@ var (
 )
 
 type grpcController struct {
-	c             Configuration
-	pid           *pid.PID
-	groupMonitor  *temperature.Monitor
-	boilerMonitor *temperature.Monitor
-	powerManager  *power_manager.PowerManager
+	c              Configuration
+	heatingElem    *heating_element.HeatingElement
+	groupMonitor   *temperature.Monitor
+	ambientMonitor *temperature.Monitor
+	boilerMonitor  *temperature.Monitor
+	powerManager   *power_manager.PowerManager
+	supervisor     *safety.Supervisor
+	events         *event_log.Log
+	steam          *steam.Selector
+	steamSubId     uuid.UUID
+	shotTimer      *brew.Timer
+	shotTimerSubId uuid.UUID
+	shots          *shot_history.Store
+
+	strategyMu   sync.Mutex
+	strategyName string
+	strategy     control.Strategy
+	autotuning   bool
+	// mode applied to the strategy, and while steaming the brew setpoint and
+	// parameters of the strategy
+	mode           steam.Mode
+	brewTarget     control.TargetTemperature
+	brewParameters control.Parameters
+	// feed-forward boost applied to strategies that support it when a shot
+	// starts
+	boost control.BoostProfile
 }
 
 func newGrpcController(
@@ -34,9 +69,23 @@ func newGrpcController(
 	heatingElem *heating_element.HeatingElement,
 	boilerMonitor *temperature.Monitor,
 	groupMonitor *temperature.Monitor,
+	ambientMonitor *temperature.Monitor,
 	powerManager *power_manager.PowerManager,
+	supervisor *safety.Supervisor,
+	events *event_log.Log,
+	steamSelector *steam.Selector,
+	shotTimer *brew.Timer,
+	shots *shot_history.Store,
 ) (*grpcController, error) {
-	temperatureCtrlr, err := pid.NewPid(heatingElem, powerManager, boilerMonitor)
+	strategyName := c.ControlStrategy
+	if strategyName == "" {
+		strategyName = pid.Name
+	}
+	boost, err := control.ParseBoostProfile(c.BoostProfile)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid boost profile")
+	}
+	temperatureCtrlr, err := newStrategy(strategyName, heatingElem, powerManager, boilerMonitor)
 	if err != nil {
 		return nil, err
 	}
@@ -45,21 +94,238 @@ func newGrpcController(
 		return nil, errors.Wrap(err, "Failed to start temperature controller")
 	}
 
-	return &grpcController{
-		c:             c,
-		pid:           temperatureCtrlr,
-		groupMonitor:  groupMonitor,
-		boilerMonitor: boilerMonitor,
-	}, nil
+	ctrl := &grpcController{
+		c:              c,
+		heatingElem:    heatingElem,
+		groupMonitor:   groupMonitor,
+		ambientMonitor: ambientMonitor,
+		boilerMonitor:  boilerMonitor,
+		powerManager:   powerManager,
+		supervisor:     supervisor,
+		events:         events,
+		steam:          steamSelector,
+		shotTimer:      shotTimer,
+		shots:          shots,
+		strategyName:   strategyName,
+		strategy:       temperatureCtrlr,
+		mode:           steam.ModeBrew,
+		boost:          boost,
+	}
+	subId, modes := steamSelector.Subscribe()
+	ctrl.steamSubId = subId
+	go ctrl.followMode(modes)
+	subId, timedShots := shotTimer.Subscribe()
+	ctrl.shotTimerSubId = subId
+	go ctrl.followShots(timedShots)
+	return ctrl, nil
+}
+
+// followShots boosts the strategy while shots are pulled
+func (c *grpcController) followShots(shots chan brew.Shot) {
+	for shot := range shots {
+		c.strategyMu.Lock()
+		if booster, ok := c.strategy.(control.Booster); ok {
+			// a stopped shot ends the boost
+			var profile control.BoostProfile
+			if shot.Running() {
+				profile = c.boostFor(shot)
+			}
+			if len(profile) > 0 {
+				log.Info("Boosting heater for shot", zap.Stringer("profile", profile), zap.String("source", string(shot.Source)))
+			}
+			// from the detection rather than the start, which is backdated
+			// for shots detected from the temperature, so that the first
+			// steps of the profile are not over before they are applied
+			booster.Boost(profile, shot.DetectedAt)
+		}
+		c.strategyMu.Unlock()
+	}
+}
+
+// boostFor returns the boost applied to the strategy for the shot, none unless
+// it was started by the brew switch or detected from the temperature, as the
+// button may start the timer without brewing. strategyMu must be held.
+func (c *grpcController) boostFor(shot brew.Shot) control.BoostProfile {
+	if shot.Source == brew.SourceButton || c.mode == steam.ModeSteam || c.autotuning {
+		return nil
+	}
+	if _, ok := c.strategy.(control.Booster); !ok {
+		return nil
+	}
+	return c.boost
+}
+
+// followMode applies the mode whenever the steam selector switches it
+func (c *grpcController) followMode(modes chan steam.Status) {
+	for range modes {
+		// the current status rather than the received one, which may be
+		// older than one applied by SetMode
+		status := c.steam.Status()
+		c.strategyMu.Lock()
+		c.applyMode(status)
+		c.strategyMu.Unlock()
+	}
+}
+
+// applyMode switches the strategy to the setpoint and gains of the mode,
+// resetting it so that it heats or cools towards them at once. strategyMu must
+// be held.
+func (c *grpcController) applyMode(status steam.Status) {
+	if status.Mode == c.mode {
+		return
+	}
+	if status.Mode == steam.ModeSteam {
+		c.enterSteam()
+	} else {
+		c.leaveSteam()
+	}
+	c.mode = status.Mode
+	if r, ok := c.strategy.(control.Resetter); ok {
+		r.Reset()
+	}
+	c.logEvent(event_log.Event{
+		Type:    event_log.TypeModeChanged,
+		Cause:   string(status.Reason),
+		Details: map[string]string{"mode": string(status.Mode)},
+	})
+}
+
+// enterSteam keeps the brew setpoint and parameters and applies the steam
+// ones. strategyMu must be held.
+func (c *grpcController) enterSteam() {
+	c.brewTarget = c.strategy.GetTargetTemperature()
+	c.brewParameters = c.strategy.GetParameters()
+	sc := c.steam.Config()
+	if c.strategyName == pid.Name {
+		if err := c.strategy.SetParameters(control.Parameters{
+			pid.ParamP: sc.P,
+			pid.ParamI: sc.I,
+			pid.ParamD: sc.D,
+		}); err != nil {
+			log.Error("Failed to apply steam gains", zap.Error(err))
+		}
+	}
+	c.strategy.SetTargetTemperature(sc.Temperature)
+}
+
+// leaveSteam applies the brew setpoint and parameters again. strategyMu must
+// be held.
+func (c *grpcController) leaveSteam() {
+	if err := c.strategy.SetParameters(c.brewParameters); err != nil {
+		log.Error("Failed to restore brew parameters", zap.Error(err))
+	}
+	c.strategy.SetTargetTemperature(c.brewTarget.Value)
+}
+
+// withBrewMode runs fn with the brew setpoint and parameters applied to the
+// strategy, so that fn changes those rather than the steam ones while
+// steaming. strategyMu must be held.
+func (c *grpcController) withBrewMode(fn func() error) error {
+	if c.mode != steam.ModeSteam {
+		return fn()
+	}
+	c.leaveSteam()
+	defer c.enterSteam()
+	return fn()
+}
+
+func (c *grpcController) SetMode(ctx context.Context, req *espressopb.SetModeRequest) (*espressopb.ModeStatus, error) {
+	c.powerManager.RecordActivity(power_manager.ReasonUser)
+	mode, err := steam.ParseMode(req.Mode)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.steam.SetMode(mode, steam.ReasonUser); err != nil {
+		return nil, err
+	}
+	status := c.steam.Status()
+	c.strategyMu.Lock()
+	c.applyMode(status)
+	c.strategyMu.Unlock()
+	return modeStatusProto(status)
+}
+
+func (c *grpcController) WatchMode(req *espressopb.WatchModeRequest, stream espressopb.Espresso_WatchModeServer) error {
+	grpcStreams.Inc()
+	defer grpcStreams.Dec()
+
+	subId, modes := c.steam.Subscribe()
+	defer c.steam.Unsubscribe(subId)
+
+	status, err := modeStatusProto(c.steam.Status())
+	if err != nil {
+		return err
+	}
+	if err := stream.Send(status); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case s, ok := <-modes:
+			if !ok {
+				return errors.New("steam selector stopped publishing")
+			}
+			status, err := modeStatusProto(s)
+			if err != nil {
+				return err
+			}
+			if err := stream.Send(status); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func modeStatusProto(status steam.Status) (*espressopb.ModeStatus, error) {
+	since, err := ptypes.TimestampProto(status.Since)
+	if err != nil {
+		return nil, err
+	}
+	pbStatus := &espressopb.ModeStatus{
+		Mode:   string(status.Mode),
+		Reason: string(status.Reason),
+		Since:  since,
+	}
+	if !status.RevertAt.IsZero() {
+		if pbStatus.RevertAt, err = ptypes.TimestampProto(status.RevertAt); err != nil {
+			return nil, err
+		}
+	}
+	return pbStatus, nil
 }
 
 func (c *grpcController) BoilerTemperature(req *espressopb.TemperatureStreamRequest, stream espressopb.Espresso_BoilerTemperatureServer) error {
+	return streamTemperature(c.boilerMonitor, stream)
+}
+
+func (c *grpcController) GroupTemperature(req *espressopb.TemperatureStreamRequest, stream espressopb.Espresso_GroupTemperatureServer) error {
+	if c.groupMonitor == nil {
+		return errors.New("no group head thermometer configured")
+	}
+	return streamTemperature(c.groupMonitor, stream)
+}
+
+func (c *grpcController) AmbientTemperature(req *espressopb.TemperatureStreamRequest, stream espressopb.Espresso_AmbientTemperatureServer) error {
+	if c.ambientMonitor == nil {
+		return errors.New("no ambient thermometer configured")
+	}
+	return streamTemperature(c.ambientMonitor, stream)
+}
+
+type temperatureStream interface {
+	Send(*espressopb.TemperatureStreamResponse) error
+}
+
+// streamTemperature sends the monitor's history followed by each new sample
+func streamTemperature(monitor *temperature.Monitor, stream temperatureStream) error {
 	grpcStreams.Inc()
 	defer grpcStreams.Dec()
 
 	// the first message sent on the stream is the temperature history
 	var pbSamples []*espressopb.TemperatureSample
-	samples := c.boilerMonitor.GetHistory()
+	samples := monitor.GetHistory()
 	for _, s := range samples {
 		pbTime, err := ptypes.TimestampProto(s.ObservedAt)
 		if err != nil {
@@ -83,8 +349,8 @@ func (c *grpcController) BoilerTemperature(req *espressopb.TemperatureStreamRequ
 	}
 
 	// send a current sample every second
-	subId, subCh := c.boilerMonitor.Subscribe()
-	defer c.boilerMonitor.Unsubscribe(subId)
+	subId, subCh := monitor.Subscribe()
+	defer monitor.Unsubscribe(subId)
 	for sample := range subCh {
 		pbTime, err := ptypes.TimestampProto(sample.ObservedAt)
 		if err != nil {
@@ -104,52 +370,437 @@ func (c *grpcController) BoilerTemperature(req *espressopb.TemperatureStreamRequ
 	return errors.New("temperature monitor stopped publishing")
 }
 
+// AtTemperature reports whether the last boiler temperature is within
+// AtTemperatureTolerance of the setpoint
+func (c *grpcController) AtTemperature() bool {
+	samples := c.boilerMonitor.GetHistory()
+	if len(samples) == 0 {
+		return false
+	}
+	c.strategyMu.Lock()
+	target := c.strategy.GetTargetTemperature().Value
+	c.strategyMu.Unlock()
+	diff := samples[len(samples)-1].Value - target
+	return diff >= -c.c.AtTemperatureTolerance && diff <= c.c.AtTemperatureTolerance
+}
+
 func (c *grpcController) GetConfiguration(ctx context.Context, req *espressopb.GetConfigurationRequest) (*espressopb.Configuration, error) {
-	targetTemperature := c.pid.GetTargetTemperature()
+	c.strategyMu.Lock()
+	defer c.strategyMu.Unlock()
+
+	return c.configuration()
+}
+
+func (c *grpcController) SetConfiguration(ctx context.Context, req *espressopb.Configuration) (*espressopb.Configuration, error) {
+	c.powerManager.RecordActivity(power_manager.ReasonUser)
+	if req.Temperature < 0 || req.Temperature > 140 {
+		return nil, errors.New("temperature must be in range [0, 140] °C")
+	}
+
+	c.strategyMu.Lock()
+	defer c.strategyMu.Unlock()
+
+	if c.autotuning {
+		return nil, errors.New("configuration cannot be changed while autotuning")
+	}
+
+	strategyName := req.Strategy
+	if strategyName == "" {
+		strategyName = c.strategyName
+	}
+
+	params := control.Parameters{}
+	for name, value := range req.Parameters {
+		params[name] = value
+	}
+	if strategyName == pid.Name {
+		// clients that predate the parameters set all three gains at the top
+		// level, others only those they set and didn't name in the parameters
+		for name, value := range map[string]float32{pid.ParamP: req.P, pid.ParamI: req.I, pid.ParamD: req.D} {
+			if _, ok := params[name]; !ok && (len(req.Parameters) == 0 || value != 0) {
+				params[name] = value
+			}
+		}
+	}
+
+	var steamConfig *steam.Config
+	if req.Steam != nil {
+		sc := c.steam.Config()
+		sc.Temperature = req.Steam.Temperature
+		sc.P = req.Steam.P
+		sc.I = req.Steam.I
+		sc.D = req.Steam.D
+		sc.Timeout = time.Duration(float64(req.Steam.Timeout) * float64(time.Second))
+		if err := c.steam.Validate(sc); err != nil {
+			return nil, err
+		}
+		steamConfig = &sc
+	}
+
+	var boost control.BoostProfile
+	if req.Boost != nil {
+		for _, step := range req.Boost.Steps {
+			boost = append(boost, control.BoostStep{
+				Duration: time.Duration(float64(step.Seconds) * float64(time.Second)),
+				Output:   step.Output,
+			})
+		}
+		if err := boost.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := c.withBrewMode(func() error {
+		if err := c.setStrategy(strategyName, params, req.Temperature); err != nil {
+			return err
+		}
+		if steamConfig != nil {
+			return c.steam.SetConfig(*steamConfig)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	if req.Boost != nil {
+		c.boost = boost
+	}
+	return c.configuration()
+}
+
+// setStrategy updates the parameters and target temperature of the named
+// strategy, switching to it if it is not the current one. strategyMu must be
+// held.
+func (c *grpcController) setStrategy(strategyName string, params control.Parameters, targetTemperature float32) error {
+	previousTarget := c.strategy.GetTargetTemperature().Value
+	previousParams := c.strategy.GetParameters()
+
+	strategy := c.strategy
+	if strategyName != c.strategyName {
+		var err error
+		if strategy, err = newStrategy(strategyName, c.heatingElem, c.powerManager, c.boilerMonitor); err != nil {
+			return err
+		}
+	}
+
+	if err := strategy.SetParameters(params); err != nil {
+		return err
+	}
+
+	strategy.SetTargetTemperature(targetTemperature)
+
+	switched := strategy != c.strategy
+	if switched {
+		if err := c.strategy.Shutdown(); err != nil {
+			return errors.Wrap(err, "stopping temperature controller")
+		}
+		if err := strategy.Run(); err != nil {
+			return errors.Wrap(err, "starting temperature controller")
+		}
+		log.Info("Switched temperature control strategy", zap.String("from", c.strategyName), zap.String("to", strategyName))
+		c.strategy = strategy
+		c.strategyName = strategyName
+	}
+
+	if targetTemperature != previousTarget {
+		c.logEvent(event_log.Event{
+			Type: event_log.TypeSetpointChanged,
+			Details: map[string]string{
+				"from": fmt.Sprintf("%.1f", previousTarget),
+				"to":   fmt.Sprintf("%.1f", targetTemperature),
+			},
+		})
+	}
+	if newParams := strategy.GetParameters(); switched || !reflect.DeepEqual(newParams, previousParams) {
+		details := map[string]string{"strategy": strategyName}
+		for name, value := range newParams {
+			details[name] = fmt.Sprintf("%g", value)
+		}
+		c.logEvent(event_log.Event{Type: event_log.TypeParametersChanged, Details: details})
+	}
+	return nil
+}
+
+// logEvent appends the event to the event log, if there is one
+func (c *grpcController) logEvent(e event_log.Event) {
+	if c.events == nil {
+		return
+	}
+	if _, err := c.events.Append(e); err != nil {
+		log.Error("Failed to log configuration event", zap.Error(err))
+	}
+}
+
+func (c *grpcController) Autotune(req *espressopb.AutotuneRequest, stream espressopb.Espresso_AutotuneServer) error {
+	c.powerManager.RecordActivity(power_manager.ReasonUser)
+	grpcStreams.Inc()
+	defer grpcStreams.Dec()
+
+	c.strategyMu.Lock()
+	if c.autotuning {
+		c.strategyMu.Unlock()
+		return errors.New("autotune is already running")
+	}
+	if c.mode == steam.ModeSteam {
+		c.strategyMu.Unlock()
+		return errors.New("autotune cannot run while steaming")
+	}
+	setpoint := req.Setpoint
+	if setpoint == 0 {
+		setpoint = c.strategy.GetTargetTemperature().Value
+	}
+	if err := c.strategy.Shutdown(); err != nil {
+		c.strategyMu.Unlock()
+		return errors.Wrap(err, "stopping temperature controller")
+	}
+	c.autotuning = true
+	c.strategyMu.Unlock()
+
+	tuner := autotune.NewAutotuner(c.heatingElem, c.powerManager, c.boilerMonitor)
+	result, tuneErr := tuner.Run(stream.Context(), autotune.Options{
+		Setpoint:   setpoint,
+		OutputHigh: req.OutputHigh,
+		OutputLow:  req.OutputLow,
+		Hysteresis: req.Hysteresis,
+		Cycles:     int(req.Cycles),
+		Rule:       autotune.Rule(req.Rule),
+	}, func(p autotune.Progress) {
+		pbTime, err := ptypes.TimestampProto(p.ObservedAt)
+		if err != nil {
+			return
+		}
+		// a client that went away cancels the stream's context, which stops
+		// the autotune
+		_ = stream.Send(&espressopb.AutotuneResponse{
+			Data: &espressopb.AutotuneResponse_Progress{
+				Progress: &espressopb.AutotuneProgress{
+					Phase:       string(p.Phase),
+					Cycle:       int32(p.Cycle),
+					Cycles:      int32(p.Cycles),
+					Temperature: p.Temperature,
+					DutyFactor:  p.DutyFactor,
+					ObservedAt:  pbTime,
+				},
+			},
+		})
+	})
+
+	c.strategyMu.Lock()
+	defer c.strategyMu.Unlock()
+	c.autotuning = false
+	if err := c.strategy.Run(); err != nil {
+		return errors.Wrap(err, "restarting temperature controller")
+	}
+	if tuneErr != nil {
+		return tuneErr
+	}
+
+	applied := false
+	if req.Apply {
+		// steam mode may have been entered while autotuning
+		if err := c.withBrewMode(func() error {
+			return c.setStrategy(pid.Name, result.Parameters(), c.strategy.GetTargetTemperature().Value)
+		}); err != nil {
+			return errors.Wrap(err, "applying autotune result")
+		}
+		applied = true
+	}
+
+	return stream.Send(&espressopb.AutotuneResponse{
+		Data: &espressopb.AutotuneResponse_Result{
+			Result: &espressopb.AutotuneResult{
+				Rule:           string(result.Rule),
+				UltimateGain:   result.UltimateGain,
+				UltimatePeriod: float32(result.UltimatePeriod.Seconds()),
+				Amplitude:      result.Amplitude,
+				P:              result.P,
+				I:              result.I,
+				D:              result.D,
+				Applied:        applied,
+			},
+		},
+	})
+}
+
+// configuration returns the current configuration, with the brew setpoint and
+// parameters while steaming. strategyMu must be held.
+func (c *grpcController) configuration() (*espressopb.Configuration, error) {
+	targetTemperature := c.strategy.GetTargetTemperature()
+	params := c.strategy.GetParameters()
+	if c.mode == steam.ModeSteam {
+		targetTemperature, params = c.brewTarget, c.brewParameters
+	}
 
 	pbTime, err := ptypes.TimestampProto(targetTemperature.SetAt)
 	if err != nil {
 		return nil, err
 	}
 
+	sc := c.steam.Config()
+	pbBoost := &espressopb.BoostProfile{}
+	for _, step := range c.boost {
+		pbBoost.Steps = append(pbBoost.Steps, &espressopb.BoostStep{
+			Seconds: float32(step.Duration.Seconds()),
+			Output:  step.Output,
+		})
+	}
 	return &espressopb.Configuration{
 		Temperature: targetTemperature.Value,
-		P:           c.pid.P,
-		I:           c.pid.I,
-		D:           c.pid.D,
+		P:           params[pid.ParamP],
+		I:           params[pid.ParamI],
+		D:           params[pid.ParamD],
 		SetAt:       pbTime,
+		Strategy:    c.strategyName,
+		Parameters:  params,
+		Mode:        string(c.mode),
+		Steam: &espressopb.SteamConfiguration{
+			Temperature: sc.Temperature,
+			P:           sc.P,
+			I:           sc.I,
+			D:           sc.D,
+			Timeout:     float32(sc.Timeout.Seconds()),
+		},
+		Boost: pbBoost,
 	}, nil
 }
 
-func (c *grpcController) SetConfiguration(ctx context.Context, req *espressopb.Configuration) (*espressopb.Configuration, error) {
-	if req.Temperature < 0 || req.Temperature > 140 {
-		return nil, errors.New("temperature must be in range [0, 140] °C")
+func (c *grpcController) WatchShotTimer(req *espressopb.WatchShotTimerRequest, stream espressopb.Espresso_WatchShotTimerServer) error {
+	grpcStreams.Inc()
+	defer grpcStreams.Dec()
+
+	subId, shots := c.shotTimer.Subscribe()
+	defer c.shotTimer.Unsubscribe(subId)
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	shot := c.shotTimer.Shot()
+	send := func() error {
+		pbShot, err := shotTimerProto(shot, time.Now())
+		if err != nil {
+			return err
+		}
+		return stream.Send(pbShot)
 	}
 
-	if req.P < 0 || req.D < 0 {
-		return nil, errors.New("pid terms must be > 0")
+	if err := send(); err != nil {
+		return err
 	}
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case s, ok := <-shots:
+			if !ok {
+				return errors.New("shot timer stopped publishing")
+			}
+			shot = s
+			if err := send(); err != nil {
+				return err
+			}
+		case <-ticker.C:
+			if !shot.Running() {
+				continue
+			}
+			if err := send(); err != nil {
+				return err
+			}
+		}
+	}
+}
 
-	targetTemperature := c.pid.SetTargetTemperature(req.Temperature)
+func shotTimerProto(shot brew.Shot, now time.Time) (*espressopb.ShotTimer, error) {
+	pbShot := &espressopb.ShotTimer{
+		Running: shot.Running(),
+		Seconds: float32(shot.Duration(now).Seconds()),
+		Source:  string(shot.Source),
+	}
+	var err error
+	if !shot.Start.IsZero() {
+		if pbShot.Start, err = ptypes.TimestampProto(shot.Start); err != nil {
+			return nil, err
+		}
+	}
+	if !shot.End.IsZero() {
+		if pbShot.End, err = ptypes.TimestampProto(shot.End); err != nil {
+			return nil, err
+		}
+	}
+	return pbShot, nil
+}
 
-	pbTime, err := ptypes.TimestampProto(targetTemperature.SetAt)
+func (c *grpcController) GetSafetyStatus(ctx context.Context, req *espressopb.GetSafetyStatusRequest) (*espressopb.SafetyStatus, error) {
+	return safetyStatusProto(c.supervisor.GetFault())
+}
+
+func (c *grpcController) ResetFault(ctx context.Context, req *espressopb.ResetFaultRequest) (*espressopb.SafetyStatus, error) {
+	c.powerManager.RecordActivity(power_manager.ReasonUser)
+	c.supervisor.Reset()
+	return safetyStatusProto(c.supervisor.GetFault())
+}
+
+func safetyStatusProto(fault *safety.Fault) (*espressopb.SafetyStatus, error) {
+	if fault == nil {
+		return &espressopb.SafetyStatus{}, nil
+	}
+
+	pbTime, err := ptypes.TimestampProto(fault.At)
 	if err != nil {
 		return nil, err
 	}
+	return &espressopb.SafetyStatus{
+		Faulted:     true,
+		Kind:        string(fault.Kind),
+		Message:     fault.Message,
+		Temperature: fault.Temperature,
+		FaultedAt:   pbTime,
+	}, nil
+}
 
-	c.pid.P = req.P
-	c.pid.I = req.I
-	c.pid.D = req.D
+func (c *grpcController) ListEvents(ctx context.Context, req *espressopb.ListEventsRequest) (*espressopb.ListEventsResponse, error) {
+	q := event_log.Query{
+		Before: req.PageToken,
+		Limit:  int(req.PageSize),
+	}
+	var err error
+	if req.From != nil {
+		if q.From, err = ptypes.Timestamp(req.From); err != nil {
+			return nil, errors.Wrap(err, "invalid from")
+		}
+	}
+	if req.To != nil {
+		if q.To, err = ptypes.Timestamp(req.To); err != nil {
+			return nil, errors.Wrap(err, "invalid to")
+		}
+	}
+	for _, t := range req.Types {
+		q.Types = append(q.Types, event_log.Type(t))
+	}
 
-	return &espressopb.Configuration{
-		Temperature: targetTemperature.Value,
-		P:           c.pid.P,
-		I:           c.pid.I,
-		D:           c.pid.D,
-		SetAt:       pbTime,
-	}, nil
+	page, err := c.events.Query(q)
+	if err != nil {
+		return nil, err
+	}
+	res := &espressopb.ListEventsResponse{NextPageToken: page.Next}
+	for _, e := range page.Events {
+		pbTime, err := ptypes.TimestampProto(e.At)
+		if err != nil {
+			return nil, err
+		}
+		res.Events = append(res.Events, &espressopb.Event{
+			Id:      e.Id,
+			At:      pbTime,
+			Type:    string(e.Type),
+			Cause:   e.Cause,
+			Message: e.Message,
+			Details: e.Details,
+		})
+	}
+	return res, nil
 }
 
 func (c *grpcController) Shutdown() error {
-	return c.pid.Shutdown()
+	c.steam.Unsubscribe(c.steamSubId)
+	c.shotTimer.Unsubscribe(c.shotTimerSubId)
+	c.strategyMu.Lock()
+	defer c.strategyMu.Unlock()
+	return c.strategy.Shutdown()
 }
@@ -0,0 +1,351 @@
+package espresso
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/luiccn/espresso-controller/internal/espresso/power_manager"
+	"github.com/luiccn/espresso-controller/internal/espresso/steam"
+	"github.com/luiccn/espresso-controller/pkg/espressopb"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultEffectiveScheduleDays = 7
+	maxEffectiveScheduleDays     = 366
+	maxCalendarSize              = 1 << 20
+)
+
+type grpcPowerController struct {
+	powerManager  *power_manager.PowerManager
+	steamSelector *steam.Selector
+}
+
+func newGrpcPowerController(powerManager *power_manager.PowerManager, steamSelector *steam.Selector) *grpcPowerController {
+	return &grpcPowerController{powerManager: powerManager, steamSelector: steamSelector}
+}
+
+// status returns the power status along with the current mode
+func (c *grpcPowerController) status() (*espressopb.PowerStatus, error) {
+	return powerStatusProto(c.powerManager.GetStatus(), c.steamSelector.Status().Mode)
+}
+
+func (c *grpcPowerController) GetPowerStatus(ctx context.Context, req *espressopb.GetPowerStatusRequest) (*espressopb.PowerStatus, error) {
+	return c.status()
+}
+
+func (c *grpcPowerController) SetPower(ctx context.Context, req *espressopb.SetPowerRequest) (*espressopb.PowerStatus, error) {
+	if req.On {
+		if err := c.powerManager.PowerOn(); err != nil {
+			return nil, err
+		}
+	} else {
+		c.powerManager.PowerOff()
+	}
+	return c.status()
+}
+
+func (c *grpcPowerController) TotalPowerOff(ctx context.Context, req *espressopb.TotalPowerOffRequest) (*espressopb.PowerStatus, error) {
+	c.powerManager.TotalPowerOff()
+	return c.status()
+}
+
+func (c *grpcPowerController) SetScheduleEnabled(ctx context.Context, req *espressopb.SetScheduleEnabledRequest) (*espressopb.PowerStatus, error) {
+	var err error
+	if req.Enabled {
+		err = c.powerManager.ScheduleOn()
+	} else {
+		err = c.powerManager.ScheduleOff()
+	}
+	if err == power_manager.ErrNoWindow {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return c.status()
+}
+
+// WatchPowerStatus sends the status on every transition, and once a second if
+// it changed otherwise, e.g. the LED pattern once the boiler is at temperature
+func (c *grpcPowerController) WatchPowerStatus(req *espressopb.WatchPowerStatusRequest, stream espressopb.PowerService_WatchPowerStatusServer) error {
+	grpcStreams.Inc()
+	defer grpcStreams.Dec()
+
+	subId, transitions := c.powerManager.SubscribeTransitions()
+	defer c.powerManager.UnsubscribeTransitions(subId)
+	modeSubId, modes := c.steamSelector.Subscribe()
+	defer c.steamSelector.Unsubscribe(modeSubId)
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	var last *espressopb.PowerStatus
+	send := func() error {
+		status, err := c.status()
+		if err != nil {
+			return err
+		}
+		if proto.Equal(status, last) {
+			return nil
+		}
+		last = status
+		return stream.Send(status)
+	}
+
+	if err := send(); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case _, ok := <-transitions:
+			if !ok {
+				return errors.New("power manager stopped publishing")
+			}
+			if err := send(); err != nil {
+				return err
+			}
+		case _, ok := <-modes:
+			if !ok {
+				return errors.New("steam selector stopped publishing")
+			}
+			if err := send(); err != nil {
+				return err
+			}
+		case <-ticker.C:
+			if err := send(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (c *grpcPowerController) AddPowerOnInterval(ctx context.Context, req *espressopb.PowerOnIntervalRequest) (*espressopb.PowerSchedule, error) {
+	c.powerManager.RecordActivity(power_manager.ReasonUser)
+	day, interval, err := powerOnIntervalFromProto(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.powerManager.AddInterval(day, interval); err != nil {
+		return nil, err
+	}
+	return powerScheduleProto(c.powerManager.GetStatus().PowerSchedule), nil
+}
+
+func (c *grpcPowerController) DeletePowerOnInterval(ctx context.Context, req *espressopb.PowerOnIntervalRequest) (*espressopb.PowerSchedule, error) {
+	c.powerManager.RecordActivity(power_manager.ReasonUser)
+	day, interval, err := powerOnIntervalFromProto(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.powerManager.RemoveInterval(day, interval); err != nil {
+		return nil, err
+	}
+	return powerScheduleProto(c.powerManager.GetStatus().PowerSchedule), nil
+}
+
+func (c *grpcPowerController) GetPowerSchedule(ctx context.Context, req *espressopb.GetPowerScheduleRequest) (*espressopb.PowerSchedule, error) {
+	return powerScheduleProto(c.powerManager.GetStatus().PowerSchedule), nil
+}
+
+func (c *grpcPowerController) SetPowerSchedule(ctx context.Context, req *espressopb.PowerSchedule) (*espressopb.PowerSchedule, error) {
+	c.powerManager.RecordActivity(power_manager.ReasonUser)
+	schedule, err := powerScheduleFromProto(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.powerManager.SetSchedule(schedule); err != nil {
+		return nil, err
+	}
+	return powerScheduleProto(c.powerManager.GetStatus().PowerSchedule), nil
+}
+
+func (c *grpcPowerController) GetEffectiveSchedule(ctx context.Context, req *espressopb.GetEffectiveScheduleRequest) (*espressopb.EffectiveSchedule, error) {
+	days := int(req.Days)
+	if days == 0 {
+		days = defaultEffectiveScheduleDays
+	}
+	if days < 0 || days > maxEffectiveScheduleDays {
+		return nil, errors.Errorf("days must be in range [1, %d]", maxEffectiveScheduleDays)
+	}
+
+	pbSchedule := &espressopb.EffectiveSchedule{}
+	for _, w := range c.powerManager.GetEffectiveSchedule(days) {
+		start, err := ptypes.TimestampProto(w.Start)
+		if err != nil {
+			return nil, err
+		}
+		end, err := ptypes.TimestampProto(w.End)
+		if err != nil {
+			return nil, err
+		}
+		pbSchedule.Windows = append(pbSchedule.Windows, &espressopb.ScheduledWindow{
+			Start:  start,
+			End:    end,
+			OneOff: w.OneOff,
+			Reason: w.Reason,
+		})
+	}
+	return pbSchedule, nil
+}
+
+func (c *grpcPowerController) ImportCalendar(ctx context.Context, req *espressopb.ImportCalendarRequest) (*espressopb.ImportCalendarResponse, error) {
+	c.powerManager.RecordActivity(power_manager.ReasonUser)
+	calendar, err := c.powerManager.ImportCalendar(req.Ics)
+	if err != nil {
+		return nil, err
+	}
+	return &espressopb.ImportCalendarResponse{Events: int32(calendar.Len())}, nil
+}
+
+func (c *grpcPowerController) RemoveCalendar(ctx context.Context, req *espressopb.RemoveCalendarRequest) (*espressopb.RemoveCalendarResponse, error) {
+	c.powerManager.RecordActivity(power_manager.ReasonUser)
+	if err := c.powerManager.RemoveCalendar(); err != nil {
+		return nil, err
+	}
+	return &espressopb.RemoveCalendarResponse{}, nil
+}
+
+func (c *grpcPowerController) WatchAutoOffWarnings(req *espressopb.WatchAutoOffWarningsRequest, stream espressopb.PowerService_WatchAutoOffWarningsServer) error {
+	grpcStreams.Inc()
+	defer grpcStreams.Dec()
+
+	subId, warnings := c.powerManager.SubscribeAutoOffWarnings()
+	defer c.powerManager.UnsubscribeAutoOffWarnings(subId)
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case w, ok := <-warnings:
+			if !ok {
+				return errors.New("power manager stopped publishing")
+			}
+			at, err := ptypes.TimestampProto(w.At)
+			if err != nil {
+				return err
+			}
+			offAt, err := ptypes.TimestampProto(w.OffAt)
+			if err != nil {
+				return err
+			}
+			if err := stream.Send(&espressopb.AutoOffWarning{At: at, OffAt: offAt}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func powerStatusProto(status power_manager.PowerManagerStatus, mode steam.Mode) (*espressopb.PowerStatus, error) {
+	since, err := ptypes.TimestampProto(status.Since)
+	if err != nil {
+		return nil, err
+	}
+	pbStatus := &espressopb.PowerStatus{
+		State:      string(status.State),
+		Reason:     string(status.Reason),
+		Since:      since,
+		PowerOn:    status.PowerOn,
+		LedPattern: string(status.LedPattern),
+		Mode:       string(mode),
+	}
+	if !status.OnSince.IsZero() {
+		if pbStatus.OnSince, err = ptypes.TimestampProto(status.OnSince); err != nil {
+			return nil, err
+		}
+	}
+	if !status.AutoOffAt.IsZero() {
+		if pbStatus.AutoOffAt, err = ptypes.TimestampProto(status.AutoOffAt); err != nil {
+			return nil, err
+		}
+	}
+	return pbStatus, nil
+}
+
+func powerOnIntervalFromProto(req *espressopb.PowerOnIntervalRequest) (time.Weekday, power_manager.PowerOnInterval, error) {
+	day, err := power_manager.ParseWeekday(req.Weekday)
+	if err != nil {
+		return 0, power_manager.PowerOnInterval{}, err
+	}
+	if req.Interval == nil {
+		return 0, power_manager.PowerOnInterval{}, errors.New("no power-on interval given")
+	}
+	interval, err := power_manager.ParsePowerOnInterval(req.Interval.From + "-" + req.Interval.To)
+	if err != nil {
+		return 0, power_manager.PowerOnInterval{}, err
+	}
+	return day, interval, nil
+}
+
+// powerScheduleProto lists the days of the schedule from Sunday to Saturday
+func powerScheduleProto(schedule power_manager.PowerSchedule) *espressopb.PowerSchedule {
+	pbSchedule := &espressopb.PowerSchedule{
+		Timezone:    schedule.Timezone,
+		PausedUntil: schedule.PausedUntil.String(),
+	}
+	for _, skip := range schedule.Skips {
+		pbSchedule.Skips = append(pbSchedule.Skips, &espressopb.DateRange{
+			From:   skip.From.String(),
+			To:     skip.To.String(),
+			Reason: skip.Reason,
+		})
+	}
+	for _, extra := range schedule.Extras {
+		pbSchedule.Extras = append(pbSchedule.Extras, &espressopb.OneOffInterval{
+			Date: extra.Date.String(),
+			Interval: &espressopb.PowerOnInterval{
+				From: extra.Interval.From.String(),
+				To:   extra.Interval.To.String(),
+			},
+			Reason: extra.Reason,
+		})
+	}
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		intervals, ok := schedule.Frames[d]
+		if !ok {
+			continue
+		}
+		pbDay := &espressopb.DaySchedule{Weekday: d.String()}
+		for _, interval := range intervals {
+			pbDay.Intervals = append(pbDay.Intervals, &espressopb.PowerOnInterval{
+				From: interval.From.String(),
+				To:   interval.To.String(),
+			})
+		}
+		pbSchedule.Days = append(pbSchedule.Days, pbDay)
+	}
+	return pbSchedule
+}
+
+func powerScheduleFromProto(pbSchedule *espressopb.PowerSchedule) (power_manager.PowerSchedule, error) {
+	c := power_manager.PowerScheduleConfig{
+		Timezone:    pbSchedule.Timezone,
+		Frames:      make(map[string][]string),
+		PausedUntil: pbSchedule.PausedUntil,
+	}
+	for _, pbDay := range pbSchedule.Days {
+		for _, pbInterval := range pbDay.Intervals {
+			c.Frames[pbDay.Weekday] = append(c.Frames[pbDay.Weekday], pbInterval.From+"-"+pbInterval.To)
+		}
+	}
+	for _, pbSkip := range pbSchedule.Skips {
+		c.Skips = append(c.Skips, power_manager.DateRangeConfig{
+			From:   pbSkip.From,
+			To:     pbSkip.To,
+			Reason: pbSkip.Reason,
+		})
+	}
+	for _, pbExtra := range pbSchedule.Extras {
+		if pbExtra.Interval == nil {
+			return power_manager.PowerSchedule{}, errors.Errorf("one-off interval on %s has no interval", pbExtra.Date)
+		}
+		c.Extras = append(c.Extras, power_manager.OneOffIntervalConfig{
+			Date:     pbExtra.Date,
+			Interval: pbExtra.Interval.From + "-" + pbExtra.Interval.To,
+			Reason:   pbExtra.Reason,
+		})
+	}
+	return power_manager.NewPowerSchedule(c)
+}
@@ -0,0 +1,190 @@
+package espresso
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/luiccn/espresso-controller/internal/espresso/brew"
+	"github.com/luiccn/espresso-controller/internal/espresso/power_manager"
+	"github.com/luiccn/espresso-controller/internal/espresso/shot_history"
+	"github.com/luiccn/espresso-controller/pkg/espressopb"
+	"github.com/pkg/errors"
+)
+
+// ShotSettings returns the setpoint, strategy parameters and boost in effect
+// for the shot, for the shot history
+func (c *grpcController) ShotSettings(shot brew.Shot) shot_history.Settings {
+	c.strategyMu.Lock()
+	defer c.strategyMu.Unlock()
+	var boost []shot_history.BoostStep
+	for _, step := range c.boostFor(shot) {
+		boost = append(boost, shot_history.BoostStep{
+			Seconds: float32(step.Duration.Seconds()),
+			Output:  step.Output,
+		})
+	}
+	return shot_history.Settings{
+		Setpoint:   c.strategy.GetTargetTemperature().Value,
+		Strategy:   c.strategyName,
+		Parameters: c.strategy.GetParameters(),
+		Boost:      boost,
+	}
+}
+
+func (c *grpcController) ListShots(ctx context.Context, req *espressopb.ListShotsRequest) (*espressopb.ListShotsResponse, error) {
+	from, to, err := timeRangeFromProto(req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+	page, err := c.shots.List(shot_history.Query{
+		From:   from,
+		To:     to,
+		Before: req.PageToken,
+		Limit:  int(req.PageSize),
+	})
+	if err != nil {
+		return nil, err
+	}
+	res := &espressopb.ListShotsResponse{NextPageToken: page.Next}
+	for _, s := range page.Shots {
+		pbShot, err := shotProto(s)
+		if err != nil {
+			return nil, err
+		}
+		res.Shots = append(res.Shots, pbShot)
+	}
+	return res, nil
+}
+
+func (c *grpcController) GetShot(ctx context.Context, req *espressopb.GetShotRequest) (*espressopb.Shot, error) {
+	s, err := c.shots.Get(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	return shotProto(s)
+}
+
+func (c *grpcController) UpdateShotNotes(ctx context.Context, req *espressopb.UpdateShotNotesRequest) (*espressopb.Shot, error) {
+	c.powerManager.RecordActivity(power_manager.ReasonUser)
+	var notes shot_history.Notes
+	if n := req.Notes; n != nil {
+		notes = shot_history.Notes{
+			Dose:   n.Dose,
+			Yield:  n.Yield,
+			Bean:   n.Bean,
+			Grind:  n.Grind,
+			Rating: int(n.Rating),
+		}
+	}
+	s, err := c.shots.UpdateNotes(req.Id, notes)
+	if err != nil {
+		return nil, err
+	}
+	return shotProto(s)
+}
+
+func (c *grpcController) DeleteShot(ctx context.Context, req *espressopb.DeleteShotRequest) (*espressopb.DeleteShotResponse, error) {
+	c.powerManager.RecordActivity(power_manager.ReasonUser)
+	if err := c.shots.Delete(req.Id); err != nil {
+		return nil, err
+	}
+	return &espressopb.DeleteShotResponse{}, nil
+}
+
+func (c *grpcController) ExportShots(ctx context.Context, req *espressopb.ExportShotsRequest) (*espressopb.ExportShotsResponse, error) {
+	format, err := shot_history.ParseFormat(req.Format)
+	if err != nil {
+		return nil, err
+	}
+	from, to, err := timeRangeFromProto(req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+	shots, err := c.shots.Range(from, to)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := shot_history.Export(&buf, format, shots); err != nil {
+		return nil, errors.Wrap(err, "exporting shots")
+	}
+	return &espressopb.ExportShotsResponse{
+		Data:        buf.Bytes(),
+		ContentType: format.ContentType(),
+	}, nil
+}
+
+// timeRangeFromProto converts the bounds of a time range, zero if unset
+func timeRangeFromProto(pbFrom *timestamp.Timestamp, pbTo *timestamp.Timestamp) (time.Time, time.Time, error) {
+	var from, to time.Time
+	var err error
+	if pbFrom != nil {
+		if from, err = ptypes.Timestamp(pbFrom); err != nil {
+			return from, to, errors.Wrap(err, "invalid from")
+		}
+	}
+	if pbTo != nil {
+		if to, err = ptypes.Timestamp(pbTo); err != nil {
+			return from, to, errors.Wrap(err, "invalid to")
+		}
+	}
+	return from, to, nil
+}
+
+func shotProto(s shot_history.Shot) (*espressopb.Shot, error) {
+	start, err := ptypes.TimestampProto(s.Start)
+	if err != nil {
+		return nil, err
+	}
+	end, err := ptypes.TimestampProto(s.End)
+	if err != nil {
+		return nil, err
+	}
+	boilerTrace, err := traceProto(s.BoilerTrace)
+	if err != nil {
+		return nil, err
+	}
+	groupTrace, err := traceProto(s.GroupTrace)
+	if err != nil {
+		return nil, err
+	}
+	var boost []*espressopb.BoostStep
+	for _, step := range s.Boost {
+		boost = append(boost, &espressopb.BoostStep{Seconds: step.Seconds, Output: step.Output})
+	}
+	return &espressopb.Shot{
+		Id:          s.Id,
+		Start:       start,
+		End:         end,
+		Seconds:     float32(s.Duration().Seconds()),
+		Source:      s.Source,
+		Setpoint:    s.Setpoint,
+		Strategy:    s.Strategy,
+		Parameters:  s.Parameters,
+		Boost:       boost,
+		BoilerTrace: boilerTrace,
+		GroupTrace:  groupTrace,
+		Notes: &espressopb.ShotNotes{
+			Dose:   s.Notes.Dose,
+			Yield:  s.Notes.Yield,
+			Bean:   s.Notes.Bean,
+			Grind:  s.Notes.Grind,
+			Rating: int32(s.Notes.Rating),
+		},
+	}, nil
+}
+
+func traceProto(points []shot_history.Point) ([]*espressopb.TemperatureSample, error) {
+	var samples []*espressopb.TemperatureSample
+	for _, p := range points {
+		pbTime, err := ptypes.TimestampProto(p.At)
+		if err != nil {
+			return nil, err
+		}
+		samples = append(samples, &espressopb.TemperatureSample{Value: p.Value, ObservedAt: pbTime})
+	}
+	return samples, nil
+}
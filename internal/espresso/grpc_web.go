@@ -4,15 +4,23 @@ import (
 	"context"
 	"embed"
 	"encoding/json"
+	"fmt"
 	"io/fs"
+	"io/ioutil"
 	"net"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/hako/durafmt"
 	"github.com/improbable-eng/grpc-web/go/grpcweb"
+	"github.com/luiccn/espresso-controller/internal/espresso/event_log"
+	"github.com/luiccn/espresso-controller/internal/espresso/led"
 	"github.com/luiccn/espresso-controller/internal/espresso/power_manager"
+	"github.com/luiccn/espresso-controller/internal/espresso/safety"
+	"github.com/luiccn/espresso-controller/internal/espresso/shot_history"
+	"github.com/luiccn/espresso-controller/internal/espresso/simulation"
 	"github.com/luiccn/espresso-controller/internal/log"
 	"github.com/luiccn/espresso-controller/internal/metrics"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -63,7 +71,7 @@ func NewGRPCWebServer(server *grpc.Server, uiFS embed.FS) *GRPCWebServer {
 	}
 }
 
-func (s *GRPCWebServer) Listen(listener net.Listener, enableDevLogger bool, powerManager *power_manager.PowerManager) error {
+func (s *GRPCWebServer) Listen(listener net.Listener, enableDevLogger bool, powerManager *power_manager.PowerManager, supervisor *safety.Supervisor, eventLog *event_log.Log, shots *shot_history.Store, boiler *simulation.Boiler) error {
 	loggerMiddleware := NewProdLoggerMiddleware
 	if enableDevLogger {
 		loggerMiddleware = middleware.Logger
@@ -85,7 +93,10 @@ func (s *GRPCWebServer) Listen(listener net.Listener, enableDevLogger bool, powe
 	)
 
 	router.Post("/scheduling/on", func(writer http.ResponseWriter, req *http.Request) {
-		powerManager.PowerOn()
+		if err := powerManager.PowerOn(); err != nil {
+			http.Error(writer, err.Error(), http.StatusConflict)
+			return
+		}
 		writer.Header().Add("Content-Type", "application/json")
 		writer.WriteHeader(200)
 	})
@@ -96,7 +107,10 @@ func (s *GRPCWebServer) Listen(listener net.Listener, enableDevLogger bool, powe
 	})
 
 	router.Post("/power/on", func(writer http.ResponseWriter, req *http.Request) {
-		powerManager.PowerOn()
+		if err := powerManager.PowerOn(); err != nil {
+			http.Error(writer, err.Error(), http.StatusConflict)
+			return
+		}
 		writer.Header().Add("Content-Type", "application/json")
 		writer.WriteHeader(200)
 	})
@@ -106,7 +120,10 @@ func (s *GRPCWebServer) Listen(listener net.Listener, enableDevLogger bool, powe
 		writer.WriteHeader(200)
 	})
 	router.Post("/power/toggle", func(writer http.ResponseWriter, req *http.Request) {
-		powerManager.PowerToggle()
+		if err := powerManager.PowerToggle(); err != nil {
+			http.Error(writer, err.Error(), http.StatusConflict)
+			return
+		}
 		writer.Header().Add("Content-Type", "application/json")
 		writer.WriteHeader(200)
 	})
@@ -120,6 +137,10 @@ func (s *GRPCWebServer) Listen(listener net.Listener, enableDevLogger bool, powe
 		type PowerManagerStatus struct {
 			PowerSchedule        power_manager.PowerSchedule
 			AutoOffDuration      string
+			AutoOffAt            time.Time
+			State                power_manager.State
+			Reason               power_manager.Reason
+			LedPattern           led.Pattern
 			OnSince              string
 			CurrentlyInASchedule bool
 			LastInteraction      string
@@ -142,6 +163,10 @@ func (s *GRPCWebServer) Listen(listener net.Listener, enableDevLogger bool, powe
 		humanPowerStatus := PowerManagerStatus{
 			PowerSchedule:        ps.PowerSchedule,
 			AutoOffDuration:      durafmt.Parse(ps.AutoOffDuration).String(),
+			AutoOffAt:            ps.AutoOffAt,
+			State:                ps.State,
+			Reason:               ps.Reason,
+			LedPattern:           ps.LedPattern,
 			OnSince:              durafmt.Parse(onSince).LimitFirstN(2).String(),
 			CurrentlyInASchedule: ps.CurrentlyInASchedule,
 			LastInteraction:      ps.LastInteraction,
@@ -154,6 +179,161 @@ func (s *GRPCWebServer) Listen(listener net.Listener, enableDevLogger bool, powe
 		writer.Write(j)
 	})
 
+	router.Get("/power/history", func(writer http.ResponseWriter, req *http.Request) {
+		writer.Header().Add("Content-Type", "application/json")
+		writer.WriteHeader(200)
+		j, _ := json.Marshal(powerManager.History())
+		writer.Write(j)
+	})
+
+	router.Get("/power/schedule", func(writer http.ResponseWriter, req *http.Request) {
+		writer.Header().Add("Content-Type", "application/json")
+		writer.WriteHeader(200)
+		j, _ := json.Marshal(powerManager.GetStatus().PowerSchedule)
+		writer.Write(j)
+	})
+	router.Put("/power/schedule", func(writer http.ResponseWriter, req *http.Request) {
+		var schedule power_manager.PowerSchedule
+		if err := json.NewDecoder(req.Body).Decode(&schedule); err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := powerManager.SetSchedule(schedule); err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writer.Header().Add("Content-Type", "application/json")
+		writer.WriteHeader(200)
+		j, _ := json.Marshal(powerManager.GetStatus().PowerSchedule)
+		writer.Write(j)
+	})
+
+	router.Get("/power/schedule/effective", func(writer http.ResponseWriter, req *http.Request) {
+		days := defaultEffectiveScheduleDays
+		if v := req.URL.Query().Get("days"); v != "" {
+			var err error
+			if days, err = strconv.Atoi(v); err != nil || days < 1 || days > maxEffectiveScheduleDays {
+				http.Error(writer, fmt.Sprintf("days must be in range [1, %d]", maxEffectiveScheduleDays), http.StatusBadRequest)
+				return
+			}
+		}
+		writer.Header().Add("Content-Type", "application/json")
+		writer.WriteHeader(200)
+		j, _ := json.Marshal(powerManager.GetEffectiveSchedule(days))
+		writer.Write(j)
+	})
+	router.Put("/power/schedule/calendar", func(writer http.ResponseWriter, req *http.Request) {
+		data, err := ioutil.ReadAll(http.MaxBytesReader(writer, req.Body, maxCalendarSize))
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		calendar, err := powerManager.ImportCalendar(data)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writer.Header().Add("Content-Type", "application/json")
+		writer.WriteHeader(200)
+		j, _ := json.Marshal(struct{ Events int }{Events: calendar.Len()})
+		writer.Write(j)
+	})
+	router.Delete("/power/schedule/calendar", func(writer http.ResponseWriter, req *http.Request) {
+		if err := powerManager.RemoveCalendar(); err != nil {
+			http.Error(writer, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writer.WriteHeader(200)
+	})
+
+	router.Get("/safety/status", func(writer http.ResponseWriter, req *http.Request) {
+		type SafetyStatus struct {
+			Faulted bool
+			Fault   *safety.Fault
+		}
+
+		writer.Header().Add("Content-Type", "application/json")
+		writer.WriteHeader(200)
+
+		fault := supervisor.GetFault()
+		j, _ := json.Marshal(SafetyStatus{Faulted: fault != nil, Fault: fault})
+		writer.Write(j)
+	})
+	router.Post("/safety/reset", func(writer http.ResponseWriter, req *http.Request) {
+		supervisor.Reset()
+		writer.Header().Add("Content-Type", "application/json")
+		writer.WriteHeader(200)
+	})
+
+	router.Get("/events", func(writer http.ResponseWriter, req *http.Request) {
+		q, err := eventQuery(req)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		page, err := eventLog.Query(q)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writer.Header().Add("Content-Type", "application/json")
+		writer.WriteHeader(200)
+		j, _ := json.Marshal(page)
+		writer.Write(j)
+	})
+
+	router.Get("/shots/export", func(writer http.ResponseWriter, req *http.Request) {
+		values := req.URL.Query()
+		format, err := shot_history.ParseFormat(values.Get("format"))
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var from, to time.Time
+		if v := values.Get("from"); v != "" {
+			if from, err = time.Parse(time.RFC3339, v); err != nil {
+				http.Error(writer, fmt.Sprintf("invalid from %q, expected an RFC 3339 time", v), http.StatusBadRequest)
+				return
+			}
+		}
+		if v := values.Get("to"); v != "" {
+			if to, err = time.Parse(time.RFC3339, v); err != nil {
+				http.Error(writer, fmt.Sprintf("invalid to %q, expected an RFC 3339 time", v), http.StatusBadRequest)
+				return
+			}
+		}
+		exported, err := shots.Range(from, to)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writer.Header().Add("Content-Type", format.ContentType())
+		writer.Header().Add("Content-Disposition", fmt.Sprintf("attachment; filename=shots.%s", format))
+		writer.WriteHeader(200)
+		if err := shot_history.Export(writer, format, exported); err != nil {
+			log.Error("Failed to export shots", zap.Error(err))
+		}
+	})
+
+	if boiler != nil {
+		router.Get("/simulation/status", func(writer http.ResponseWriter, req *http.Request) {
+			writer.Header().Add("Content-Type", "application/json")
+			writer.WriteHeader(200)
+			j, _ := json.Marshal(boiler.GetStatus())
+			writer.Write(j)
+		})
+		router.Post("/simulation/brew/start", func(writer http.ResponseWriter, req *http.Request) {
+			boiler.SetBrewing(true)
+			writer.Header().Add("Content-Type", "application/json")
+			writer.WriteHeader(200)
+		})
+		router.Post("/simulation/brew/stop", func(writer http.ResponseWriter, req *http.Request) {
+			boiler.SetBrewing(false)
+			writer.Header().Add("Content-Type", "application/json")
+			writer.WriteHeader(200)
+		})
+	}
+
 	router.Handle("/metrics", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		metrics.CollectSystemMetrics()
 		promhttp.Handler().ServeHTTP(w, req)
@@ -227,3 +407,35 @@ func NewProdLoggerMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(ww, r)
 	})
 }
+
+// eventQuery reads an event log query from the url parameters from, to
+// (RFC 3339), type (repeated), page_token and limit
+func eventQuery(req *http.Request) (event_log.Query, error) {
+	values := req.URL.Query()
+	q := event_log.Query{}
+	var err error
+	if v := values.Get("from"); v != "" {
+		if q.From, err = time.Parse(time.RFC3339, v); err != nil {
+			return q, fmt.Errorf("invalid from %q, expected an RFC 3339 time", v)
+		}
+	}
+	if v := values.Get("to"); v != "" {
+		if q.To, err = time.Parse(time.RFC3339, v); err != nil {
+			return q, fmt.Errorf("invalid to %q, expected an RFC 3339 time", v)
+		}
+	}
+	for _, t := range values["type"] {
+		q.Types = append(q.Types, event_log.Type(t))
+	}
+	if v := values.Get("page_token"); v != "" {
+		if q.Before, err = strconv.ParseUint(v, 10, 64); err != nil {
+			return q, fmt.Errorf("invalid page_token %q", v)
+		}
+	}
+	if v := values.Get("limit"); v != "" {
+		if q.Limit, err = strconv.Atoi(v); err != nil {
+			return q, fmt.Errorf("invalid limit %q", v)
+		}
+	}
+	return q, nil
+}
@@ -1,18 +1,22 @@
 package heating_element
 
 import (
+	"sync"
 	"time"
 
-	"github.com/stianeikeland/go-rpio/v4"
+	"github.com/luiccn/espresso-controller/internal/espresso/gpio"
 )
 
 type HeatingElement struct {
-	heatingElementRelayPin rpio.Pin
-	dutyFactor             float32
+	heatingElementRelayPin gpio.Pin
+
+	mu         sync.Mutex
+	dutyFactor float32
+	disabled   bool
 }
 
-func NewHeatingElement(heatingElementRelayPinNum int) *HeatingElement {
-	heatingElementRelayPin := rpio.Pin(heatingElementRelayPinNum)
+func NewHeatingElement(driver gpio.Driver, heatingElementRelayPinNum int) *HeatingElement {
+	heatingElementRelayPin := driver.Pin(heatingElementRelayPinNum)
 	heatingElementRelayPin.Output()
 
 	return &HeatingElement{
@@ -24,14 +28,15 @@ func NewHeatingElement(heatingElementRelayPinNum int) *HeatingElement {
 func (h *HeatingElement) Run() {
 	go func() {
 		for {
-			if h.dutyFactor == 0 {
+			dutyFactor := h.DutyFactor()
+			if dutyFactor == 0 || h.IsDisabled() {
 				h.off()
 				time.Sleep(1 * time.Second)
 				continue
 			}
 
-			onMs := h.dutyFactor * 1000
-			offMs := (1 - h.dutyFactor) * 1000
+			onMs := dutyFactor * 1000
+			offMs := (1 - dutyFactor) * 1000
 
 			h.on()
 			time.Sleep(time.Duration(onMs) * time.Millisecond)
@@ -42,11 +47,56 @@ func (h *HeatingElement) Run() {
 }
 
 func (h *HeatingElement) SetDutyFactor(factor float32) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
 	h.dutyFactor = factor
 }
 
+// DutyFactor returns the currently commanded duty factor in [0, 1].
+func (h *HeatingElement) DutyFactor() float32 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.dutyFactor
+}
+
+// AppliedDutyFactor returns the duty factor the relay is actually driven with,
+// i.e. 0 while disabled.
+func (h *HeatingElement) AppliedDutyFactor() float32 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.disabled {
+		return 0
+	}
+	return h.dutyFactor
+}
+
+// Disable switches the relay off and keeps it off, regardless of the duty
+// factor, until Enable is called.
+func (h *HeatingElement) Disable() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.disabled = true
+	h.heatingElementRelayPin.Low()
+}
+
+func (h *HeatingElement) Enable() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.disabled = false
+}
+
+func (h *HeatingElement) IsDisabled() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.disabled
+}
+
 func (h *HeatingElement) on() {
-	h.heatingElementRelayPin.High()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.disabled {
+		h.heatingElementRelayPin.High()
+	}
 }
 
 func (h *HeatingElement) off() {
@@ -0,0 +1,145 @@
+// Package led shows patterns, e.g. a slow blink, on a status LED
+package led
+
+import (
+	"sync"
+	"time"
+
+	"github.com/luiccn/espresso-controller/internal/espresso/gpio"
+)
+
+const updateInterval = 25 * time.Millisecond
+
+type Pattern string
+
+const (
+	PatternOff         Pattern = "off"
+	PatternSolid       Pattern = "solid"
+	PatternSlowBlink   Pattern = "slow_blink"
+	PatternFastBlink   Pattern = "fast_blink"
+	PatternDoubleBlink Pattern = "double_blink"
+)
+
+// blinks are the alternating on and off durations of one period of the
+// blinking patterns, starting with on
+var blinks = map[Pattern][]time.Duration{
+	PatternSlowBlink:   {time.Second, time.Second},
+	PatternFastBlink:   {100 * time.Millisecond, 100 * time.Millisecond},
+	PatternDoubleBlink: {150 * time.Millisecond, 150 * time.Millisecond, 150 * time.Millisecond, 1050 * time.Millisecond},
+}
+
+// On reports whether the LED is lit the given time after the pattern started
+func (p Pattern) On(elapsed time.Duration) bool {
+	if p == PatternSolid {
+		return true
+	}
+	durations, ok := blinks[p]
+	if !ok {
+		return false
+	}
+	var period time.Duration
+	for _, d := range durations {
+		period += d
+	}
+	t := elapsed % period
+	for i, d := range durations {
+		if t < d {
+			return i%2 == 0
+		}
+		t -= d
+	}
+	return false
+}
+
+// LED drives the pin of an LED, high while lit, following its pattern
+type LED struct {
+	pin gpio.Pin
+
+	mu      sync.Mutex
+	pattern Pattern
+	since   time.Time
+	on      bool
+	// the clock, replaced in tests
+	now func() time.Time
+
+	shutdownCh chan struct{}
+}
+
+func NewLED(driver gpio.Driver, pinNum int) *LED {
+	pin := driver.Pin(pinNum)
+	pin.Output()
+	pin.Low()
+	return &LED{
+		pin:        pin,
+		pattern:    PatternOff,
+		since:      time.Now(),
+		now:        time.Now,
+		shutdownCh: make(chan struct{}),
+	}
+}
+
+// SetPattern starts showing the pattern, unless it is already shown or the
+// LED was shut down
+func (l *LED) SetPattern(p Pattern) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	select {
+	case <-l.shutdownCh:
+		return
+	default:
+	}
+	if p == l.pattern {
+		return
+	}
+	l.pattern = p
+	l.since = l.now()
+	l.update()
+}
+
+func (l *LED) Pattern() Pattern {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.pattern
+}
+
+func (l *LED) Run() {
+	go func() {
+		ticker := time.NewTicker(updateInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-l.shutdownCh:
+				return
+			case <-ticker.C:
+				l.mu.Lock()
+				l.update()
+				l.mu.Unlock()
+			}
+		}
+	}()
+}
+
+// update switches the pin to the current level of the pattern. mu must be
+// held.
+func (l *LED) update() {
+	on := l.pattern.On(l.now().Sub(l.since))
+	if on == l.on {
+		return
+	}
+	l.on = on
+	if on {
+		l.pin.High()
+	} else {
+		l.pin.Low()
+	}
+}
+
+// Shutdown stops the pattern and turns the LED off
+func (l *LED) Shutdown() {
+	close(l.shutdownCh)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pattern = PatternOff
+	l.on = false
+	l.pin.Low()
+}
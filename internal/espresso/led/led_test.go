@@ -0,0 +1,84 @@
+package led
+
+import (
+	"testing"
+	"time"
+
+	"github.com/luiccn/espresso-controller/internal/espresso/gpio"
+)
+
+func TestPattern_On(t *testing.T) {
+	ms := func(n int) time.Duration { return time.Duration(n) * time.Millisecond }
+	tests := []struct {
+		pattern Pattern
+		// whether the LED is lit at 0, 100, 200... ms
+		want string
+	}{
+		{pattern: PatternOff, want: "________________________"},
+		{pattern: PatternSolid, want: "########################"},
+		{pattern: PatternSlowBlink, want: "##########__________####"},
+		{pattern: PatternFastBlink, want: "#_#_#_#_#_#_#_#_#_#_#_#_"},
+		// on 150, off 150, on 150, off 1050
+		{pattern: PatternDoubleBlink, want: "##_##__________##_##____"},
+		{pattern: "unknown", want: "________________________"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.pattern), func(t *testing.T) {
+			got := ""
+			for i := range tt.want {
+				if tt.pattern.On(ms(i * 100)) {
+					got += "#"
+				} else {
+					got += "_"
+				}
+			}
+			if got != tt.want {
+				t.Errorf("On() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLED(t *testing.T) {
+	driver := gpio.NewFakeDriver()
+	pin := driver.FakePin(27)
+	l := NewLED(driver, 27)
+	if !pin.IsOutput() || pin.Read() != gpio.Low {
+		t.Fatalf("LED should start as a low output")
+	}
+
+	now := time.Date(2026, 10, 19, 7, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return now }
+	at := func(elapsed time.Duration, want gpio.State) {
+		t.Helper()
+		now = now.Add(elapsed)
+		l.mu.Lock()
+		l.update()
+		l.mu.Unlock()
+		if got := pin.Read(); got != want {
+			t.Errorf("pin = %v, want %v", got, want)
+		}
+	}
+
+	l.SetPattern(PatternSlowBlink)
+	at(0, gpio.High)
+	at(999*time.Millisecond, gpio.High)
+	at(time.Millisecond, gpio.Low)
+
+	// setting the same pattern again does not restart it
+	l.SetPattern(PatternSlowBlink)
+	at(0, gpio.Low)
+
+	l.SetPattern(PatternFastBlink)
+	at(0, gpio.High)
+	at(100*time.Millisecond, gpio.Low)
+
+	l.SetPattern(PatternSolid)
+	at(time.Hour, gpio.High)
+
+	l.Shutdown()
+	if pin.Read() != gpio.Low || l.Pattern() != PatternOff {
+		t.Errorf("Shutdown() should turn the LED off")
+	}
+}
@@ -0,0 +1,477 @@
+package power_manager
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/teambition/rrule-go"
+)
+
+const (
+	icsDateLayout          = "20060102"
+	icsDateTimeLayout      = "20060102T150405"
+	icsUTCDateTimeLayout   = "20060102T150405Z"
+	defaultAllDayDuration  = 24 * time.Hour
+	maxCalendarEventLength = 7 * 24 * time.Hour
+)
+
+// Calendar turns the events of an iCalendar (RFC 5545) file into power-on
+// windows. Recurring events, their exceptions (EXDATE, RDATE and modified
+// instances) and IANA timezones are supported; VTIMEZONE definitions are not,
+// so a TZID must name an IANA timezone.
+type Calendar struct {
+	events []*calendarEvent
+}
+
+type calendarEvent struct {
+	uid      string
+	summary  string
+	start    time.Time
+	duration time.Duration
+	// nil if the event does not recur
+	recurrence *rrule.Set
+	cancelled  bool
+}
+
+// ParseCalendarFile reads the calendar at the path. Floating times, which
+// have no timezone, are in loc.
+func ParseCalendarFile(path string, loc *time.Location) (*Calendar, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening calendar")
+	}
+	defer f.Close()
+	return ParseCalendar(f, loc)
+}
+
+// icsProperty is a content line, e.g.
+// DTSTART;TZID=Europe/Lisbon:20261017T053000
+type icsProperty struct {
+	line   int
+	name   string
+	params map[string]string
+	value  string
+}
+
+func (p icsProperty) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s: %s", p.line, p.name, fmt.Sprintf(format, args...))
+}
+
+// ParseCalendar reads a calendar. Floating times, which have no timezone, are
+// in loc.
+func ParseCalendar(r io.Reader, loc *time.Location) (*Calendar, error) {
+	props, err := readICSProperties(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(props) == 0 || props[0].name != "BEGIN" || props[0].value != "VCALENDAR" {
+		return nil, errors.New("not an iCalendar file, expected it to start with BEGIN:VCALENDAR")
+	}
+
+	c := &Calendar{}
+	// modified instances of recurring events, by uid
+	overrides := map[string][]time.Time{}
+	var stack []string
+	var event []icsProperty
+	for _, p := range props {
+		switch p.name {
+		case "BEGIN":
+			stack = append(stack, p.value)
+			if p.value == "VEVENT" {
+				event = []icsProperty{}
+			}
+			continue
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1] != p.value {
+				return nil, p.errorf("unexpected END:%s", p.value)
+			}
+			stack = stack[:len(stack)-1]
+			if p.value == "VEVENT" {
+				e, recurrenceId, err := parseEvent(event, loc)
+				if err != nil {
+					return nil, err
+				}
+				if !e.cancelled {
+					c.events = append(c.events, e)
+				}
+				if !recurrenceId.IsZero() {
+					overrides[e.uid] = append(overrides[e.uid], recurrenceId)
+				}
+				event = nil
+			}
+			continue
+		}
+		if event != nil && len(stack) > 0 && stack[len(stack)-1] == "VEVENT" {
+			event = append(event, p)
+		}
+	}
+	if len(stack) != 0 {
+		return nil, fmt.Errorf("missing END:%s", stack[len(stack)-1])
+	}
+
+	// a modified instance replaces the occurrence of the recurring event
+	for _, e := range c.events {
+		if e.recurrence == nil {
+			continue
+		}
+		for _, t := range overrides[e.uid] {
+			e.recurrence.ExDate(t)
+		}
+	}
+	return c, nil
+}
+
+// readICSProperties unfolds the content lines and splits them into properties
+func readICSProperties(r io.Reader) ([]icsProperty, error) {
+	var lines []string
+	var lineNums []int
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		if (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+		lineNums = append(lineNums, n)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "reading calendar")
+	}
+
+	props := make([]icsProperty, 0, len(lines))
+	for i, line := range lines {
+		p, err := parseICSProperty(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", lineNums[i], err)
+		}
+		p.line = lineNums[i]
+		props = append(props, p)
+	}
+	return props, nil
+}
+
+func parseICSProperty(line string) (icsProperty, error) {
+	// the value starts after the first colon that is not within quotes
+	colon := -1
+	quoted := false
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		} else if r == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return icsProperty{}, fmt.Errorf("malformed content line %q", line)
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	p := icsProperty{
+		name:   strings.ToUpper(parts[0]),
+		params: map[string]string{},
+		value:  line[colon+1:],
+	}
+	for _, param := range parts[1:] {
+		kv := strings.SplitN(param, "=", 2)
+		if len(kv) != 2 {
+			return icsProperty{}, fmt.Errorf("malformed parameter %q", param)
+		}
+		p.params[strings.ToUpper(kv[0])] = strings.Trim(kv[1], `"`)
+	}
+	return p, nil
+}
+
+// parseEvent parses the properties of a VEVENT. The recurrence id is set for
+// modified instances of recurring events.
+func parseEvent(props []icsProperty, loc *time.Location) (*calendarEvent, time.Time, error) {
+	e := &calendarEvent{}
+	var dtstart, dtend, duration, rruleProp *icsProperty
+	var exdates, rdates []icsProperty
+	var recurrenceId time.Time
+	for i := range props {
+		p := &props[i]
+		switch p.name {
+		case "UID":
+			e.uid = p.value
+		case "SUMMARY":
+			e.summary = unescapeICSText(p.value)
+		case "DTSTART":
+			dtstart = p
+		case "DTEND":
+			dtend = p
+		case "DURATION":
+			duration = p
+		case "RRULE":
+			rruleProp = p
+		case "EXDATE":
+			exdates = append(exdates, *p)
+		case "RDATE":
+			rdates = append(rdates, *p)
+		case "RECURRENCE-ID":
+			t, _, err := parseICSTime(*p, loc)
+			if err != nil {
+				return nil, time.Time{}, err
+			}
+			recurrenceId = t
+		case "STATUS":
+			e.cancelled = strings.EqualFold(p.value, "CANCELLED")
+		}
+	}
+
+	if dtstart == nil {
+		return nil, time.Time{}, fmt.Errorf("event %q has no DTSTART", e.summary)
+	}
+	start, allDay, err := parseICSTime(*dtstart, loc)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	e.start = start
+
+	switch {
+	case dtend != nil:
+		end, _, err := parseICSTime(*dtend, loc)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		e.duration = end.Sub(start)
+	case duration != nil:
+		if e.duration, err = parseICSDuration(duration.value); err != nil {
+			return nil, time.Time{}, duration.errorf("%s", err)
+		}
+	case allDay:
+		e.duration = defaultAllDayDuration
+	default:
+		return nil, time.Time{}, dtstart.errorf("event %q has neither DTEND nor DURATION", e.summary)
+	}
+	if e.duration <= 0 {
+		return nil, time.Time{}, dtstart.errorf("event %q ends before it starts", e.summary)
+	}
+	if e.duration > maxCalendarEventLength {
+		return nil, time.Time{}, dtstart.errorf("event %q is longer than %s", e.summary, maxCalendarEventLength)
+	}
+
+	if rruleProp != nil {
+		option, err := rrule.StrToROptionInLocation(rruleProp.value, start.Location())
+		if err != nil {
+			return nil, time.Time{}, rruleProp.errorf("%s", err)
+		}
+		option.Dtstart = start
+		r, err := rrule.NewRRule(*option)
+		if err != nil {
+			return nil, time.Time{}, rruleProp.errorf("%s", err)
+		}
+		e.recurrence = &rrule.Set{}
+		e.recurrence.RRule(r)
+	}
+	if e.recurrence == nil && len(rdates) > 0 {
+		e.recurrence = &rrule.Set{}
+		e.recurrence.DTStart(start)
+		e.recurrence.RDate(start)
+	}
+	for _, p := range rdates {
+		ts, err := parseICSTimes(p, loc)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		for _, t := range ts {
+			e.recurrence.RDate(t)
+		}
+	}
+	for _, p := range exdates {
+		if e.recurrence == nil {
+			return nil, time.Time{}, p.errorf("event %q does not recur", e.summary)
+		}
+		ts, err := parseICSTimes(p, loc)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		for _, t := range ts {
+			e.recurrence.ExDate(t)
+		}
+	}
+
+	return e, recurrenceId, nil
+}
+
+// parseICSTime parses a DATE or DATE-TIME value. Dates are returned as the
+// start of the day in loc.
+func parseICSTime(p icsProperty, loc *time.Location) (time.Time, bool, error) {
+	ts, err := parseICSTimes(p, loc)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	if len(ts) != 1 {
+		return time.Time{}, false, p.errorf("expected a single value")
+	}
+	return ts[0], p.params["VALUE"] == "DATE" || len(p.value) == len(icsDateLayout), nil
+}
+
+// parseICSTimes parses the comma separated DATE or DATE-TIME values of the
+// property
+func parseICSTimes(p icsProperty, loc *time.Location) ([]time.Time, error) {
+	if tzid, ok := p.params["TZID"]; ok {
+		var err error
+		if loc, err = time.LoadLocation(tzid); err != nil {
+			return nil, p.errorf("unknown timezone %q, only IANA timezone names such as Europe/Lisbon are supported", tzid)
+		}
+	}
+
+	var ts []time.Time
+	for _, v := range strings.Split(p.value, ",") {
+		var t time.Time
+		var err error
+		switch {
+		case len(v) == len(icsDateLayout):
+			t, err = time.ParseInLocation(icsDateLayout, v, loc)
+		case strings.HasSuffix(v, "Z"):
+			t, err = time.Parse(icsUTCDateTimeLayout, v)
+		default:
+			t, err = time.ParseInLocation(icsDateTimeLayout, v, loc)
+		}
+		if err != nil {
+			return nil, p.errorf("invalid date or time %q", v)
+		}
+		ts = append(ts, t)
+	}
+	return ts, nil
+}
+
+var icsDurationRegex = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// parseICSDuration parses a duration such as PT1H30M or P1D
+func parseICSDuration(s string) (time.Duration, error) {
+	m := icsDurationRegex.FindStringSubmatch(s)
+	if m == nil || s == "P" || strings.HasSuffix(s, "T") {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var d time.Duration
+	for i, unit := range units {
+		if m[i+2] == "" {
+			continue
+		}
+		n, err := strconv.Atoi(m[i+2])
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		d += time.Duration(n) * unit
+	}
+	if m[1] == "-" {
+		d = -d
+	}
+	return d, nil
+}
+
+func unescapeICSText(s string) string {
+	return strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(s)
+}
+
+// Len returns the number of events, not counting their recurrences
+func (c *Calendar) Len() int {
+	return len(c.events)
+}
+
+// Windows returns the occurrences of the events that end after from and start
+// before until, ordered by start
+func (c *Calendar) Windows(from time.Time, until time.Time) []Window {
+	var windows []Window
+	for _, e := range c.events {
+		for _, start := range e.starts(from.Add(-e.duration), until) {
+			w := Window{Start: start, End: start.Add(e.duration), Reason: e.summary}
+			if w.End.After(from) && w.Start.Before(until) {
+				windows = append(windows, w)
+			}
+		}
+	}
+	sort.Slice(windows, func(i, j int) bool { return windows[i].Start.Before(windows[j].Start) })
+	return windows
+}
+
+// starts returns the starts of the event's occurrences in [after, before]
+func (e *calendarEvent) starts(after time.Time, before time.Time) []time.Time {
+	if e.recurrence == nil {
+		if e.start.Before(after) || e.start.After(before) {
+			return nil
+		}
+		return []time.Time{e.start}
+	}
+	return e.recurrence.Between(after, before, true)
+}
+
+// CalendarStore keeps the calendar file, which is re-read when it changes
+type CalendarStore struct {
+	path string
+}
+
+func NewCalendarStore(path string) *CalendarStore {
+	return &CalendarStore{path: path}
+}
+
+func (s *CalendarStore) Path() string {
+	return s.path
+}
+
+// Load parses the calendar file, returning nil if there is none
+func (s *CalendarStore) Load(loc *time.Location) (*Calendar, error) {
+	c, err := ParseCalendarFile(s.path, loc)
+	if os.IsNotExist(errors.Cause(err)) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid calendar %s", s.path)
+	}
+	return c, nil
+}
+
+// ModTime returns when the calendar file was last changed, zero if there is
+// none
+func (s *CalendarStore) ModTime() time.Time {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// Save validates and writes the calendar, replacing the previous one
+func (s *CalendarStore) Save(data []byte, loc *time.Location) (*Calendar, error) {
+	c, err := ParseCalendar(strings.NewReader(string(data)), loc)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return nil, errors.Wrap(err, "creating calendar directory")
+	}
+	tmp := s.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return nil, errors.Wrap(err, "writing calendar")
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return nil, errors.Wrap(err, "writing calendar")
+	}
+	return c, nil
+}
+
+// Remove deletes the calendar file
+func (s *CalendarStore) Remove() error {
+	if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "removing calendar")
+	}
+	return nil
+}
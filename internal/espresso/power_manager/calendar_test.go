@@ -0,0 +1,381 @@
+package power_manager
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ics joins the lines of a calendar with CRLF
+func ics(lines ...string) string {
+	return strings.Join(append([]string{"BEGIN:VCALENDAR", "VERSION:2.0"}, append(lines, "END:VCALENDAR")...), "\r\n") + "\r\n"
+}
+
+func TestCalendar_Windows(t *testing.T) {
+	lisbon, err := time.LoadLocation("Europe/Lisbon")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Monday the 19th to Sunday the 25th of October
+	from := time.Date(2026, 10, 19, 0, 0, 0, 0, lisbon)
+	until := from.AddDate(0, 0, 7)
+
+	tests := []struct {
+		name string
+		ics  string
+		want []Window
+	}{
+		{
+			name: "single event",
+			ics: ics(
+				"BEGIN:VEVENT",
+				"UID:1",
+				"SUMMARY:Team breakfast",
+				"DTSTART;TZID=Europe/Lisbon:20261020T080000",
+				"DTEND;TZID=Europe/Lisbon:20261020T093000",
+				"END:VEVENT",
+			),
+			want: []Window{
+				{Start: time.Date(2026, 10, 20, 8, 0, 0, 0, lisbon), End: time.Date(2026, 10, 20, 9, 30, 0, 0, lisbon), Reason: "Team breakfast"},
+			},
+		},
+		{
+			name: "weekly with exceptions",
+			ics: ics(
+				"BEGIN:VEVENT",
+				"UID:2",
+				"SUMMARY:Standup",
+				"DTSTART;TZID=Europe/Lisbon:20261005T090000",
+				"DURATION:PT1H",
+				"RRULE:FREQ=WEEKLY;BYDAY=MO,WE,FR",
+				"EXDATE;TZID=Europe/Lisbon:20261021T090000",
+				"END:VEVENT",
+			),
+			want: []Window{
+				{Start: time.Date(2026, 10, 19, 9, 0, 0, 0, lisbon), End: time.Date(2026, 10, 19, 10, 0, 0, 0, lisbon), Reason: "Standup"},
+				{Start: time.Date(2026, 10, 23, 9, 0, 0, 0, lisbon), End: time.Date(2026, 10, 23, 10, 0, 0, 0, lisbon), Reason: "Standup"},
+			},
+		},
+		{
+			name: "modified and cancelled instances",
+			ics: ics(
+				"BEGIN:VEVENT",
+				"UID:3",
+				"SUMMARY:Standup",
+				"DTSTART;TZID=Europe/Lisbon:20261005T090000",
+				"DTEND;TZID=Europe/Lisbon:20261005T100000",
+				"RRULE:FREQ=DAILY;COUNT=30",
+				"END:VEVENT",
+				"BEGIN:VEVENT",
+				"UID:3",
+				"SUMMARY:Late standup",
+				"RECURRENCE-ID;TZID=Europe/Lisbon:20261020T090000",
+				"DTSTART;TZID=Europe/Lisbon:20261020T110000",
+				"DTEND;TZID=Europe/Lisbon:20261020T120000",
+				"END:VEVENT",
+				"BEGIN:VEVENT",
+				"UID:3",
+				"RECURRENCE-ID;TZID=Europe/Lisbon:20261021T090000",
+				"DTSTART;TZID=Europe/Lisbon:20261021T090000",
+				"DTEND;TZID=Europe/Lisbon:20261021T100000",
+				"STATUS:CANCELLED",
+				"END:VEVENT",
+			),
+			want: []Window{
+				{Start: time.Date(2026, 10, 19, 9, 0, 0, 0, lisbon), End: time.Date(2026, 10, 19, 10, 0, 0, 0, lisbon), Reason: "Standup"},
+				{Start: time.Date(2026, 10, 20, 11, 0, 0, 0, lisbon), End: time.Date(2026, 10, 20, 12, 0, 0, 0, lisbon), Reason: "Late standup"},
+				{Start: time.Date(2026, 10, 22, 9, 0, 0, 0, lisbon), End: time.Date(2026, 10, 22, 10, 0, 0, 0, lisbon), Reason: "Standup"},
+				{Start: time.Date(2026, 10, 23, 9, 0, 0, 0, lisbon), End: time.Date(2026, 10, 23, 10, 0, 0, 0, lisbon), Reason: "Standup"},
+				{Start: time.Date(2026, 10, 24, 9, 0, 0, 0, lisbon), End: time.Date(2026, 10, 24, 10, 0, 0, 0, lisbon), Reason: "Standup"},
+				// clocks go back on the 25th, the event keeping its wall clock time
+				{Start: time.Date(2026, 10, 25, 9, 0, 0, 0, lisbon), End: time.Date(2026, 10, 25, 10, 0, 0, 0, lisbon), Reason: "Standup"},
+			},
+		},
+		{
+			name: "other timezones",
+			ics: ics(
+				"BEGIN:VEVENT",
+				"UID:4",
+				"SUMMARY:Call with Tokyo",
+				"DTSTART;TZID=Asia/Tokyo:20261021T170000",
+				"DTEND;TZID=Asia/Tokyo:20261021T180000",
+				"END:VEVENT",
+				"BEGIN:VEVENT",
+				"UID:5",
+				"SUMMARY:Deploy",
+				"DTSTART:20261022T140000Z",
+				"DTEND:20261022T143000Z",
+				"END:VEVENT",
+			),
+			want: []Window{
+				{Start: time.Date(2026, 10, 21, 17, 0, 0, 0, tokyo), End: time.Date(2026, 10, 21, 18, 0, 0, 0, tokyo), Reason: "Call with Tokyo"},
+				{Start: time.Date(2026, 10, 22, 14, 0, 0, 0, time.UTC), End: time.Date(2026, 10, 22, 14, 30, 0, 0, time.UTC), Reason: "Deploy"},
+			},
+		},
+		{
+			name: "all-day floating event",
+			ics: ics(
+				"BEGIN:VEVENT",
+				"UID:6",
+				"SUMMARY:Open day",
+				"DTSTART;VALUE=DATE:20261024",
+				"END:VEVENT",
+			),
+			want: []Window{
+				{Start: time.Date(2026, 10, 24, 0, 0, 0, 0, lisbon), End: time.Date(2026, 10, 25, 0, 0, 0, 0, lisbon), Reason: "Open day"},
+			},
+		},
+		{
+			name: "folded lines and escaped text",
+			ics: ics(
+				"BEGIN:VEVENT",
+				"UID:7",
+				"SUMMARY:Cupping\\, ",
+				" roasters\\; guests",
+				"DTSTART;TZID=\"Europe/Lisbon\":20261019T150000",
+				"DTEND;TZID=Europe/Lisbon:20261019T160000",
+				"END:VEVENT",
+			),
+			want: []Window{
+				{Start: time.Date(2026, 10, 19, 15, 0, 0, 0, lisbon), End: time.Date(2026, 10, 19, 16, 0, 0, 0, lisbon), Reason: "Cupping, roasters; guests"},
+			},
+		},
+		{
+			name: "ended before from",
+			ics: ics(
+				"BEGIN:VEVENT",
+				"UID:8",
+				"DTSTART;TZID=Europe/Lisbon:20261018T230000",
+				"DTEND;TZID=Europe/Lisbon:20261019T000000",
+				"END:VEVENT",
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := ParseCalendar(strings.NewReader(tt.ics), lisbon)
+			if err != nil {
+				t.Fatalf("ParseCalendar() error = %v", err)
+			}
+			got := c.Windows(from, until)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Windows() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if !got[i].Start.Equal(tt.want[i].Start) || !got[i].End.Equal(tt.want[i].End) || got[i].Reason != tt.want[i].Reason {
+					t.Errorf("Windows()[%d] = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseCalendar_invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		ics     string
+		wantErr string
+	}{
+		{
+			name:    "not a calendar",
+			ics:     "BEGIN:VCARD\r\nEND:VCARD\r\n",
+			wantErr: "not an iCalendar file",
+		},
+		{
+			name:    "not a content line",
+			ics:     "BEGIN:VCALENDAR\r\nhello\r\n",
+			wantErr: `line 2: malformed content line "hello"`,
+		},
+		{
+			name:    "unterminated event",
+			ics:     "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nDTSTART:20261019T150000Z\r\n",
+			wantErr: "missing END:VEVENT",
+		},
+		{
+			name:    "mismatched end",
+			ics:     ics("BEGIN:VEVENT", "END:VTODO"),
+			wantErr: "line 4: END: unexpected END:VTODO",
+		},
+		{
+			name:    "no start",
+			ics:     ics("BEGIN:VEVENT", "SUMMARY:Nothing", "END:VEVENT"),
+			wantErr: `event "Nothing" has no DTSTART`,
+		},
+		{
+			name:    "no end",
+			ics:     ics("BEGIN:VEVENT", "DTSTART:20261019T150000Z", "END:VEVENT"),
+			wantErr: "has neither DTEND nor DURATION",
+		},
+		{
+			name:    "unknown timezone",
+			ics:     ics("BEGIN:VEVENT", "DTSTART;TZID=W. Europe Standard Time:20261019T150000", "DURATION:PT1H", "END:VEVENT"),
+			wantErr: `line 4: DTSTART: unknown timezone "W. Europe Standard Time"`,
+		},
+		{
+			name:    "invalid time",
+			ics:     ics("BEGIN:VEVENT", "DTSTART:20261019T250000", "DURATION:PT1H", "END:VEVENT"),
+			wantErr: `invalid date or time "20261019T250000"`,
+		},
+		{
+			name:    "invalid duration",
+			ics:     ics("BEGIN:VEVENT", "DTSTART:20261019T150000", "DURATION:1 hour", "END:VEVENT"),
+			wantErr: `line 5: DURATION: invalid duration "1 hour"`,
+		},
+		{
+			name:    "ends before it starts",
+			ics:     ics("BEGIN:VEVENT", "DTSTART:20261019T150000", "DTEND:20261019T140000", "END:VEVENT"),
+			wantErr: "ends before it starts",
+		},
+		{
+			name:    "too long",
+			ics:     ics("BEGIN:VEVENT", "DTSTART:20261019T150000", "DURATION:P2W", "END:VEVENT"),
+			wantErr: "is longer than",
+		},
+		{
+			name:    "invalid rule",
+			ics:     ics("BEGIN:VEVENT", "DTSTART:20261019T150000", "DURATION:PT1H", "RRULE:FREQ=SOMETIMES", "END:VEVENT"),
+			wantErr: "line 6: RRULE:",
+		},
+		{
+			name:    "exception of a single event",
+			ics:     ics("BEGIN:VEVENT", "DTSTART:20261019T150000", "DURATION:PT1H", "EXDATE:20261019T150000", "END:VEVENT"),
+			wantErr: "does not recur",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCalendar(strings.NewReader(tt.ics), time.UTC)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseCalendar() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCalendarStore(t *testing.T) {
+	store := NewCalendarStore(filepath.Join(t.TempDir(), "espresso", "schedule.ics"))
+
+	if c, err := store.Load(time.UTC); c != nil || err != nil {
+		t.Fatalf("Load() before Save() = %v, %v, want no calendar", c, err)
+	}
+	if !store.ModTime().IsZero() {
+		t.Errorf("ModTime() before Save() = %v, want zero", store.ModTime())
+	}
+
+	if _, err := store.Save([]byte("BEGIN:VEVENT\r\n"), time.UTC); err == nil {
+		t.Fatalf("Save() of an invalid calendar should fail")
+	}
+	if c, _ := store.Load(time.UTC); c != nil {
+		t.Errorf("Save() of an invalid calendar should not write it")
+	}
+
+	data := ics("BEGIN:VEVENT", "DTSTART:20261019T150000Z", "DURATION:PT1H", "RRULE:FREQ=DAILY", "END:VEVENT")
+	if _, err := store.Save([]byte(data), time.UTC); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	c, err := store.Load(time.UTC)
+	if err != nil || c == nil || c.Len() != 1 {
+		t.Fatalf("Load() = %v, %v, want the saved calendar", c, err)
+	}
+	if store.ModTime().IsZero() {
+		t.Errorf("ModTime() after Save() should not be zero")
+	}
+
+	if err := store.Remove(); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if c, err := store.Load(time.UTC); c != nil || err != nil {
+		t.Errorf("Load() after Remove() = %v, %v, want no calendar", c, err)
+	}
+}
+
+func TestPowerManager_calendar(t *testing.T) {
+	p, _ := newTestPowerManager()
+	lisbon, err := time.LoadLocation("Europe/Lisbon")
+	if err != nil {
+		t.Fatal(err)
+	}
+	schedule := PowerSchedule{
+		Timezone: "Europe/Lisbon",
+		Frames:   map[time.Weekday][]PowerOnInterval{},
+		Skips:    []DateRange{{From: mustDate(t, "2026-10-21"), To: mustDate(t, "2026-10-21")}},
+	}
+	if err := p.SetSchedule(schedule); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := p.ImportCalendar([]byte(ics())); err == nil {
+		t.Errorf("ImportCalendar() without a calendar file should fail")
+	}
+
+	store := NewCalendarStore(filepath.Join(t.TempDir(), "schedule.ics"))
+	if err := p.UseCalendar(store, time.Minute); err != nil {
+		t.Fatalf("UseCalendar() error = %v", err)
+	}
+	_, err = p.ImportCalendar([]byte(ics(
+		"BEGIN:VEVENT",
+		"DTSTART:20261019T090000",
+		"DURATION:PT1H",
+		"RRULE:FREQ=DAILY",
+		"END:VEVENT",
+	)))
+	if err != nil {
+		t.Fatalf("ImportCalendar() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want bool
+	}{
+		{name: "in an event", at: time.Date(2026, 10, 20, 9, 30, 0, 0, lisbon), want: true},
+		{name: "after an event", at: time.Date(2026, 10, 20, 10, 0, 0, 0, lisbon), want: false},
+		{name: "skipped date", at: time.Date(2026, 10, 21, 9, 30, 0, 0, lisbon), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, got := p.inSchedule(tt.at); got != tt.want {
+				t.Errorf("inSchedule() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if err := p.RemoveCalendar(); err != nil {
+		t.Fatalf("RemoveCalendar() error = %v", err)
+	}
+	if _, got := p.inSchedule(time.Date(2026, 10, 20, 9, 30, 0, 0, lisbon)); got {
+		t.Errorf("inSchedule() after RemoveCalendar() = true, want false")
+	}
+}
+
+func TestPowerManager_calendarRefreshBehaviour(t *testing.T) {
+	p, _ := newTestPowerManager()
+	store := NewCalendarStore(filepath.Join(t.TempDir(), "schedule.ics"))
+	if err := p.UseCalendar(store, time.Minute); err != nil {
+		t.Fatalf("UseCalendar() error = %v", err)
+	}
+
+	// another process writes the file
+	other := NewCalendarStore(store.Path())
+	if _, err := other.Save([]byte(ics("BEGIN:VEVENT", "DTSTART;VALUE=DATE:20261024", "END:VEVENT")), time.UTC); err != nil {
+		t.Fatal(err)
+	}
+
+	p.calendarRefreshBehaviour(p.calendarCheckedAt.Add(time.Second))
+	if p.calendar != nil {
+		t.Errorf("calendarRefreshBehaviour() reloaded before the refresh interval")
+	}
+	p.calendarRefreshBehaviour(p.calendarCheckedAt.Add(time.Minute))
+	if p.calendar == nil || !reflect.DeepEqual(p.calendarModTime, store.ModTime()) {
+		t.Errorf("calendarRefreshBehaviour() did not reload the changed calendar")
+	}
+}
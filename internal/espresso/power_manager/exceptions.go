@@ -0,0 +1,220 @@
+package power_manager
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const dateLayout = "2006-01-02"
+
+// Date is a calendar day in the schedule's timezone
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// ParseDate parses a YYYY-MM-DD date
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		return Date{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", s)
+	}
+	return DateOf(t), nil
+}
+
+// DateOf returns the date of t in t's timezone
+func DateOf(t time.Time) Date {
+	return Date{Year: t.Year(), Month: t.Month(), Day: t.Day()}
+}
+
+func (d Date) IsZero() bool {
+	return d == Date{}
+}
+
+func (d Date) Before(o Date) bool {
+	if d.Year != o.Year {
+		return d.Year < o.Year
+	}
+	if d.Month != o.Month {
+		return d.Month < o.Month
+	}
+	return d.Day < o.Day
+}
+
+// In returns the start of the day in the timezone
+func (d Date) In(loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+func (d Date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// DateRange is a range of days, both included, on which the weekly intervals
+// are skipped, e.g. a vacation or a public holiday
+type DateRange struct {
+	From   Date
+	To     Date
+	Reason string
+}
+
+func (r DateRange) contains(d Date) bool {
+	return !d.Before(r.From) && !r.To.Before(d)
+}
+
+// OneOffInterval powers on the machine on a single day in addition to the
+// weekly intervals, e.g. to warm up earlier than usual tomorrow
+type OneOffInterval struct {
+	Date     Date
+	Interval PowerOnInterval
+	Reason   string
+}
+
+type DateRangeConfig struct {
+	From   string
+	To     string
+	Reason string `json:",omitempty"`
+}
+
+type OneOffIntervalConfig struct {
+	Date     string
+	Interval string
+	Reason   string `json:",omitempty"`
+}
+
+func newDateRange(c DateRangeConfig) (DateRange, error) {
+	from, err := ParseDate(c.From)
+	if err != nil {
+		return DateRange{}, err
+	}
+	to := from
+	if c.To != "" {
+		if to, err = ParseDate(c.To); err != nil {
+			return DateRange{}, err
+		}
+	}
+	return DateRange{From: from, To: to, Reason: c.Reason}, nil
+}
+
+func newOneOffInterval(c OneOffIntervalConfig) (OneOffInterval, error) {
+	date, err := ParseDate(c.Date)
+	if err != nil {
+		return OneOffInterval{}, err
+	}
+	interval, err := ParsePowerOnInterval(c.Interval)
+	if err != nil {
+		return OneOffInterval{}, err
+	}
+	return OneOffInterval{Date: date, Interval: interval, Reason: c.Reason}, nil
+}
+
+// validateExceptions checks that skipped ranges do not end before they start
+// and that one-off intervals are valid and do not overlap each other. One-off
+// intervals may overlap the weekly ones.
+func (s PowerSchedule) validateExceptions() error {
+	for _, skip := range s.Skips {
+		if skip.From.IsZero() || skip.To.IsZero() {
+			return errors.New("skipped dates must have a start and an end")
+		}
+		if skip.To.Before(skip.From) {
+			return fmt.Errorf("skipped dates %s to %s end before they start", skip.From, skip.To)
+		}
+	}
+
+	type span struct {
+		start, end time.Time
+		extra      OneOffInterval
+	}
+	var spans []span
+	for _, extra := range s.Extras {
+		if extra.Date.IsZero() {
+			return fmt.Errorf("one-off interval %s must have a date", extra.Interval)
+		}
+		if err := extra.Interval.validate(); err != nil {
+			return errors.Wrapf(err, "one-off interval on %s", extra.Date)
+		}
+		w := occurrence(extra.Date.In(time.UTC), extra.Interval)
+		spans = append(spans, span{start: w.Start, end: w.End, extra: extra})
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start.Before(spans[j].start) })
+	for i := 1; i < len(spans); i++ {
+		if prev, cur := spans[i-1], spans[i]; cur.start.Before(prev.end) {
+			return fmt.Errorf("one-off intervals %s %s and %s %s overlap", prev.extra.Date, prev.extra.Interval, cur.extra.Date, cur.extra.Interval)
+		}
+	}
+	return nil
+}
+
+// IsSkipped is true if the weekly intervals starting on the date are skipped,
+// the schedule being paused or the date being in a skipped range
+func (s PowerSchedule) IsSkipped(d Date) (bool, string) {
+	if !s.PausedUntil.IsZero() && d.Before(s.PausedUntil) {
+		return true, "paused until " + s.PausedUntil.String()
+	}
+	for _, skip := range s.Skips {
+		if skip.contains(d) {
+			return true, skip.Reason
+		}
+	}
+	return false, ""
+}
+
+// scheduledInterval is an interval starting on a given day
+type scheduledInterval struct {
+	interval PowerOnInterval
+	oneOff   bool
+	reason   string
+}
+
+// intervalsOn returns the weekly intervals starting on the day unless it is
+// skipped, followed by the one-off intervals of the day
+func (s PowerSchedule) intervalsOn(day time.Time) []scheduledInterval {
+	var intervals []scheduledInterval
+	date := DateOf(day)
+	if skipped, _ := s.IsSkipped(date); !skipped {
+		for _, interval := range s.Frames[day.Weekday()] {
+			intervals = append(intervals, scheduledInterval{interval: interval})
+		}
+	}
+	for _, extra := range s.Extras {
+		if extra.Date == date {
+			intervals = append(intervals, scheduledInterval{interval: extra.Interval, oneOff: true, reason: extra.Reason})
+		}
+	}
+	return intervals
+}
+
+// Windows returns the power-on windows that end after from and start within
+// the given number of days of it, in the timezone loc, ordered by start
+func (s PowerSchedule) Windows(from time.Time, days int, loc *time.Location) []Window {
+	from = from.In(loc)
+	until := from.AddDate(0, 0, days)
+	// windows of yesterday may cross midnight into today
+	day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc).AddDate(0, 0, -1)
+
+	var windows []Window
+	for ; day.Before(until); day = day.AddDate(0, 0, 1) {
+		for _, si := range s.intervalsOn(day) {
+			w := si.window(day)
+			if w.End.After(from) && w.Start.Before(until) {
+				windows = append(windows, w)
+			}
+		}
+	}
+	sort.Slice(windows, func(i, j int) bool { return windows[i].Start.Before(windows[j].Start) })
+	return windows
+}
+
+func (si scheduledInterval) window(day time.Time) Window {
+	w := occurrence(day, si.interval)
+	w.OneOff = si.oneOff
+	w.Reason = si.reason
+	return w
+}
@@ -0,0 +1,167 @@
+package power_manager
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func mustDate(t *testing.T, s string) Date {
+	t.Helper()
+	d, err := ParseDate(s)
+	if err != nil {
+		t.Fatalf("ParseDate(%q) error = %v", s, err)
+	}
+	return d
+}
+
+func TestPowerSchedule_validateExceptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		c       PowerScheduleConfig
+		wantErr bool
+	}{
+		{
+			name: "vacation, holiday and pause",
+			c: PowerScheduleConfig{
+				Skips: []DateRangeConfig{
+					{From: "2026-08-01", To: "2026-08-15", Reason: "vacation"},
+					{From: "2026-12-25", Reason: "Christmas"},
+				},
+				PausedUntil: "2026-11-01",
+			},
+		},
+		{
+			name: "adjacent one-offs",
+			c: PowerScheduleConfig{Extras: []OneOffIntervalConfig{
+				{Date: "2026-10-17", Interval: "23:00-01:00"},
+				{Date: "2026-10-18", Interval: "01:00-02:00"},
+			}},
+		},
+		{
+			name:    "reversed skip",
+			c:       PowerScheduleConfig{Skips: []DateRangeConfig{{From: "2026-08-15", To: "2026-08-01"}}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid date",
+			c:       PowerScheduleConfig{Skips: []DateRangeConfig{{From: "2026-02-30"}}},
+			wantErr: true,
+		},
+		{
+			name: "overlapping one-offs",
+			c: PowerScheduleConfig{Extras: []OneOffIntervalConfig{
+				{Date: "2026-10-17", Interval: "23:00-01:00"},
+				{Date: "2026-10-18", Interval: "00:30-02:00"},
+			}},
+			wantErr: true,
+		},
+		{
+			name:    "empty one-off",
+			c:       PowerScheduleConfig{Extras: []OneOffIntervalConfig{{Date: "2026-10-17", Interval: "05:30-05:30"}}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid pause",
+			c:       PowerScheduleConfig{PausedUntil: "soon"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewPowerSchedule(tt.c); (err != nil) != tt.wantErr {
+				t.Errorf("NewPowerSchedule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPowerSchedule_Windows(t *testing.T) {
+	lisbon, err := time.LoadLocation("Europe/Lisbon")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewPowerSchedule(PowerScheduleConfig{
+		Timezone: "Europe/Lisbon",
+		Frames: map[string][]string{
+			"Sunday":   {"09:00-10:00"},
+			"Monday":   {"06:00-08:00"},
+			"Tuesday":  {"06:00-08:00"},
+			"Saturday": {"23:00-01:00"},
+		},
+		Skips:  []DateRangeConfig{{From: "2026-10-20", Reason: "holiday"}},
+		Extras: []OneOffIntervalConfig{{Date: "2026-10-20", Interval: "05:30-07:00", Reason: "early start"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Saturday the 17th of October, half an hour into the late interval
+	from := time.Date(2026, 10, 17, 23, 30, 0, 0, lisbon)
+	want := []Window{
+		{Start: time.Date(2026, 10, 17, 23, 0, 0, 0, lisbon), End: time.Date(2026, 10, 18, 1, 0, 0, 0, lisbon)},
+		{Start: time.Date(2026, 10, 18, 9, 0, 0, 0, lisbon), End: time.Date(2026, 10, 18, 10, 0, 0, 0, lisbon)},
+		{Start: time.Date(2026, 10, 19, 6, 0, 0, 0, lisbon), End: time.Date(2026, 10, 19, 8, 0, 0, 0, lisbon)},
+		{Start: time.Date(2026, 10, 20, 5, 30, 0, 0, lisbon), End: time.Date(2026, 10, 20, 7, 0, 0, 0, lisbon), OneOff: true, Reason: "early start"},
+	}
+	if got := s.Windows(from, 3, lisbon); !reflect.DeepEqual(got, want) {
+		t.Errorf("Windows() = %v, want %v", got, want)
+	}
+}
+
+func TestPowerSchedule_at_exceptions(t *testing.T) {
+	lisbon, err := time.LoadLocation("Europe/Lisbon")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := PowerSchedule{
+		Timezone: "Europe/Lisbon",
+		Frames: map[time.Weekday][]PowerOnInterval{
+			time.Monday:  {mustInterval(t, "06:00-08:00")},
+			time.Tuesday: {mustInterval(t, "23:00-01:00")},
+		},
+		Skips:       []DateRange{{From: mustDate(t, "2026-10-26"), To: mustDate(t, "2026-10-27")}},
+		Extras:      []OneOffInterval{{Date: mustDate(t, "2026-10-27"), Interval: mustInterval(t, "05:30-06:00")}},
+		PausedUntil: mustDate(t, "2026-10-20"),
+	}
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want bool
+	}{
+		{name: "paused", at: time.Date(2026, 10, 19, 7, 0, 0, 0, lisbon), want: false},
+		{name: "resumed", at: time.Date(2026, 10, 20, 23, 30, 0, 0, lisbon), want: true},
+		{name: "resumed past midnight", at: time.Date(2026, 10, 21, 0, 30, 0, 0, lisbon), want: true},
+		{name: "skipped", at: time.Date(2026, 10, 26, 7, 0, 0, 0, lisbon), want: false},
+		{name: "skipped past midnight", at: time.Date(2026, 10, 28, 0, 30, 0, 0, lisbon), want: false},
+		{name: "one-off on a skipped day", at: time.Date(2026, 10, 27, 5, 45, 0, 0, lisbon), want: true},
+		{name: "after the skipped days", at: time.Date(2026, 11, 2, 7, 0, 0, 0, lisbon), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, got := s.at(tt.at, lisbon); got != tt.want {
+				t.Errorf("at() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPowerSchedule_Config(t *testing.T) {
+	c := PowerScheduleConfig{
+		Timezone:    "Europe/Lisbon",
+		Frames:      map[string][]string{"Monday": {"06:00-08:00"}},
+		Skips:       []DateRangeConfig{{From: "2026-08-01", To: "2026-08-15", Reason: "vacation"}},
+		Extras:      []OneOffIntervalConfig{{Date: "2026-10-17", Interval: "05:30-07:00"}},
+		PausedUntil: "2026-11-01",
+	}
+	s, err := NewPowerSchedule(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Config(); !reflect.DeepEqual(got, c) {
+		t.Errorf("Config() = %+v, want %+v", got, c)
+	}
+}
@@ -1,246 +1,809 @@
 package power_manager
 
 import (
+	"errors"
+	"sort"
+	"sync"
 	"time"
 
-	"github.com/stianeikeland/go-rpio/v4"
+	"github.com/google/uuid"
+	"github.com/luiccn/espresso-controller/internal/espresso/brew"
+	"github.com/luiccn/espresso-controller/internal/espresso/button"
+	"github.com/luiccn/espresso-controller/internal/espresso/event_log"
+	"github.com/luiccn/espresso-controller/internal/espresso/gpio"
+	"github.com/luiccn/espresso-controller/internal/espresso/led"
+	"github.com/luiccn/espresso-controller/internal/log"
+	"go.uber.org/zap"
 )
 
-type PowerOnInterval struct {
-	From int
-	To   int
-}
-
-type PowerSchedule struct {
-	Frames map[time.Weekday][]PowerOnInterval
-}
-
+// PowerManager powers the machine on and off following the schedule, the
+// power button and API calls. Its state is guarded by mu, so its methods can be
+// called from any goroutine.
 type PowerManager struct {
-	PowerSchedule        PowerSchedule
-	AutoOffDuration      time.Duration
-	powerRelayPin        rpio.Pin
-	powerButtonPin       rpio.Pin
-	OnSince              time.Time
-	CurrentlyInASchedule bool
-	LastInteraction      string
-	StopScheduling       bool
-	currentSchedule      PowerOnInterval
-	totalOff             bool
+	powerRelayPin gpio.Pin
+	powerLed      *led.LED
+
+	mu                sync.Mutex
+	powerSchedule     PowerSchedule
+	autoOff           AutoOffConfig
+	state             State
+	reason            Reason
+	since             time.Time
+	onSince           time.Time
+	lastActivity      time.Time
+	warnedOffAt       time.Time
+	warningChans      map[uuid.UUID]chan AutoOffWarning
+	transitionChans   map[uuid.UUID]chan Transition
+	history           history
+	currentSchedule   Window
+	scheduleStore     *ScheduleStore
+	location          *time.Location
+	calendar          *Calendar
+	calendarStore     *CalendarStore
+	calendarRefresh   time.Duration
+	calendarCheckedAt time.Time
+	calendarModTime   time.Time
+	faultSource       FaultSource
+	temperatureSource TemperatureSource
+	events            *event_log.Log
+	// the clock of API calls, replaced in tests
+	now func() time.Time
+
+	shutdownCh chan struct{}
 }
 
 type PowerManagerStatus struct {
-	PowerSchedule        PowerSchedule
-	AutoOffDuration      time.Duration
-	OnSince              time.Time
+	PowerSchedule   PowerSchedule
+	AutoOffDuration time.Duration
+	// when the machine will be powered off, zero if it will not be
+	AutoOffAt time.Time
+	State     State
+	Reason    Reason
+	// when the current state was entered
+	Since   time.Time
+	OnSince time.Time
+	PowerOn bool
+	// shown on the power LED
+	LedPattern led.Pattern
+	// derived from the state, kept for existing clients
 	CurrentlyInASchedule bool
 	LastInteraction      string
-	PowerOn              bool
 	StopScheduling       bool
 	TotalOff             bool
 }
 
-func NewPowerManager(powerSchedule PowerSchedule, autoOffDuration time.Duration, powerRelayPinNum int, powerButtonPinNum int, powerLedPinNum int) *PowerManager {
+// AutoOffConfig sets when a machine powered on by the user is powered off
+type AutoOffConfig struct {
+	// Time after power on, or after the last activity if Idle, the machine is
+	// powered off. Never if zero.
+	Duration time.Duration
+	// Restart the countdown on every brew and interaction through the button or
+	// the API
+	Idle bool
+	// Subscribers are warned this long before the machine is powered off, not
+	// at all if zero
+	Warning time.Duration
+}
+
+// AutoOffWarning tells subscribers the machine is about to be powered off
+type AutoOffWarning struct {
+	At    time.Time
+	OffAt time.Time
+}
+
+const (
+	warningBufferSize    = 10
+	transitionBufferSize = 10
+)
+
+// FaultSource reports whether a fault keeps the machine powered off, e.g. the
+// safety supervisor
+type FaultSource interface {
+	Faulted() bool
+}
+
+// TemperatureSource reports whether the boiler is at its setpoint
+type TemperatureSource interface {
+	AtTemperature() bool
+}
+
+func NewPowerManager(driver gpio.Driver, powerSchedule PowerSchedule, autoOff AutoOffConfig, powerRelayPinNum int, powerLedPinNum int) *PowerManager {
 
-	powerRelayPin := rpio.Pin(powerRelayPinNum)
+	powerRelayPin := driver.Pin(powerRelayPinNum)
 	powerRelayPin.Output()
 	powerRelayPin.Low()
 
-	powerButtonPin := rpio.Pin(powerButtonPinNum)
-	powerButtonPin.Input()
-	powerButtonPin.PullDown()
-
-	powerLedPin := rpio.Pin(powerLedPinNum)
-	powerLedPin.Output()
-	powerLedPin.Low()
+	location, err := powerSchedule.Location()
+	if err != nil {
+		log.Error("Falling back to the system timezone for the power schedule", zap.Error(err))
+		location = time.Local
+	}
 
 	return &PowerManager{
-		PowerSchedule:        powerSchedule,
-		AutoOffDuration:      autoOffDuration,
-		CurrentlyInASchedule: false,
-		OnSince:              time.Time{},
-		powerRelayPin:        powerRelayPin,
-		powerButtonPin:       powerButtonPin,
-		LastInteraction:      "Start-up Off",
-		StopScheduling:       false,
-		currentSchedule:      PowerOnInterval{},
-		totalOff:             false,
+		powerSchedule:   powerSchedule,
+		autoOff:         autoOff,
+		powerRelayPin:   powerRelayPin,
+		powerLed:        led.NewLED(driver, powerLedPinNum),
+		state:           StateOff,
+		reason:          ReasonStartup,
+		since:           time.Now(),
+		now:             time.Now,
+		location:        location,
+		warningChans:    map[uuid.UUID]chan AutoOffWarning{},
+		transitionChans: map[uuid.UUID]chan Transition{},
+		shutdownCh:      make(chan struct{}),
 	}
 }
 
 func (p *PowerManager) Run() {
+	p.powerLed.Run()
 	go func() {
 		for {
+			select {
+			case <-p.shutdownCh:
+				return
+			default:
+			}
 
 			currentTime := time.Now()
+			p.calendarRefreshBehaviour(currentTime)
 
-			p.powerButtonBehaviour()
-
-			if p.totalOff {
-				continue
-			}
-
+			p.mu.Lock()
+			p.faultBehaviour(currentTime)
 			p.powerScheduleBehaviour(currentTime)
-			p.autoOffBehaviour()
+			p.autoOffBehaviour(currentTime)
+			p.mu.Unlock()
+
+			p.ledBehaviour()
 
 			time.Sleep(200 * time.Millisecond)
 		}
 	}()
 }
 
-func (p *PowerManager) autoOffBehaviour() {
-	if !p.OnSince.Equal(time.Time{}) && time.Now().Sub(p.OnSince) >= p.AutoOffDuration && !p.CurrentlyInASchedule {
-		p.powerOff()
-		p.LastInteraction = "Auto-off"
+// ledBehaviour shows the state of the machine on the power LED
+func (p *PowerManager) ledBehaviour() {
+	p.mu.Lock()
+	state, source := p.state, p.temperatureSource
+	p.mu.Unlock()
+	// asked without holding mu, as the source may call the power manager
+	atTemperature := source != nil && source.AtTemperature()
+	p.powerLed.SetPattern(ledPattern(state, atTemperature))
+}
+
+func ledPattern(state State, atTemperature bool) led.Pattern {
+	switch {
+	case state == StateFault:
+		return led.PatternFastBlink
+	case state == StateScheduleSuppressed:
+		return led.PatternDoubleBlink
+	case state.PowerOn() && atTemperature:
+		return led.PatternSolid
+	case state.PowerOn():
+		return led.PatternSlowBlink
+	default:
+		return led.PatternOff
 	}
 }
 
-func (p *PowerManager) powerScheduleBehaviour(currentTime time.Time) {
-	powerOnInterval, inSchedule := p.inSchedule(currentTime)
-	if inSchedule && p.currentSchedule != powerOnInterval {
-		p.StopScheduling = false
-		p.currentSchedule = powerOnInterval
+// transition moves to the state, switching the relay, unless the state
+// machine does not allow it. mu must be held.
+func (p *PowerManager) transition(to State, reason Reason, at time.Time) bool {
+	from := p.state
+	if from == to {
+		return false
 	}
-	if !p.StopScheduling {
-		if inSchedule {
-			p.powerOn()
-			p.CurrentlyInASchedule = true
-			p.LastInteraction = "Scheduled Power On"
-		} else {
-			if p.CurrentlyInASchedule && p.IsMachinePowerOn() {
-				p.powerOff()
-				p.CurrentlyInASchedule = false
-				p.LastInteraction = "Scheduled Power Off"
-			}
+	if !canTransition(from, to) {
+		log.Debug("Ignoring power state transition", zap.String("from", string(from)), zap.String("to", string(to)), zap.String("reason", string(reason)))
+		return false
+	}
+
+	if to.PowerOn() {
+		if !from.PowerOn() {
+			p.onSince = at
+			p.lastActivity = at
 		}
+		p.powerRelayPin.High()
 	} else {
-		p.CurrentlyInASchedule = false
+		p.onSince = time.Time{}
+		p.powerRelayPin.Low()
+	}
+	p.state = to
+	p.reason = reason
+	p.since = at
+	t := Transition{From: from, To: to, Reason: reason, At: at}
+	p.history.add(t)
+	p.logTransition(t)
+	p.publishTransition(t)
+	log.Info("Power state changed", zap.String("from", string(from)), zap.String("to", string(to)), zap.String("reason", string(reason)))
+	return true
+}
+
+// logTransition appends the transition to the event log, if there is one. mu
+// must be held.
+func (p *PowerManager) logTransition(t Transition) {
+	if p.events == nil {
+		return
+	}
+	eventType := event_log.TypePowerOff
+	switch {
+	case t.To.PowerOn():
+		if t.From.PowerOn() {
+			// a scheduled window starting while powered on by the user
+			return
+		}
+		eventType = event_log.TypePowerOn
+	case t.To == StateScheduleSuppressed:
+		eventType = event_log.TypeScheduleSuppressed
+	case t.To == StateTotalOff:
+		eventType = event_log.TypeTotalOff
+	case t.Reason == ReasonAutoOff:
+		eventType = event_log.TypeAutoOff
+	case !t.From.PowerOn():
+		// e.g. a fault cleared while powered off
+		return
+	}
+	_, err := p.events.Append(event_log.Event{
+		At:      t.At,
+		Type:    eventType,
+		Cause:   string(t.Reason),
+		Details: map[string]string{"from": string(t.From), "to": string(t.To)},
+	})
+	if err != nil {
+		log.Error("Failed to log power state transition", zap.Error(err))
+	}
+}
+
+// faultBehaviour powers off the machine while the fault source is faulted.
+// mu must be held.
+func (p *PowerManager) faultBehaviour(currentTime time.Time) {
+	if p.faultSource == nil {
+		return
+	}
+	faulted := p.faultSource.Faulted()
+	if faulted && p.state != StateFault {
+		p.transition(StateFault, ReasonFault, currentTime)
+	} else if !faulted && p.state == StateFault {
+		p.transition(StateOff, ReasonFaultCleared, currentTime)
+	}
+}
+
+// autoOffAt returns when the machine powered on by the user will be powered
+// off, zero if it will not be. mu must be held.
+func (p *PowerManager) autoOffAt() time.Time {
+	if p.state != StateManualOn || p.autoOff.Duration <= 0 {
+		return time.Time{}
+	}
+	from := p.onSince
+	if p.autoOff.Idle && p.lastActivity.After(from) {
+		from = p.lastActivity
+	}
+	return from.Add(p.autoOff.Duration)
+}
+
+// autoOffBehaviour powers off the machine when it was powered on by the user,
+// or was last used if idle based, long enough ago, warning the subscribers
+// beforehand. mu must be held.
+func (p *PowerManager) autoOffBehaviour(currentTime time.Time) {
+	offAt := p.autoOffAt()
+	if offAt.IsZero() {
+		return
+	}
+	if !currentTime.Before(offAt) {
+		p.transition(StateOff, ReasonAutoOff, currentTime)
+		return
+	}
+	if p.autoOff.Warning > 0 && !currentTime.Before(offAt.Add(-p.autoOff.Warning)) && !p.warnedOffAt.Equal(offAt) {
+		p.warnedOffAt = offAt
+		p.publishWarning(AutoOffWarning{At: currentTime, OffAt: offAt})
 	}
 }
 
-func (p *PowerManager) powerButtonBehaviour() {
-	if p.isPowerButtonOn() {
-		count := 0
-		for p.isPowerButtonOn() && count < 10 {
-			count++
-			time.Sleep(100 * time.Millisecond)
+// publishWarning sends the warning to all subscribers. mu must be held.
+func (p *PowerManager) publishWarning(w AutoOffWarning) {
+	log.Info("Powering off soon", zap.Time("offAt", w.OffAt))
+	for subId, ch := range p.warningChans {
+		select {
+		case ch <- w:
+		default:
+			log.Debug("Dropped auto-off warning for slow subscriber", zap.Stringer("subId", subId))
 		}
+	}
+}
+
+// SubscribeAutoOffWarnings returns a channel receiving a warning before the
+// machine is powered off automatically
+func (p *PowerManager) SubscribeAutoOffWarnings() (uuid.UUID, chan AutoOffWarning) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	subId := uuid.New()
+	ch := make(chan AutoOffWarning, warningBufferSize)
+	p.warningChans[subId] = ch
+	return subId, ch
+}
 
-		if p.IsMachinePowerOn() {
-			p.powerOff()
-			p.LastInteraction = "Power Button Off"
-			if p.CurrentlyInASchedule {
-				p.StopScheduling = true
+// UnsubscribeAutoOffWarnings stops publishing to the subscription and closes
+// its channel
+func (p *PowerManager) UnsubscribeAutoOffWarnings(subId uuid.UUID) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if ch, ok := p.warningChans[subId]; ok {
+		delete(p.warningChans, subId)
+		close(ch)
+	}
+}
+
+func (p *PowerManager) publishTransition(t Transition) {
+	for subId, ch := range p.transitionChans {
+		select {
+		case ch <- t:
+		default:
+			log.Debug("Dropped power transition for slow subscriber", zap.Stringer("subId", subId))
+		}
+	}
+}
+
+// SubscribeTransitions returns a channel receiving every change of state
+func (p *PowerManager) SubscribeTransitions() (uuid.UUID, chan Transition) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	subId := uuid.New()
+	ch := make(chan Transition, transitionBufferSize)
+	p.transitionChans[subId] = ch
+	return subId, ch
+}
+
+// UnsubscribeTransitions stops publishing to the subscription and closes its
+// channel
+func (p *PowerManager) UnsubscribeTransitions(subId uuid.UUID) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if ch, ok := p.transitionChans[subId]; ok {
+		delete(p.transitionChans, subId)
+		close(ch)
+	}
+}
+
+// RecordActivity restarts the idle based auto-off countdown, e.g. on a brew
+func (p *PowerManager) RecordActivity(reason Reason) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.recordActivity(reason)
+}
+
+// recordActivity restarts the idle based auto-off countdown. mu must be held.
+func (p *PowerManager) recordActivity(reason Reason) {
+	if p.state.PowerOn() {
+		log.Debug("Power activity", zap.String("reason", string(reason)))
+	}
+	p.lastActivity = p.now()
+}
+
+// WatchBrews restarts the idle based auto-off countdown whenever a shot starts
+func (p *PowerManager) WatchBrews(detector *brew.Detector) {
+	subId, brews := detector.Subscribe()
+	go func() {
+		defer detector.Unsubscribe(subId)
+		for {
+			select {
+			case <-p.shutdownCh:
+				return
+			case e, ok := <-brews:
+				if !ok {
+					return
+				}
+				if e.Type == brew.EventStarted {
+					p.RecordActivity(ReasonBrew)
+				}
 			}
-		} else {
-			p.powerOn()
-			p.totalOff = false
-			p.LastInteraction = "Power Button On"
 		}
+	}()
+}
+
+// calendarRefreshBehaviour re-reads the calendar file when it changed
+func (p *PowerManager) calendarRefreshBehaviour(currentTime time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.calendarStore == nil || currentTime.Sub(p.calendarCheckedAt) < p.calendarRefresh {
+		return
+	}
+	p.calendarCheckedAt = currentTime
+
+	modTime := p.calendarStore.ModTime()
+	if modTime.Equal(p.calendarModTime) {
+		return
+	}
+	p.calendarModTime = modTime
+	calendar, err := p.calendarStore.Load(p.location)
+	if err != nil {
+		// keep the last valid calendar until the file changes again
+		log.Error("Failed to reload power schedule calendar", zap.Error(err))
+		return
+	}
+	p.calendar = calendar
+	log.Info("Reloaded power schedule calendar", zap.String("path", p.calendarStore.Path()))
+}
+
+// powerScheduleBehaviour powers the machine on when a window starts and off
+// when it ends. mu must be held.
+func (p *PowerManager) powerScheduleBehaviour(currentTime time.Time) {
+	window, inSchedule := p.inSchedule(currentTime)
+	newWindow := inSchedule && window != p.currentSchedule
+	if inSchedule {
+		p.currentSchedule = window
+	}
 
-		for p.isPowerButtonOn() {
-			time.Sleep(100 * time.Millisecond)
+	switch p.state {
+	case StateOff, StateManualOn:
+		if inSchedule {
+			p.transition(StateScheduledOn, ReasonScheduleStart, currentTime)
+		}
+	case StateScheduledOn:
+		if !inSchedule {
+			p.transition(StateOff, ReasonScheduleEnd, currentTime)
+		}
+	case StateScheduleSuppressed:
+		if newWindow {
+			p.transition(StateScheduledOn, ReasonScheduleStart, currentTime)
+		} else if !inSchedule {
+			p.transition(StateOff, ReasonScheduleEnd, currentTime)
 		}
 	}
 }
 
+// HandleButton runs the power actions of the power button's gestures
+func (p *PowerManager) HandleButton(b *button.Button) {
+	b.Handle(button.ActionTogglePower, func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.recordActivity(ReasonPowerButton)
+		if p.state.PowerOn() {
+			p.powerOff(ReasonPowerButton)
+		} else if err := p.powerOn(ReasonPowerButton); err != nil {
+			log.Info("Ignoring power button", zap.Error(err))
+		}
+	})
+	b.Handle(button.ActionTotalOff, func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.transition(StateTotalOff, ReasonPowerButton, p.now())
+	})
+	b.Handle(button.ActionSuppressSchedule, func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.recordActivity(ReasonPowerButton)
+		if p.state == StateScheduledOn {
+			p.transition(StateScheduleSuppressed, ReasonPowerButton, p.now())
+		}
+	})
+}
+
 func (p *PowerManager) GetStatus() PowerManagerStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 	return PowerManagerStatus{
-		PowerSchedule:        p.PowerSchedule,
-		AutoOffDuration:      p.AutoOffDuration,
-		OnSince:              p.OnSince,
-		CurrentlyInASchedule: p.CurrentlyInASchedule,
-		LastInteraction:      p.LastInteraction,
-		PowerOn:              p.IsMachinePowerOn(),
-		StopScheduling:       p.StopScheduling,
-		TotalOff:             p.totalOff,
+		PowerSchedule:        p.powerSchedule,
+		AutoOffDuration:      p.autoOff.Duration,
+		AutoOffAt:            p.autoOffAt(),
+		State:                p.state,
+		Reason:               p.reason,
+		Since:                p.since,
+		OnSince:              p.onSince,
+		PowerOn:              p.state.PowerOn(),
+		LedPattern:           p.powerLed.Pattern(),
+		CurrentlyInASchedule: p.state == StateScheduledOn,
+		LastInteraction:      string(p.reason),
+		StopScheduling:       p.state == StateScheduleSuppressed,
+		TotalOff:             p.state == StateTotalOff,
 	}
 }
 
-func (p *PowerManager) SetSchedule(newPowerSchedule PowerSchedule) {
-	p.PowerSchedule = newPowerSchedule
+// History returns the last state transitions, oldest first
+func (p *PowerManager) History() []Transition {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.history.list()
 }
 
-func (p *PowerManager) powerOn() {
-	if p.IsMachinePowerOff() {
-		p.powerRelayPin.High()
-		p.OnSince = time.Now()
-		p.LastInteraction = "Power On Call"
+// WatchFaults keeps the machine powered off while the source is faulted
+func (p *PowerManager) WatchFaults(source FaultSource) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.faultSource = source
+}
+
+// WatchTemperature shows on the power LED whether the boiler is at its
+// setpoint, the LED blinking slowly while it heats up
+func (p *PowerManager) WatchTemperature(source TemperatureSource) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.temperatureSource = source
+}
+
+// SetSchedule validates the schedule and replaces the current one with it,
+// saving it to the schedule store if there is one
+func (p *PowerManager) SetSchedule(newPowerSchedule PowerSchedule) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.setSchedule(newPowerSchedule)
+}
+
+// AddInterval adds a power-on interval to the weekly schedule, rejecting it if
+// it overlaps another one
+func (p *PowerManager) AddInterval(day time.Weekday, interval PowerOnInterval) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s := p.powerSchedule
+	s.Frames = copyFrames(s.Frames)
+	s.Frames[day] = append(s.Frames[day], interval)
+	return p.setSchedule(s)
+}
+
+// ErrIntervalNotFound is returned when removing an interval that is not in the
+// weekly schedule
+var ErrIntervalNotFound = errors.New("no such power-on interval in the weekly schedule")
+
+// RemoveInterval removes a power-on interval from the weekly schedule
+func (p *PowerManager) RemoveInterval(day time.Weekday, interval PowerOnInterval) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s := p.powerSchedule
+	s.Frames = copyFrames(s.Frames)
+	for i, existing := range s.Frames[day] {
+		if existing == interval {
+			s.Frames[day] = append(s.Frames[day][:i], s.Frames[day][i+1:]...)
+			if len(s.Frames[day]) == 0 {
+				delete(s.Frames, day)
+			}
+			return p.setSchedule(s)
+		}
 	}
+	return ErrIntervalNotFound
 }
 
-func (p *PowerManager) powerOff() {
-	if p.IsMachinePowerOn() {
-		p.powerRelayPin.Low()
-		p.OnSince = time.Time{}
-		p.LastInteraction = "Power Off Call"
+func copyFrames(frames map[time.Weekday][]PowerOnInterval) map[time.Weekday][]PowerOnInterval {
+	copied := make(map[time.Weekday][]PowerOnInterval, len(frames))
+	for d, intervals := range frames {
+		copied[d] = append([]PowerOnInterval(nil), intervals...)
 	}
+	return copied
 }
 
-func (p *PowerManager) PowerOn() {
-	p.totalOff = false
-	p.powerOn()
+// setSchedule validates and saves the schedule. mu must be held.
+func (p *PowerManager) setSchedule(newPowerSchedule PowerSchedule) error {
+	if err := newPowerSchedule.Validate(); err != nil {
+		return err
+	}
+	location, err := newPowerSchedule.Location()
+	if err != nil {
+		return err
+	}
+	if p.scheduleStore != nil {
+		if err := p.scheduleStore.Save(newPowerSchedule); err != nil {
+			return err
+		}
+	}
+	p.powerSchedule = newPowerSchedule
+	if location.String() != p.location.String() && p.calendarStore != nil {
+		// floating times of the calendar are in the schedule's timezone
+		calendar, err := p.calendarStore.Load(location)
+		if err != nil {
+			log.Error("Failed to reload power schedule calendar", zap.Error(err))
+		} else {
+			p.calendar = calendar
+		}
+	}
+	p.location = location
+	return nil
 }
 
-func (p *PowerManager) PowerOff() {
-	if p.CurrentlyInASchedule {
-		p.StopScheduling = true
+// GetEffectiveSchedule returns the power-on windows of the next days, taking
+// skipped dates, one-off intervals and the calendar into account
+func (p *PowerManager) GetEffectiveSchedule(days int) []Window {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	windows := append(p.powerSchedule.Windows(now, days, p.location), p.calendarWindows(now, now.AddDate(0, 0, days))...)
+	sort.Slice(windows, func(i, j int) bool { return windows[i].Start.Before(windows[j].Start) })
+	return windows
+}
+
+// UseCalendar adds the events of the calendar file to the schedule, checking
+// the file for changes on the refresh interval. An invalid file is ignored
+// until it changes.
+func (p *PowerManager) UseCalendar(store *CalendarStore, refresh time.Duration) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calendarStore = store
+	p.calendarRefresh = refresh
+	p.calendarModTime = store.ModTime()
+	p.calendarCheckedAt = p.now()
+	calendar, err := store.Load(p.location)
+	if err != nil {
+		return err
 	}
-	p.powerOff()
+	p.calendar = calendar
+	return nil
 }
 
-func (p *PowerManager) TotalPowerOff() {
-	if p.CurrentlyInASchedule {
-		p.StopScheduling = true
+// ImportCalendar validates the calendar and replaces the calendar file with it
+func (p *PowerManager) ImportCalendar(data []byte) (*Calendar, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.calendarStore == nil {
+		return nil, errors.New("no calendar file configured")
+	}
+	calendar, err := p.calendarStore.Save(data, p.location)
+	if err != nil {
+		return nil, err
+	}
+	p.calendar = calendar
+	p.calendarModTime = p.calendarStore.ModTime()
+	return calendar, nil
+}
+
+// RemoveCalendar deletes the calendar file, leaving the weekly schedule
+func (p *PowerManager) RemoveCalendar() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.calendarStore == nil {
+		return nil
 	}
-	p.powerOff()
-	p.LastInteraction = "Total Power Off Call"
-	p.totalOff = true
+	if err := p.calendarStore.Remove(); err != nil {
+		return err
+	}
+	p.calendar = nil
+	p.calendarModTime = time.Time{}
+	return nil
 }
 
-func (p *PowerManager) ScheduleOn() {
-	p.StopScheduling = false
+// calendarWindows returns the calendar's windows between from and until that
+// do not start on a skipped date. mu must be held.
+func (p *PowerManager) calendarWindows(from time.Time, until time.Time) []Window {
+	if p.calendar == nil {
+		return nil
+	}
+	var windows []Window
+	for _, w := range p.calendar.Windows(from, until) {
+		if skipped, _ := p.powerSchedule.IsSkipped(DateOf(w.Start.In(p.location))); !skipped {
+			windows = append(windows, w)
+		}
+	}
+	return windows
 }
 
-func (p *PowerManager) ScheduleOff() {
-	p.StopScheduling = true
+// LogEventsTo appends power on and off events to the log from now on
+func (p *PowerManager) LogEventsTo(events *event_log.Log) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = events
 }
 
-func (p *PowerManager) PowerToggle() {
-	if p.IsMachinePowerOn() {
-		p.PowerOff()
-	} else {
-		p.PowerOn()
+// PersistScheduleTo saves schedules set from now on to the store
+func (p *PowerManager) PersistScheduleTo(store *ScheduleStore) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.scheduleStore = store
+}
+
+// powerOn powers on the machine on behalf of the user. mu must be held.
+func (p *PowerManager) powerOn(reason Reason) error {
+	switch p.state {
+	case StateFault:
+		return ErrFault
+	case StateScheduledOn, StateManualOn:
+		return nil
 	}
+	p.transition(StateManualOn, reason, p.now())
+	return nil
 }
 
-func (p *PowerManager) IsMachinePowerOn() bool {
-	return p.powerRelayPin.Read() == rpio.High
+// powerOff powers off the machine on behalf of the user, suppressing the
+// current scheduled window. mu must be held.
+func (p *PowerManager) powerOff(reason Reason) {
+	switch p.state {
+	case StateScheduledOn:
+		p.transition(StateScheduleSuppressed, reason, p.now())
+	case StateManualOn:
+		p.transition(StateOff, reason, p.now())
+	}
 }
 
-func (p *PowerManager) IsMachinePowerOff() bool {
-	return !p.IsMachinePowerOn()
+// PowerOn powers on the machine until auto-off, also after a total power off
+func (p *PowerManager) PowerOn() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.recordActivity(ReasonUser)
+	return p.powerOn(ReasonUser)
 }
 
-func (p *PowerManager) isPowerButtonOn() bool {
-	return p.powerButtonPin.Read() == rpio.High
+// PowerOff powers off the machine, skipping the current scheduled window
+func (p *PowerManager) PowerOff() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.recordActivity(ReasonUser)
+	p.powerOff(ReasonUser)
 }
 
-func (p *PowerManager) inSchedule(currentTime time.Time) (PowerOnInterval, bool) {
+// TotalPowerOff powers off the machine and ignores the schedule until it is
+// powered on again
+func (p *PowerManager) TotalPowerOff() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.transition(StateTotalOff, ReasonUser, p.now())
+}
 
-	hour := currentTime.Hour()
-	v, present := p.PowerSchedule.Frames[currentTime.Weekday()]
+// ScheduleOn resumes a suppressed scheduled window. It fails with ErrNoWindow
+// outside of one.
+func (p *PowerManager) ScheduleOn() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.recordActivity(ReasonUser)
+	now := p.now()
+	if _, inSchedule := p.inSchedule(now); !inSchedule {
+		return ErrNoWindow
+	}
+	if p.state == StateScheduleSuppressed {
+		p.transition(StateScheduledOn, ReasonScheduleResumed, now)
+	}
+	return nil
+}
 
-	if present {
-		for _, powerOnInterval := range v {
-			if hour >= powerOnInterval.From && hour <= powerOnInterval.To {
-				return powerOnInterval, true
-			}
-		}
+// ScheduleOff suppresses the current scheduled window, powering off the
+// machine until it ends. It fails with ErrNoWindow outside of one.
+func (p *PowerManager) ScheduleOff() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.recordActivity(ReasonUser)
+	now := p.now()
+	if _, inSchedule := p.inSchedule(now); !inSchedule {
+		return ErrNoWindow
+	}
+	if p.state == StateScheduledOn {
+		p.transition(StateScheduleSuppressed, ReasonUser, now)
 	}
+	return nil
+}
 
+func (p *PowerManager) PowerToggle() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.recordActivity(ReasonUser)
+	if p.state.PowerOn() {
+		p.powerOff(ReasonUser)
+		return nil
+	}
+	return p.powerOn(ReasonUser)
+}
+
+func (p *PowerManager) IsMachinePowerOn() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.state.PowerOn()
+}
+
+func (p *PowerManager) IsMachinePowerOff() bool {
+	return !p.IsMachinePowerOn()
+}
+
+// inSchedule returns the occurrence of the power-on interval containing the
+// current time, or the last one if there is none. mu must be held.
+func (p *PowerManager) inSchedule(currentTime time.Time) (Window, bool) {
+	if w, ok := p.powerSchedule.at(currentTime, p.location); ok {
+		return w, true
+	}
+	for _, w := range p.calendarWindows(currentTime, currentTime.Add(time.Nanosecond)) {
+		if !currentTime.Before(w.Start) && currentTime.Before(w.End) {
+			return w, true
+		}
+	}
 	return p.currentSchedule, false
 }
 
 func (p *PowerManager) Shutdown() {
+	close(p.shutdownCh)
+	p.powerLed.Shutdown()
+	p.mu.Lock()
+	defer p.mu.Unlock()
 	p.powerRelayPin.Low()
 }
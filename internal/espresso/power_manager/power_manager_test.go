@@ -0,0 +1,559 @@
+package power_manager
+
+import (
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/luiccn/espresso-controller/internal/espresso/button"
+	"github.com/luiccn/espresso-controller/internal/espresso/event_log"
+	"github.com/luiccn/espresso-controller/internal/espresso/gpio"
+	"github.com/luiccn/espresso-controller/internal/espresso/led"
+)
+
+const (
+	testRelayPin  = 16
+	testButtonPin = 17
+	testLedPin    = 27
+)
+
+func newTestPowerManager() (*PowerManager, *gpio.FakeDriver) {
+	driver := gpio.NewFakeDriver()
+	return NewPowerManager(driver, PowerSchedule{}, AutoOffConfig{Duration: time.Hour}, testRelayPin, testLedPin), driver
+}
+
+func TestPowerManager_PowerToggle(t *testing.T) {
+	p, driver := newTestPowerManager()
+	relay := driver.FakePin(testRelayPin)
+
+	if !relay.IsOutput() || relay.Read() != gpio.Low {
+		t.Fatalf("relay should start as a low output")
+	}
+
+	p.PowerToggle()
+	if relay.Read() != gpio.High || !p.IsMachinePowerOn() {
+		t.Errorf("PowerToggle() from off should power on the machine")
+	}
+	if p.GetStatus().OnSince.IsZero() {
+		t.Errorf("PowerToggle() from off should set OnSince")
+	}
+
+	p.PowerToggle()
+	if relay.Read() != gpio.Low || p.IsMachinePowerOn() {
+		t.Errorf("PowerToggle() from on should power off the machine")
+	}
+}
+
+func TestPowerManager_TotalPowerOff(t *testing.T) {
+	p, _ := newTestPowerManager()
+
+	p.PowerOn()
+	p.TotalPowerOff()
+	if status := p.GetStatus(); status.PowerOn || !status.TotalOff {
+		t.Errorf("TotalPowerOff() status = %+v, want powered off and total off", status)
+	}
+
+	p.PowerOn()
+	if status := p.GetStatus(); !status.PowerOn || status.TotalOff {
+		t.Errorf("PowerOn() after TotalPowerOff() status = %+v, want powered on", status)
+	}
+}
+
+type fakeFaultSource struct {
+	mu      sync.Mutex
+	faulted bool
+}
+
+func (f *fakeFaultSource) Faulted() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.faulted
+}
+
+func (f *fakeFaultSource) set(faulted bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.faulted = faulted
+}
+
+func TestPowerManager_transitions(t *testing.T) {
+	lisbon, err := time.LoadLocation("Europe/Lisbon")
+	if err != nil {
+		t.Fatal(err)
+	}
+	schedule := PowerSchedule{
+		Timezone: "Europe/Lisbon",
+		Frames: map[time.Weekday][]PowerOnInterval{
+			time.Monday: {mustInterval(t, "06:00-08:00"), mustInterval(t, "08:00-09:00")},
+		},
+	}
+	// Monday the 19th of October
+	at := func(hour, min int) time.Time { return time.Date(2026, 10, 19, hour, min, 0, 0, lisbon) }
+
+	type step struct {
+		at     time.Time
+		do     func(p *PowerManager, faults *fakeFaultSource)
+		want   State
+		reason Reason
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "scheduled window",
+			steps: []step{
+				{at: at(5, 59), want: StateOff, reason: ReasonStartup},
+				{at: at(6, 0), want: StateScheduledOn, reason: ReasonScheduleStart},
+				{at: at(8, 30), want: StateScheduledOn, reason: ReasonScheduleStart},
+				{at: at(9, 0), want: StateOff, reason: ReasonScheduleEnd},
+			},
+		},
+		{
+			name: "suppressed until the next window",
+			steps: []step{
+				{at: at(6, 0), want: StateScheduledOn, reason: ReasonScheduleStart},
+				{at: at(6, 30), do: func(p *PowerManager, _ *fakeFaultSource) { p.PowerOff() }, want: StateScheduleSuppressed, reason: ReasonUser},
+				{at: at(7, 59), want: StateScheduleSuppressed, reason: ReasonUser},
+				{at: at(8, 0), want: StateScheduledOn, reason: ReasonScheduleStart},
+			},
+		},
+		{
+			name: "suppressed until the window ends",
+			steps: []step{
+				{at: at(8, 0), want: StateScheduledOn, reason: ReasonScheduleStart},
+				{at: at(8, 30), do: func(p *PowerManager, _ *fakeFaultSource) { p.ScheduleOff() }, want: StateScheduleSuppressed, reason: ReasonUser},
+				{at: at(9, 0), want: StateOff, reason: ReasonScheduleEnd},
+			},
+		},
+		{
+			name: "suppressed and resumed",
+			steps: []step{
+				{at: at(8, 0), want: StateScheduledOn, reason: ReasonScheduleStart},
+				{at: at(8, 30), do: func(p *PowerManager, _ *fakeFaultSource) { p.ScheduleOff() }, want: StateScheduleSuppressed, reason: ReasonUser},
+				{at: at(8, 40), do: func(p *PowerManager, _ *fakeFaultSource) { p.ScheduleOn() }, want: StateScheduledOn, reason: ReasonScheduleResumed},
+			},
+		},
+		{
+			name: "manual on until auto-off",
+			steps: []step{
+				{at: at(10, 0), do: func(p *PowerManager, _ *fakeFaultSource) { p.PowerOn() }, want: StateManualOn, reason: ReasonUser},
+				{at: at(20, 0), want: StateOff, reason: ReasonAutoOff},
+			},
+		},
+		{
+			name: "total off ignores the schedule",
+			steps: []step{
+				{at: at(5, 0), do: func(p *PowerManager, _ *fakeFaultSource) { p.TotalPowerOff() }, want: StateTotalOff, reason: ReasonUser},
+				{at: at(6, 0), want: StateTotalOff, reason: ReasonUser},
+				{at: at(6, 30), do: func(p *PowerManager, _ *fakeFaultSource) { p.PowerOn() }, want: StateScheduledOn, reason: ReasonScheduleStart},
+			},
+		},
+		{
+			name: "fault",
+			steps: []step{
+				{at: at(6, 0), want: StateScheduledOn, reason: ReasonScheduleStart},
+				{at: at(6, 1), do: func(_ *PowerManager, f *fakeFaultSource) { f.set(true) }, want: StateFault, reason: ReasonFault},
+				{at: at(6, 2), do: func(p *PowerManager, _ *fakeFaultSource) { p.PowerOn(); p.TotalPowerOff() }, want: StateFault, reason: ReasonFault},
+				{at: at(6, 3), do: func(_ *PowerManager, f *fakeFaultSource) { f.set(false) }, want: StateScheduledOn, reason: ReasonScheduleStart},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, driver := newTestPowerManager()
+			relay := driver.FakePin(testRelayPin)
+			faults := &fakeFaultSource{}
+			p.WatchFaults(faults)
+			if err := p.SetSchedule(schedule); err != nil {
+				t.Fatal(err)
+			}
+
+			for i, s := range tt.steps {
+				p.now = func() time.Time { return s.at }
+				if s.do != nil {
+					s.do(p, faults)
+				}
+				p.mu.Lock()
+				p.faultBehaviour(s.at)
+				p.powerScheduleBehaviour(s.at)
+				p.autoOffBehaviour(s.at)
+				p.mu.Unlock()
+
+				status := p.GetStatus()
+				if status.State != s.want || status.Reason != s.reason {
+					t.Fatalf("step %d: state = %s (%s), want %s (%s)", i, status.State, status.Reason, s.want, s.reason)
+				}
+				if relayOn := relay.Read() == gpio.High; relayOn != s.want.PowerOn() {
+					t.Errorf("step %d: relay on = %v in state %s", i, relayOn, s.want)
+				}
+			}
+		})
+	}
+}
+
+func TestPowerManager_PowerOn_fault(t *testing.T) {
+	p, _ := newTestPowerManager()
+	faults := &fakeFaultSource{faulted: true}
+	p.WatchFaults(faults)
+	p.mu.Lock()
+	p.faultBehaviour(time.Now())
+	p.mu.Unlock()
+
+	if err := p.PowerOn(); err != ErrFault {
+		t.Errorf("PowerOn() in a fault error = %v, want %v", err, ErrFault)
+	}
+	if err := p.PowerToggle(); err != ErrFault {
+		t.Errorf("PowerToggle() in a fault error = %v, want %v", err, ErrFault)
+	}
+	if p.IsMachinePowerOn() {
+		t.Errorf("machine should stay off in a fault")
+	}
+}
+
+func TestPowerManager_Schedule_noWindow(t *testing.T) {
+	p, _ := newTestPowerManager()
+	if err := p.ScheduleOff(); err != ErrNoWindow {
+		t.Errorf("ScheduleOff() outside a window error = %v, want %v", err, ErrNoWindow)
+	}
+	if err := p.ScheduleOn(); err != ErrNoWindow {
+		t.Errorf("ScheduleOn() outside a window error = %v, want %v", err, ErrNoWindow)
+	}
+	p.PowerOn()
+	if err := p.ScheduleOff(); err != ErrNoWindow {
+		t.Errorf("ScheduleOff() while manually on error = %v, want %v", err, ErrNoWindow)
+	}
+	if status := p.GetStatus(); status.State != StateManualOn {
+		t.Errorf("state = %s, want %s", status.State, StateManualOn)
+	}
+}
+
+func TestPowerManager_History(t *testing.T) {
+	p, _ := newTestPowerManager()
+	for i := 0; i < maxHistory; i++ {
+		p.PowerToggle()
+	}
+	p.TotalPowerOff()
+
+	history := p.History()
+	if len(history) != maxHistory {
+		t.Fatalf("len(History()) = %d, want %d", len(history), maxHistory)
+	}
+	want := Transition{From: StateOff, To: StateTotalOff, Reason: ReasonUser}
+	if last := history[len(history)-1]; last.From != want.From || last.To != want.To || last.Reason != want.Reason {
+		t.Errorf("last transition = %v, want %v", last, want)
+	}
+	for i := 1; i < len(history); i++ {
+		if history[i].From != history[i-1].To {
+			t.Fatalf("transition %d = %v does not follow %v", i, history[i], history[i-1])
+		}
+	}
+}
+
+func TestPowerManager_HandleButton(t *testing.T) {
+	p, driver := newTestPowerManager()
+	b, err := button.NewButton(button.Config{
+		ShortPress:        button.ActionTogglePower,
+		LongPress:         button.ActionTotalOff,
+		DoublePress:       button.ActionNone,
+		LongPressDuration: 200 * time.Millisecond,
+		DoublePressWindow: 200 * time.Millisecond,
+	}, driver, testButtonPin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.HandleButton(b)
+	b.Run()
+	defer b.Shutdown()
+
+	press := func(held time.Duration, want State) {
+		t.Helper()
+		pin := driver.FakePin(testButtonPin)
+		pin.Set(gpio.High)
+		time.Sleep(held)
+		pin.Set(gpio.Low)
+		deadline := time.Now().Add(time.Second)
+		for p.GetStatus().State != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("state after a %s press = %s, want %s", held, p.GetStatus().State, want)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		if reason := p.GetStatus().Reason; reason != ReasonPowerButton {
+			t.Errorf("reason = %s, want %s", reason, ReasonPowerButton)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	press(80*time.Millisecond, StateManualOn)
+	press(400*time.Millisecond, StateTotalOff)
+	press(80*time.Millisecond, StateManualOn)
+}
+
+func TestPowerManager_AddInterval(t *testing.T) {
+	p, _ := newTestPowerManager()
+	morning := mustInterval(t, "06:00-08:00")
+
+	if err := p.AddInterval(time.Monday, morning); err != nil {
+		t.Fatalf("AddInterval() error = %v", err)
+	}
+	if err := p.AddInterval(time.Monday, mustInterval(t, "07:00-09:00")); err == nil {
+		t.Errorf("AddInterval() with an overlapping interval should fail")
+	}
+	if got, want := p.GetStatus().PowerSchedule.Frames, map[time.Weekday][]PowerOnInterval{time.Monday: {morning}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Frames = %v, want %v", got, want)
+	}
+
+	if err := p.RemoveInterval(time.Tuesday, morning); err != ErrIntervalNotFound {
+		t.Errorf("RemoveInterval() of a missing interval error = %v, want %v", err, ErrIntervalNotFound)
+	}
+	if err := p.RemoveInterval(time.Monday, morning); err != nil {
+		t.Fatalf("RemoveInterval() error = %v", err)
+	}
+	if got := p.GetStatus().PowerSchedule.Frames; len(got) != 0 {
+		t.Errorf("Frames after RemoveInterval() = %v, want none", got)
+	}
+}
+
+func TestPowerManager_SubscribeTransitions(t *testing.T) {
+	p, _ := newTestPowerManager()
+	subId, transitions := p.SubscribeTransitions()
+
+	p.PowerOn()
+	p.TotalPowerOff()
+	p.UnsubscribeTransitions(subId)
+
+	var got []State
+	for tr := range transitions {
+		got = append(got, tr.To)
+	}
+	if want := []State{StateManualOn, StateTotalOff}; !reflect.DeepEqual(got, want) {
+		t.Errorf("transitions to %v, want %v", got, want)
+	}
+}
+
+type fakeTemperatureSource bool
+
+func (f fakeTemperatureSource) AtTemperature() bool {
+	return bool(f)
+}
+
+func TestPowerManager_ledBehaviour(t *testing.T) {
+	tests := []struct {
+		name          string
+		do            func(p *PowerManager)
+		atTemperature bool
+		want          led.Pattern
+	}{
+		{name: "off", do: func(p *PowerManager) {}, want: led.PatternOff},
+		{name: "heating up", do: func(p *PowerManager) { p.PowerOn() }, want: led.PatternSlowBlink},
+		{name: "at temperature", do: func(p *PowerManager) { p.PowerOn() }, atTemperature: true, want: led.PatternSolid},
+		{name: "total off", do: func(p *PowerManager) { p.TotalPowerOff() }, atTemperature: true, want: led.PatternOff},
+		{
+			name: "schedule suppressed",
+			do: func(p *PowerManager) {
+				p.transition(StateScheduledOn, ReasonScheduleStart, p.now())
+				p.transition(StateScheduleSuppressed, ReasonUser, p.now())
+			},
+			want: led.PatternDoubleBlink,
+		},
+		{
+			name: "fault",
+			do: func(p *PowerManager) {
+				p.PowerOn()
+				p.WatchFaults(&fakeFaultSource{faulted: true})
+				p.faultBehaviour(p.now())
+			},
+			atTemperature: true,
+			want:          led.PatternFastBlink,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, driver := newTestPowerManager()
+			p.WatchTemperature(fakeTemperatureSource(tt.atTemperature))
+			tt.do(p)
+			p.ledBehaviour()
+			if got := p.GetStatus().LedPattern; got != tt.want {
+				t.Errorf("LedPattern = %s, want %s", got, tt.want)
+			}
+			// every pattern but off starts lit
+			if got, want := driver.FakePin(testLedPin).Read() == gpio.High, tt.want != led.PatternOff; got != want {
+				t.Errorf("LED lit = %t, want %t", got, want)
+			}
+		})
+	}
+}
+
+// TestPowerManager_concurrent is meant to be run with -race
+func TestPowerManager_concurrent(t *testing.T) {
+	p, driver := newTestPowerManager()
+	p.WatchFaults(&fakeFaultSource{})
+	p.Run()
+	defer p.Shutdown()
+	b, err := button.NewButton(button.Config{
+		ShortPress:        button.ActionTogglePower,
+		LongPress:         button.ActionTotalOff,
+		DoublePress:       button.ActionSuppressSchedule,
+		LongPressDuration: time.Second,
+		DoublePressWindow: 100 * time.Millisecond,
+	}, driver, testButtonPin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.HandleButton(b)
+	b.Run()
+	defer b.Shutdown()
+
+	var wg sync.WaitGroup
+	calls := []func(){
+		func() { p.PowerOn() },
+		func() { p.PowerOff() },
+		func() { p.PowerToggle() },
+		func() { p.TotalPowerOff() },
+		func() { p.ScheduleOn() },
+		func() { p.ScheduleOff() },
+		func() { p.GetStatus() },
+		func() { p.History() },
+		func() { p.IsMachinePowerOn() },
+		func() { p.GetEffectiveSchedule(1) },
+		func() { p.SetSchedule(DefaultPowerSchedule()) },
+	}
+	for _, call := range calls {
+		wg.Add(1)
+		go func(call func()) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				call()
+			}
+		}(call)
+	}
+	// a short press while the calls are made
+	driver.FakePin(testButtonPin).Set(gpio.High)
+	time.Sleep(300 * time.Millisecond)
+	driver.FakePin(testButtonPin).Set(gpio.Low)
+	wg.Wait()
+}
+
+func TestPowerManager_LogEventsTo(t *testing.T) {
+	events, err := event_log.Open(event_log.Config{Path: filepath.Join(t.TempDir(), "events.jsonl")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer events.Close()
+
+	p, _ := newTestPowerManager()
+	p.LogEventsTo(events)
+	p.PowerOn()
+	p.PowerOff()
+	p.PowerOn()
+	p.TotalPowerOff()
+
+	page, err := events.Query(event_log.Query{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []event_log.Type{event_log.TypeTotalOff, event_log.TypePowerOn, event_log.TypePowerOff, event_log.TypePowerOn}
+	var got []event_log.Type
+	for _, e := range page.Events {
+		got = append(got, e.Type)
+		if e.Cause != string(ReasonUser) {
+			t.Errorf("event %+v cause = %q, want %q", e, e.Cause, ReasonUser)
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("logged events = %v, want %v", got, want)
+	}
+}
+
+func TestPowerManager_autoOffBehaviour(t *testing.T) {
+	start := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		autoOff  AutoOffConfig
+		activity time.Duration
+		at       time.Duration
+		wantOn   bool
+		wantWarn bool
+	}{
+		{name: "before auto-off", autoOff: AutoOffConfig{Duration: time.Hour}, at: 59 * time.Minute, wantOn: true},
+		{name: "auto-off", autoOff: AutoOffConfig{Duration: time.Hour}, at: time.Hour},
+		{name: "never", autoOff: AutoOffConfig{}, at: 24 * time.Hour, wantOn: true},
+		{name: "activity ignored", autoOff: AutoOffConfig{Duration: time.Hour}, activity: 50 * time.Minute, at: time.Hour},
+		{name: "idle", autoOff: AutoOffConfig{Duration: time.Hour, Idle: true}, activity: 50 * time.Minute, at: 100 * time.Minute, wantOn: true},
+		{name: "idle auto-off", autoOff: AutoOffConfig{Duration: time.Hour, Idle: true}, activity: 50 * time.Minute, at: 110 * time.Minute},
+		{name: "warning", autoOff: AutoOffConfig{Duration: time.Hour, Warning: 5 * time.Minute}, at: 55 * time.Minute, wantOn: true, wantWarn: true},
+		{name: "before warning", autoOff: AutoOffConfig{Duration: time.Hour, Warning: 5 * time.Minute}, at: 54 * time.Minute, wantOn: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := newTestPowerManager()
+			p.autoOff = tt.autoOff
+			subId, warnings := p.SubscribeAutoOffWarnings()
+			defer p.UnsubscribeAutoOffWarnings(subId)
+
+			p.now = func() time.Time { return start }
+			p.PowerOn()
+			if tt.activity > 0 {
+				p.now = func() time.Time { return start.Add(tt.activity) }
+				p.RecordActivity(ReasonBrew)
+			}
+
+			p.mu.Lock()
+			p.autoOffBehaviour(start.Add(tt.at))
+			p.mu.Unlock()
+
+			if on := p.IsMachinePowerOn(); on != tt.wantOn {
+				t.Errorf("powered on = %v, want %v", on, tt.wantOn)
+			}
+			select {
+			case w := <-warnings:
+				if !tt.wantWarn {
+					t.Errorf("unexpected warning %+v", w)
+				} else if want := start.Add(tt.autoOff.Duration); !w.OffAt.Equal(want) {
+					t.Errorf("warning off at %v, want %v", w.OffAt, want)
+				}
+			default:
+				if tt.wantWarn {
+					t.Errorf("no warning")
+				}
+			}
+		})
+	}
+}
+
+func TestPowerManager_autoOffBehaviour_warnsOnce(t *testing.T) {
+	p, _ := newTestPowerManager()
+	p.autoOff = AutoOffConfig{Duration: time.Hour, Idle: true, Warning: 5 * time.Minute}
+	subId, warnings := p.SubscribeAutoOffWarnings()
+	defer p.UnsubscribeAutoOffWarnings(subId)
+
+	start := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	p.now = func() time.Time { return start }
+	p.PowerOn()
+	tick := func(at time.Duration) {
+		p.mu.Lock()
+		p.autoOffBehaviour(start.Add(at))
+		p.mu.Unlock()
+	}
+
+	tick(56 * time.Minute)
+	tick(57 * time.Minute)
+	// a brew postpones auto-off, warning again before the new time
+	p.now = func() time.Time { return start.Add(58 * time.Minute) }
+	p.RecordActivity(ReasonBrew)
+	tick(59 * time.Minute)
+	tick(113 * time.Minute)
+
+	if got := len(warnings); got != 2 {
+		t.Errorf("got %d warnings, want 2", got)
+	}
+	if status := p.GetStatus(); !status.AutoOffAt.Equal(start.Add(118 * time.Minute)) {
+		t.Errorf("AutoOffAt = %v, want %v", status.AutoOffAt, start.Add(118*time.Minute))
+	}
+}
@@ -0,0 +1,399 @@
+package power_manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	// the pi's image may lack the timezone database
+	_ "time/tzdata"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	minutesPerDay  = 24 * 60
+	minutesPerWeek = 7 * minutesPerDay
+)
+
+var weekdays = []time.Weekday{time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday}
+
+// TimeOfDay is a wall clock time in minutes since midnight, 24:00 being the
+// end of the day
+type TimeOfDay int
+
+// ParseTimeOfDay parses a 24 hour HH:MM time
+func ParseTimeOfDay(s string) (TimeOfDay, error) {
+	if len(s) != 5 || s[2] != ':' || !isDigits(s[:2]) || !isDigits(s[3:]) {
+		return 0, fmt.Errorf("invalid time of day %q, expected HH:MM", s)
+	}
+	hour, _ := strconv.Atoi(s[:2])
+	minute, _ := strconv.Atoi(s[3:])
+	if minute > 59 || hour > 24 || (hour == 24 && minute != 0) {
+		return 0, fmt.Errorf("time of day %q must be within [00:00, 24:00]", s)
+	}
+	return TimeOfDay(hour*60 + minute), nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func (t TimeOfDay) String() string {
+	return fmt.Sprintf("%02d:%02d", int(t)/60, int(t)%60)
+}
+
+// PowerOnInterval is the half-open interval [From, To) of wall clock time.
+// An interval whose To is before its From crosses midnight and ends on the
+// next day.
+type PowerOnInterval struct {
+	From TimeOfDay
+	To   TimeOfDay
+}
+
+// ParsePowerOnInterval parses an HH:MM-HH:MM interval
+func ParsePowerOnInterval(s string) (PowerOnInterval, error) {
+	parts := strings.Split(s, "-")
+	if len(parts) != 2 {
+		return PowerOnInterval{}, fmt.Errorf("invalid interval %q, expected HH:MM-HH:MM", s)
+	}
+	from, err := ParseTimeOfDay(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return PowerOnInterval{}, err
+	}
+	to, err := ParseTimeOfDay(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return PowerOnInterval{}, err
+	}
+	return PowerOnInterval{From: from, To: to}, nil
+}
+
+func (i PowerOnInterval) String() string {
+	return i.From.String() + "-" + i.To.String()
+}
+
+// CrossesMidnight is true if the interval ends on the next day
+func (i PowerOnInterval) CrossesMidnight() bool {
+	return i.To < i.From
+}
+
+func (i PowerOnInterval) validate() error {
+	if i.From < 0 || i.From >= minutesPerDay || i.To < 0 || i.To > minutesPerDay {
+		return fmt.Errorf("interval %s must be within [00:00, 24:00]", i)
+	}
+	if i.From == i.To {
+		return fmt.Errorf("interval %s is empty", i)
+	}
+	return nil
+}
+
+// duration of the interval in minutes
+func (i PowerOnInterval) duration() int {
+	if i.CrossesMidnight() {
+		return minutesPerDay - int(i.From) + int(i.To)
+	}
+	return int(i.To - i.From)
+}
+
+type PowerSchedule struct {
+	// IANA name of the timezone the intervals are in, e.g. Europe/Lisbon. The
+	// system's timezone is used if empty.
+	Timezone string
+	// intervals by the weekday they start on
+	Frames map[time.Weekday][]PowerOnInterval
+	// dates on which the weekly intervals are skipped
+	Skips []DateRange
+	// intervals in addition to the weekly ones
+	Extras []OneOffInterval
+	// the weekly intervals are skipped before this date, if set
+	PausedUntil Date
+}
+
+// PowerScheduleConfig is the schedule as found in the config and schedule
+// files, e.g.
+//
+//	Timezone: Europe/Lisbon
+//	Frames:
+//	  Monday: ["06:45-07:30", "23:00-01:00"]
+//	Skips:
+//	  - {From: "2026-12-24", To: "2026-12-26", Reason: Christmas}
+//	Extras:
+//	  - {Date: "2026-10-17", Interval: "05:30-07:00"}
+//	PausedUntil: "2026-11-01"
+type PowerScheduleConfig struct {
+	Timezone    string
+	Frames      map[string][]string
+	Skips       []DateRangeConfig      `json:",omitempty"`
+	Extras      []OneOffIntervalConfig `json:",omitempty"`
+	PausedUntil string                 `json:",omitempty"`
+}
+
+// ParseWeekday parses the english name of a weekday, ignoring case
+func ParseWeekday(name string) (time.Weekday, error) {
+	for _, d := range weekdays {
+		if strings.EqualFold(name, d.String()) {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown weekday %q", name)
+}
+
+// DefaultPowerSchedule is used when neither the config nor the schedule file
+// define a schedule
+func DefaultPowerSchedule() PowerSchedule {
+	frames := make(map[time.Weekday][]PowerOnInterval)
+	for _, d := range weekdays {
+		if d == time.Saturday || d == time.Sunday {
+			frames[d] = []PowerOnInterval{{From: 7 * 60, To: 9 * 60}, {From: 11 * 60, To: 13 * 60}, {From: 14 * 60, To: 15 * 60}}
+		} else {
+			frames[d] = []PowerOnInterval{{From: 6 * 60, To: 8 * 60}, {From: 11 * 60, To: 13 * 60}, {From: 14 * 60, To: 15 * 60}}
+		}
+	}
+	return PowerSchedule{Frames: frames}
+}
+
+// NewPowerSchedule parses and validates the schedule of the config
+func NewPowerSchedule(c PowerScheduleConfig) (PowerSchedule, error) {
+	frames := make(map[time.Weekday][]PowerOnInterval)
+	for name, intervals := range c.Frames {
+		d, err := ParseWeekday(name)
+		if err != nil {
+			return PowerSchedule{}, err
+		}
+		for _, s := range intervals {
+			interval, err := ParsePowerOnInterval(s)
+			if err != nil {
+				return PowerSchedule{}, errors.Wrap(err, d.String())
+			}
+			frames[d] = append(frames[d], interval)
+		}
+	}
+	s := PowerSchedule{Timezone: c.Timezone, Frames: frames}
+	for _, skipConfig := range c.Skips {
+		skip, err := newDateRange(skipConfig)
+		if err != nil {
+			return PowerSchedule{}, errors.Wrap(err, "skipped dates")
+		}
+		s.Skips = append(s.Skips, skip)
+	}
+	for _, extraConfig := range c.Extras {
+		extra, err := newOneOffInterval(extraConfig)
+		if err != nil {
+			return PowerSchedule{}, errors.Wrap(err, "one-off interval")
+		}
+		s.Extras = append(s.Extras, extra)
+	}
+	if c.PausedUntil != "" {
+		pausedUntil, err := ParseDate(c.PausedUntil)
+		if err != nil {
+			return PowerSchedule{}, errors.Wrap(err, "paused until")
+		}
+		s.PausedUntil = pausedUntil
+	}
+	if err := s.Validate(); err != nil {
+		return PowerSchedule{}, err
+	}
+	return s, nil
+}
+
+// Config returns the schedule in the form of the config
+func (s PowerSchedule) Config() PowerScheduleConfig {
+	frames := make(map[string][]string, len(s.Frames))
+	for d, intervals := range s.Frames {
+		for _, interval := range intervals {
+			frames[d.String()] = append(frames[d.String()], interval.String())
+		}
+	}
+	c := PowerScheduleConfig{Timezone: s.Timezone, Frames: frames, PausedUntil: s.PausedUntil.String()}
+	for _, skip := range s.Skips {
+		c.Skips = append(c.Skips, DateRangeConfig{From: skip.From.String(), To: skip.To.String(), Reason: skip.Reason})
+	}
+	for _, extra := range s.Extras {
+		c.Extras = append(c.Extras, OneOffIntervalConfig{Date: extra.Date.String(), Interval: extra.Interval.String(), Reason: extra.Reason})
+	}
+	return c
+}
+
+// Location loads the timezone of the schedule
+func (s PowerSchedule) Location() (*time.Location, error) {
+	if s.Timezone == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid timezone %q", s.Timezone)
+	}
+	return loc, nil
+}
+
+// weekSpan is a half-open range of minutes since the start of the week
+type weekSpan struct {
+	start, end int
+	day        time.Weekday
+	interval   PowerOnInterval
+}
+
+// Validate checks the timezone, that intervals are within a day and not empty,
+// and that no two weekly intervals overlap, including intervals crossing
+// midnight into the next day or into the next week. See validateExceptions for
+// the exceptions.
+func (s PowerSchedule) Validate() error {
+	if _, err := s.Location(); err != nil {
+		return err
+	}
+
+	var spans []weekSpan
+	for d, intervals := range s.Frames {
+		if d < time.Sunday || d > time.Saturday {
+			return fmt.Errorf("invalid weekday %d", d)
+		}
+		for _, interval := range intervals {
+			if err := interval.validate(); err != nil {
+				return errors.Wrap(err, d.String())
+			}
+			start := int(d)*minutesPerDay + int(interval.From)
+			end := start + interval.duration()
+			if end > minutesPerWeek {
+				// Saturday night into Sunday morning
+				spans = append(spans, weekSpan{start: start, end: minutesPerWeek, day: d, interval: interval})
+				spans = append(spans, weekSpan{start: 0, end: end - minutesPerWeek, day: d, interval: interval})
+			} else {
+				spans = append(spans, weekSpan{start: start, end: end, day: d, interval: interval})
+			}
+		}
+	}
+
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	for i := 1; i < len(spans); i++ {
+		if prev, cur := spans[i-1], spans[i]; cur.start < prev.end {
+			return fmt.Errorf("%s interval %s and %s interval %s overlap", prev.day, prev.interval, cur.day, cur.interval)
+		}
+	}
+	return s.validateExceptions()
+}
+
+// Window is an occurrence of a power-on interval
+type Window struct {
+	Start time.Time
+	End   time.Time
+	// whether the window comes from a one-off interval rather than a weekly
+	// one
+	OneOff bool
+	Reason string
+}
+
+// at returns the occurrence of the schedule's intervals containing t, in the
+// timezone loc. Intervals are matched on wall clock time, so an interval
+// starting in the hour skipped when DST begins starts when the clocks have
+// gone forward.
+func (s PowerSchedule) at(t time.Time, loc *time.Location) (Window, bool) {
+	t = t.In(loc)
+	minute := TimeOfDay(t.Hour()*60 + t.Minute())
+	today := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	yesterday := today.AddDate(0, 0, -1)
+
+	for _, si := range s.intervalsOn(today) {
+		if minute >= si.interval.From && (si.interval.CrossesMidnight() || minute < si.interval.To) {
+			return si.window(today), true
+		}
+	}
+	for _, si := range s.intervalsOn(yesterday) {
+		if si.interval.CrossesMidnight() && minute < si.interval.To {
+			return si.window(yesterday), true
+		}
+	}
+	return Window{}, false
+}
+
+// occurrence of the interval starting on day
+func occurrence(day time.Time, interval PowerOnInterval) Window {
+	endDay := day
+	if interval.CrossesMidnight() {
+		endDay = day.AddDate(0, 0, 1)
+	}
+	return Window{Start: interval.From.on(day), End: interval.To.on(endDay)}
+}
+
+// on returns the time of day on the day in its timezone
+func (t TimeOfDay) on(day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), int(t)/60, int(t)%60, 0, 0, day.Location())
+}
+
+// MarshalJSON encodes the schedule as its config
+func (s PowerSchedule) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Config())
+}
+
+func (s *PowerSchedule) UnmarshalJSON(data []byte) error {
+	var c PowerScheduleConfig
+	if err := json.Unmarshal(data, &c); err != nil {
+		return err
+	}
+	schedule, err := NewPowerSchedule(c)
+	if err != nil {
+		return err
+	}
+	*s = schedule
+	return nil
+}
+
+// ScheduleStore persists the power schedule as json so changes made at
+// runtime survive restarts
+type ScheduleStore struct {
+	path string
+}
+
+func NewScheduleStore(path string) *ScheduleStore {
+	return &ScheduleStore{path: path}
+}
+
+func (s *ScheduleStore) Path() string {
+	return s.path
+}
+
+// Load reads the stored schedule, returning false if none was stored yet
+func (s *ScheduleStore) Load() (PowerSchedule, bool, error) {
+	data, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return PowerSchedule{}, false, nil
+	}
+	if err != nil {
+		return PowerSchedule{}, false, errors.Wrap(err, "reading power schedule")
+	}
+
+	var schedule PowerSchedule
+	if err := json.Unmarshal(data, &schedule); err != nil {
+		return PowerSchedule{}, false, errors.Wrapf(err, "invalid power schedule %s", s.path)
+	}
+	return schedule, true, nil
+}
+
+// Save writes the schedule to a temporary file first, so a crash while saving
+// does not leave a truncated schedule behind
+func (s *ScheduleStore) Save(schedule PowerSchedule) error {
+	data, err := json.MarshalIndent(schedule, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return errors.Wrap(err, "creating power schedule directory")
+	}
+	tmp := s.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return errors.Wrap(err, "writing power schedule")
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return errors.Wrap(err, "writing power schedule")
+	}
+	return nil
+}
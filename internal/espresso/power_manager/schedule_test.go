@@ -0,0 +1,245 @@
+package power_manager
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func mustInterval(t *testing.T, s string) PowerOnInterval {
+	t.Helper()
+	interval, err := ParsePowerOnInterval(s)
+	if err != nil {
+		t.Fatalf("ParsePowerOnInterval(%q) error = %v", s, err)
+	}
+	return interval
+}
+
+func TestParseTimeOfDay(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    TimeOfDay
+		wantErr bool
+	}{
+		{s: "00:00", want: 0},
+		{s: "06:45", want: 6*60 + 45},
+		{s: "24:00", want: 24 * 60},
+		{s: "6:45", wantErr: true},
+		{s: "06:60", wantErr: true},
+		{s: "24:01", wantErr: true},
+		{s: "+6:45", wantErr: true},
+		{s: "0645", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := ParseTimeOfDay(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTimeOfDay() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("ParseTimeOfDay() = %v, want %v", got, tt.want)
+			}
+			if err == nil && got.String() != tt.s {
+				t.Errorf("String() = %v, want %v", got.String(), tt.s)
+			}
+		})
+	}
+}
+
+func TestPowerSchedule_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		timezone string
+		frames   map[time.Weekday][]string
+		wantErr  bool
+	}{
+		{name: "empty"},
+		{name: "disjoint", frames: map[time.Weekday][]string{time.Monday: {"11:00-13:00", "06:45-07:30"}}},
+		{name: "adjacent", frames: map[time.Weekday][]string{time.Monday: {"06:00-08:00", "08:00-09:00"}}},
+		{name: "until the end of the day", frames: map[time.Weekday][]string{time.Monday: {"23:00-24:00"}}},
+		{name: "crossing midnight", frames: map[time.Weekday][]string{time.Monday: {"23:00-01:00"}, time.Tuesday: {"01:00-02:00"}}},
+		{name: "crossing into sunday", frames: map[time.Weekday][]string{time.Saturday: {"23:00-01:00"}, time.Sunday: {"01:00-02:00"}}},
+		{name: "timezone", timezone: "Europe/Lisbon", frames: map[time.Weekday][]string{time.Monday: {"06:00-08:00"}}},
+		{name: "overlapping", frames: map[time.Weekday][]string{time.Monday: {"06:00-08:00", "07:59-09:00"}}, wantErr: true},
+		{name: "empty interval", frames: map[time.Weekday][]string{time.Monday: {"06:00-06:00"}}, wantErr: true},
+		{name: "crossing midnight overlapping", frames: map[time.Weekday][]string{time.Monday: {"23:00-01:00"}, time.Tuesday: {"00:30-02:00"}}, wantErr: true},
+		{name: "crossing into sunday overlapping", frames: map[time.Weekday][]string{time.Saturday: {"23:00-01:00"}, time.Sunday: {"00:00-00:30"}}, wantErr: true},
+		{name: "unknown timezone", timezone: "Mars/Olympus_Mons", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := PowerSchedule{Timezone: tt.timezone, Frames: map[time.Weekday][]PowerOnInterval{}}
+			for d, intervals := range tt.frames {
+				for _, interval := range intervals {
+					s.Frames[d] = append(s.Frames[d], mustInterval(t, interval))
+				}
+			}
+			if err := s.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPowerSchedule_at(t *testing.T) {
+	lisbon, err := time.LoadLocation("Europe/Lisbon")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := PowerSchedule{
+		Timezone: "Europe/Lisbon",
+		Frames: map[time.Weekday][]PowerOnInterval{
+			time.Sunday:   {mustInterval(t, "01:30-02:30")},
+			time.Monday:   {mustInterval(t, "06:45-07:30")},
+			time.Saturday: {mustInterval(t, "23:00-00:30")},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		at        time.Time
+		wantOk    bool
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{
+			name:      "start included",
+			at:        time.Date(2026, 10, 19, 6, 45, 0, 0, lisbon),
+			wantOk:    true,
+			wantStart: time.Date(2026, 10, 19, 6, 45, 0, 0, lisbon),
+			wantEnd:   time.Date(2026, 10, 19, 7, 30, 0, 0, lisbon),
+		},
+		{
+			name: "end excluded",
+			at:   time.Date(2026, 10, 19, 7, 30, 0, 0, lisbon),
+		},
+		{
+			name: "before start",
+			at:   time.Date(2026, 10, 19, 6, 44, 59, 0, lisbon),
+		},
+		{
+			name:      "in another timezone",
+			at:        time.Date(2026, 10, 19, 8, 0, 0, 0, time.FixedZone("CEST", 2*60*60)),
+			wantOk:    true,
+			wantStart: time.Date(2026, 10, 19, 6, 45, 0, 0, lisbon),
+			wantEnd:   time.Date(2026, 10, 19, 7, 30, 0, 0, lisbon),
+		},
+		{
+			name:      "after midnight",
+			at:        time.Date(2026, 10, 18, 0, 15, 0, 0, lisbon),
+			wantOk:    true,
+			wantStart: time.Date(2026, 10, 17, 23, 0, 0, 0, lisbon),
+			wantEnd:   time.Date(2026, 10, 18, 0, 30, 0, 0, lisbon),
+		},
+		{
+			// clocks go forward from 01:00 to 02:00 on the 29th of March, so
+			// the interval starts at a wall clock time that does not exist
+			name:      "dst begins",
+			at:        time.Date(2026, 3, 29, 2, 15, 0, 0, lisbon),
+			wantOk:    true,
+			wantStart: time.Date(2026, 3, 29, 1, 30, 0, 0, lisbon),
+			wantEnd:   time.Date(2026, 3, 29, 2, 30, 0, 0, lisbon),
+		},
+		{
+			// clocks go back from 02:00 to 01:00 on the 25th of October, 01:45
+			// occurring a second time at 01:45 UTC
+			name:      "dst ends",
+			at:        time.Date(2026, 10, 25, 1, 45, 0, 0, time.UTC),
+			wantOk:    true,
+			wantStart: time.Date(2026, 10, 25, 1, 30, 0, 0, lisbon),
+			wantEnd:   time.Date(2026, 10, 25, 2, 30, 0, 0, lisbon),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := s.at(tt.at, lisbon)
+			if ok != tt.wantOk {
+				t.Fatalf("at() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if ok && (!got.Start.Equal(tt.wantStart) || !got.End.Equal(tt.wantEnd)) {
+				t.Errorf("at() = [%v, %v), want [%v, %v)", got.Start, got.End, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestNewPowerSchedule(t *testing.T) {
+	// viper lowercases the keys of the config
+	got, err := NewPowerSchedule(PowerScheduleConfig{
+		Timezone: "Europe/Lisbon",
+		Frames: map[string][]string{
+			"monday": {"06:45-07:30"},
+			"Sunday": {"23:00-01:00"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewPowerSchedule() error = %v", err)
+	}
+	want := PowerSchedule{
+		Timezone: "Europe/Lisbon",
+		Frames: map[time.Weekday][]PowerOnInterval{
+			time.Monday: {{From: 6*60 + 45, To: 7*60 + 30}},
+			time.Sunday: {{From: 23 * 60, To: 60}},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NewPowerSchedule() = %v, want %v", got, want)
+	}
+
+	if _, err := NewPowerSchedule(PowerScheduleConfig{Frames: map[string][]string{"someday": {"06:00-08:00"}}}); err == nil {
+		t.Errorf("NewPowerSchedule() with an unknown weekday should fail")
+	}
+	if _, err := NewPowerSchedule(PowerScheduleConfig{Frames: map[string][]string{"monday": {"6-8"}}}); err == nil {
+		t.Errorf("NewPowerSchedule() with hours only should fail")
+	}
+}
+
+func TestScheduleStore(t *testing.T) {
+	store := NewScheduleStore(filepath.Join(t.TempDir(), "espresso", "schedule.json"))
+
+	if _, ok, err := store.Load(); ok || err != nil {
+		t.Fatalf("Load() before Save() = %v, %v, want nothing stored", ok, err)
+	}
+
+	want := DefaultPowerSchedule()
+	want.Timezone = "America/New_York"
+	if err := store.Save(want); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	got, ok, err := store.Load()
+	if !ok || err != nil {
+		t.Fatalf("Load() = %v, %v, want the saved schedule", ok, err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Load() = %v, want %v", got, want)
+	}
+}
+
+func TestPowerManager_SetSchedule(t *testing.T) {
+	p, _ := newTestPowerManager()
+	store := NewScheduleStore(filepath.Join(t.TempDir(), "schedule.json"))
+	p.PersistScheduleTo(store)
+
+	invalid := PowerSchedule{Frames: map[time.Weekday][]PowerOnInterval{time.Friday: {mustInterval(t, "07:00-09:00"), mustInterval(t, "08:00-10:00")}}}
+	if err := p.SetSchedule(invalid); err == nil {
+		t.Fatalf("SetSchedule() of an invalid schedule should fail")
+	}
+	if _, ok, _ := store.Load(); ok {
+		t.Errorf("SetSchedule() of an invalid schedule should not save it")
+	}
+
+	valid := PowerSchedule{Timezone: "Asia/Tokyo", Frames: map[time.Weekday][]PowerOnInterval{time.Friday: {mustInterval(t, "07:00-09:00")}}}
+	if err := p.SetSchedule(valid); err != nil {
+		t.Fatalf("SetSchedule() error = %v", err)
+	}
+	if got, _, _ := store.Load(); !reflect.DeepEqual(got, valid) {
+		t.Errorf("SetSchedule() saved %v, want %v", got, valid)
+	}
+	if p.location.String() != "Asia/Tokyo" {
+		t.Errorf("SetSchedule() location = %v, want Asia/Tokyo", p.location)
+	}
+}
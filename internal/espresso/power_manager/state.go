@@ -0,0 +1,108 @@
+package power_manager
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// State is the state of the power manager's state machine. The machine is
+// powered on in the ScheduledOn and ManualOn states only.
+type State string
+
+const (
+	// StateOff is powered off, waiting for the next scheduled window
+	StateOff State = "off"
+	// StateScheduledOn is powered on by the schedule until the window ends
+	StateScheduledOn State = "scheduled_on"
+	// StateManualOn is powered on by the user until auto-off
+	StateManualOn State = "manual_on"
+	// StateScheduleSuppressed is powered off by the user during a scheduled
+	// window, which is skipped until it ends
+	StateScheduleSuppressed State = "schedule_suppressed"
+	// StateTotalOff is powered off, ignoring the schedule until the user powers
+	// the machine on
+	StateTotalOff State = "total_off"
+	// StateFault is powered off because of a safety fault, until it is reset
+	StateFault State = "fault"
+)
+
+// PowerOn is true if the machine is powered on in the state
+func (s State) PowerOn() bool {
+	return s == StateScheduledOn || s == StateManualOn
+}
+
+// Reason is why a transition happened
+type Reason string
+
+const (
+	ReasonStartup         Reason = "startup"
+	ReasonPowerButton     Reason = "power_button"
+	ReasonUser            Reason = "user"
+	ReasonScheduleStart   Reason = "schedule_start"
+	ReasonScheduleEnd     Reason = "schedule_end"
+	ReasonScheduleResumed Reason = "schedule_resumed"
+	ReasonAutoOff         Reason = "auto_off"
+	ReasonBrew            Reason = "brew"
+	ReasonFault           Reason = "fault"
+	ReasonFaultCleared    Reason = "fault_cleared"
+)
+
+// transitions lists the states each state can transition to
+var transitions = map[State][]State{
+	StateOff:                {StateScheduledOn, StateManualOn, StateTotalOff, StateFault},
+	StateScheduledOn:        {StateOff, StateScheduleSuppressed, StateTotalOff, StateFault},
+	StateManualOn:           {StateOff, StateScheduledOn, StateTotalOff, StateFault},
+	StateScheduleSuppressed: {StateOff, StateScheduledOn, StateManualOn, StateTotalOff, StateFault},
+	StateTotalOff:           {StateManualOn, StateFault},
+	StateFault:              {StateOff},
+}
+
+// ErrFault is returned when powering on the machine while it is in a fault
+var ErrFault = errors.New("powered off by a safety fault")
+
+// ErrNoWindow is returned when resuming or suppressing the scheduled window
+// outside of one
+var ErrNoWindow = errors.New("no scheduled window is active")
+
+// Transition is a change of state
+type Transition struct {
+	From   State
+	To     State
+	Reason Reason
+	At     time.Time
+}
+
+func (t Transition) String() string {
+	return fmt.Sprintf("%s -> %s (%s)", t.From, t.To, t.Reason)
+}
+
+func canTransition(from State, to State) bool {
+	for _, s := range transitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// maxHistory is the number of transitions kept in memory
+const maxHistory = 100
+
+// history keeps the last transitions, oldest first
+type history struct {
+	transitions []Transition
+}
+
+func (h *history) add(t Transition) {
+	if len(h.transitions) == maxHistory {
+		copy(h.transitions, h.transitions[1:])
+		h.transitions = h.transitions[:maxHistory-1]
+	}
+	h.transitions = append(h.transitions, t)
+}
+
+func (h *history) list() []Transition {
+	return append([]Transition(nil), h.transitions...)
+}
@@ -0,0 +1,269 @@
+// Package safety cuts off the heating element when the boiler temperature
+// readings indicate that heating is no longer under control.
+package safety
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/luiccn/espresso-controller/internal/espresso/event_log"
+	"github.com/luiccn/espresso-controller/internal/espresso/heating_element"
+	"github.com/luiccn/espresso-controller/internal/espresso/temperature"
+	"github.com/luiccn/espresso-controller/internal/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+)
+
+var (
+	faulted = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "espresso_safety_faulted",
+		Help: "Whether the heating element is cut off by a safety fault",
+	})
+	faults = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "espresso_safety_faults_total",
+		Help: "Number of safety faults by kind",
+	}, []string{"kind"})
+)
+
+type FaultKind string
+
+const (
+	// FaultOverTemperature is a reading above the maximum temperature
+	FaultOverTemperature FaultKind = "over_temperature"
+	// FaultRunaway is a temperature not rising while heating at full power,
+	// e.g. because the sensor came loose from the boiler
+	FaultRunaway FaultKind = "thermal_runaway"
+	// FaultStuck is a reading not changing at all while heating
+	FaultStuck FaultKind = "stuck_reading"
+	// FaultOutOfRange is a reading no boiler can produce
+	FaultOutOfRange FaultKind = "out_of_range"
+	// FaultSensor is a sensor repeatedly failing or not responding
+	FaultSensor FaultKind = "sensor_error"
+)
+
+type Fault struct {
+	Kind        FaultKind
+	Message     string
+	Temperature float32
+	At          time.Time
+}
+
+// Limits configure when the supervisor trips
+type Limits struct {
+	// Readings above MaxTemperature are an over-temperature fault
+	MaxTemperature float64
+	// Readings outside [MinPlausibleTemperature, MaxPlausibleTemperature] are
+	// an out-of-range fault
+	MinPlausibleTemperature float64
+	MaxPlausibleTemperature float64
+	// The temperature must rise by at least RunawayMinRise within
+	// RunawayWindow while the heating element is at full power
+	RunawayWindow  time.Duration
+	RunawayMinRise float64
+	// The reading must change within StuckWindow while heating
+	StuckWindow time.Duration
+	// Number of consecutive failed readings that are a sensor fault
+	MaxSensorErrors int
+	// A reading must arrive within StaleWindow of the previous one
+	StaleWindow time.Duration
+}
+
+// Supervisor wraps the boiler's temperature sampler and inspects every raw
+// reading. When one of the limits is violated it disables the heating element
+// and latches the fault until Reset is called. Supervisor implements
+// temperature.Sampler.
+type Supervisor struct {
+	sampler        temperature.Sampler
+	heatingElement *heating_element.HeatingElement
+	limits         Limits
+
+	mu                sync.Mutex
+	fault             *Fault
+	sensorErrors      int
+	lastSampleAt      time.Time
+	fullDutySince     time.Time
+	fullDutySinceTemp float32
+	lastValue         float32
+	lastChangeAt      time.Time
+	events            *event_log.Log
+
+	shutdownCh chan struct{}
+}
+
+func NewSupervisor(sampler temperature.Sampler, heatingElem *heating_element.HeatingElement, limits Limits) *Supervisor {
+	return &Supervisor{
+		sampler:        sampler,
+		heatingElement: heatingElem,
+		limits:         limits,
+		shutdownCh:     make(chan struct{}),
+	}
+}
+
+// Run watches for readings to stop arriving
+func (s *Supervisor) Run() {
+	if s.limits.StaleWindow <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(s.limits.StaleWindow / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.shutdownCh:
+				return
+			case now := <-ticker.C:
+				s.mu.Lock()
+				if !s.lastSampleAt.IsZero() && now.Sub(s.lastSampleAt) > s.limits.StaleWindow {
+					s.trip(FaultSensor, fmt.Sprintf("no temperature reading for %s", now.Sub(s.lastSampleAt).Round(time.Second)), s.lastValue, now)
+				}
+				s.mu.Unlock()
+			}
+		}
+	}()
+}
+
+func (s *Supervisor) Sample() (*temperature.Sample, error) {
+	sample, err := s.sampler.Sample()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.lastSampleAt = now
+	if err != nil {
+		s.sensorErrors++
+		if s.limits.MaxSensorErrors > 0 && s.sensorErrors >= s.limits.MaxSensorErrors {
+			s.trip(FaultSensor, fmt.Sprintf("%d consecutive failed readings, last: %s", s.sensorErrors, err), s.lastValue, now)
+		}
+		return nil, err
+	}
+	s.sensorErrors = 0
+	s.inspect(sample, s.heatingElement.DutyFactor())
+	return sample, nil
+}
+
+// inspect checks a successful reading against the limits. mu must be held.
+func (s *Supervisor) inspect(sample *temperature.Sample, dutyFactor float32) {
+	value := sample.Value
+	at := sample.ObservedAt
+
+	if math.IsNaN(float64(value)) || float64(value) < s.limits.MinPlausibleTemperature || float64(value) > s.limits.MaxPlausibleTemperature {
+		s.trip(FaultOutOfRange, fmt.Sprintf("reading %.1f °C is outside of [%.0f, %.0f] °C", value, s.limits.MinPlausibleTemperature, s.limits.MaxPlausibleTemperature), value, at)
+		return
+	}
+
+	if float64(value) > s.limits.MaxTemperature {
+		s.trip(FaultOverTemperature, fmt.Sprintf("reading %.1f °C is above %.0f °C", value, s.limits.MaxTemperature), value, at)
+		return
+	}
+
+	if dutyFactor >= 1 && s.limits.RunawayWindow > 0 {
+		if s.fullDutySince.IsZero() {
+			s.fullDutySince = at
+			s.fullDutySinceTemp = value
+		} else if at.Sub(s.fullDutySince) >= s.limits.RunawayWindow {
+			rise := value - s.fullDutySinceTemp
+			if float64(rise) < s.limits.RunawayMinRise {
+				s.trip(FaultRunaway, fmt.Sprintf("temperature rose %.1f °C in %s at full power", rise, at.Sub(s.fullDutySince).Round(time.Second)), value, at)
+				return
+			}
+			s.fullDutySince = at
+			s.fullDutySinceTemp = value
+		}
+	} else {
+		s.fullDutySince = time.Time{}
+	}
+
+	if value != s.lastValue || dutyFactor == 0 || s.lastChangeAt.IsZero() {
+		s.lastValue = value
+		s.lastChangeAt = at
+	} else if s.limits.StuckWindow > 0 && at.Sub(s.lastChangeAt) >= s.limits.StuckWindow {
+		s.trip(FaultStuck, fmt.Sprintf("reading stuck at %.2f °C for %s while heating", value, at.Sub(s.lastChangeAt).Round(time.Second)), value, at)
+	}
+}
+
+// trip latches the fault, unless one is latched already, and cuts off the
+// heating element. mu must be held.
+func (s *Supervisor) trip(kind FaultKind, message string, value float32, at time.Time) {
+	if s.fault != nil {
+		return
+	}
+	s.fault = &Fault{
+		Kind:        kind,
+		Message:     message,
+		Temperature: value,
+		At:          at,
+	}
+	s.heatingElement.Disable()
+	faulted.Set(1)
+	faults.WithLabelValues(string(kind)).Inc()
+	log.Error("Safety fault, heating element cut off", zap.String("kind", string(kind)), zap.String("message", message))
+	s.logEvent(event_log.Event{
+		At:      at,
+		Type:    event_log.TypeFault,
+		Cause:   string(kind),
+		Message: message,
+		Details: map[string]string{"temperature": fmt.Sprintf("%.1f", value)},
+	})
+}
+
+// logEvent appends the event to the event log, if there is one. mu must be
+// held.
+func (s *Supervisor) logEvent(e event_log.Event) {
+	if s.events == nil {
+		return
+	}
+	if _, err := s.events.Append(e); err != nil {
+		log.Error("Failed to log safety event", zap.Error(err))
+	}
+}
+
+// LogEventsTo appends faults and their resets to the log from now on
+func (s *Supervisor) LogEventsTo(events *event_log.Log) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = events
+}
+
+// GetFault returns the latched fault, or nil
+func (s *Supervisor) GetFault() *Fault {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fault == nil {
+		return nil
+	}
+	f := *s.fault
+	return &f
+}
+
+// Faulted reports whether a fault is latched
+func (s *Supervisor) Faulted() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.fault != nil
+}
+
+// Reset clears the latched fault and enables the heating element again. The
+// supervisor trips again if the fault persists.
+func (s *Supervisor) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fault != nil {
+		log.Info("Safety fault reset", zap.String("kind", string(s.fault.Kind)))
+		s.logEvent(event_log.Event{Type: event_log.TypeFaultReset, Cause: string(s.fault.Kind)})
+	}
+	s.fault = nil
+	s.sensorErrors = 0
+	s.fullDutySince = time.Time{}
+	s.lastChangeAt = time.Time{}
+	s.lastSampleAt = time.Time{}
+	s.heatingElement.Enable()
+	faulted.Set(0)
+}
+
+func (s *Supervisor) Shutdown() {
+	close(s.shutdownCh)
+}
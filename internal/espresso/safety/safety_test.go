@@ -0,0 +1,132 @@
+package safety
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/luiccn/espresso-controller/internal/espresso/gpio"
+	"github.com/luiccn/espresso-controller/internal/espresso/heating_element"
+	"github.com/luiccn/espresso-controller/internal/espresso/temperature"
+)
+
+var testLimits = Limits{
+	MaxTemperature:          160,
+	MinPlausibleTemperature: -20,
+	MaxPlausibleTemperature: 300,
+	RunawayWindow:           90 * time.Second,
+	RunawayMinRise:          1,
+	StuckWindow:             2 * time.Minute,
+	MaxSensorErrors:         3,
+}
+
+// reading is one scripted sample, taken after advancing the clock by dt
+type reading struct {
+	value float32
+	duty  float32
+	err   error
+	dt    time.Duration
+}
+
+type scriptedSampler struct {
+	now      time.Time
+	readings []reading
+	heater   *heating_element.HeatingElement
+}
+
+func (s *scriptedSampler) Sample() (*temperature.Sample, error) {
+	r := s.readings[0]
+	s.readings = s.readings[1:]
+	s.now = s.now.Add(r.dt)
+	s.heater.SetDutyFactor(r.duty)
+	if r.err != nil {
+		return nil, r.err
+	}
+	return &temperature.Sample{Value: r.value, ObservedAt: s.now}, nil
+}
+
+// repeat returns n readings, the temperature changing by step each second
+func repeat(n int, from float32, step float32, duty float32) []reading {
+	rs := make([]reading, n)
+	for i := range rs {
+		rs[i] = reading{value: from + float32(i)*step, duty: duty, dt: time.Second}
+	}
+	return rs
+}
+
+func TestSupervisor_Sample(t *testing.T) {
+	errRead := errors.New("read failed")
+	tests := []struct {
+		name     string
+		readings []reading
+		want     FaultKind
+	}{
+		{
+			name:     "Normal heat-up",
+			readings: repeat(300, 20, 0.3, 1),
+		},
+		{
+			name:     "Idle at target with a constant reading",
+			readings: repeat(300, 93, 0, 0),
+		},
+		{
+			name:     "Over temperature",
+			readings: []reading{{value: 150, dt: time.Second}, {value: 161, dt: time.Second}},
+			want:     FaultOverTemperature,
+		},
+		{
+			name:     "Garbage reading",
+			readings: []reading{{value: -242, dt: time.Second}},
+			want:     FaultOutOfRange,
+		},
+		{
+			name:     "Runaway at full power",
+			readings: repeat(120, 60, 0.005, 1),
+			want:     FaultRunaway,
+		},
+		{
+			name:     "Stuck while heating at partial power",
+			readings: repeat(180, 92, 0, 0.3),
+			want:     FaultStuck,
+		},
+		{
+			name:     "Intermittent sensor errors",
+			readings: []reading{{err: errRead}, {err: errRead}, {value: 93}, {err: errRead}, {err: errRead}},
+		},
+		{
+			name:     "Consecutive sensor errors",
+			readings: []reading{{value: 93}, {err: errRead}, {err: errRead}, {err: errRead}},
+			want:     FaultSensor,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			heater := heating_element.NewHeatingElement(gpio.NewFakeDriver(), 14)
+			sampler := &scriptedSampler{now: time.Unix(0, 0), readings: tt.readings, heater: heater}
+			s := NewSupervisor(sampler, heater, testLimits)
+
+			for len(sampler.readings) > 0 {
+				s.Sample()
+			}
+
+			fault := s.GetFault()
+			if tt.want == "" {
+				if fault != nil {
+					t.Fatalf("GetFault() = %+v, want no fault", fault)
+				}
+				return
+			}
+			if fault == nil || fault.Kind != tt.want {
+				t.Fatalf("GetFault() = %+v, want %s", fault, tt.want)
+			}
+			if !heater.IsDisabled() {
+				t.Errorf("heating element should be disabled on fault")
+			}
+
+			s.Reset()
+			if s.GetFault() != nil || heater.IsDisabled() {
+				t.Errorf("Reset() should clear the fault and enable the heating element")
+			}
+		})
+	}
+}
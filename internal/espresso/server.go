@@ -6,21 +6,29 @@ import (
 	"net"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 	"time"
 
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	grpc_zap "github.com/grpc-ecosystem/go-grpc-middleware/logging/zap"
 	grpc_ctxtags "github.com/grpc-ecosystem/go-grpc-middleware/tags"
+	"github.com/luiccn/espresso-controller/internal/espresso/brew"
+	"github.com/luiccn/espresso-controller/internal/espresso/button"
+	"github.com/luiccn/espresso-controller/internal/espresso/event_log"
+	"github.com/luiccn/espresso-controller/internal/espresso/gpio"
 	"github.com/luiccn/espresso-controller/internal/espresso/heating_element"
 	"github.com/luiccn/espresso-controller/internal/espresso/power_manager"
+	"github.com/luiccn/espresso-controller/internal/espresso/safety"
+	"github.com/luiccn/espresso-controller/internal/espresso/shot_history"
+	"github.com/luiccn/espresso-controller/internal/espresso/simulation"
+	"github.com/luiccn/espresso-controller/internal/espresso/spi"
+	"github.com/luiccn/espresso-controller/internal/espresso/steam"
 	"github.com/luiccn/espresso-controller/internal/espresso/temperature"
-	"github.com/luiccn/espresso-controller/internal/espresso/temperature/max31865"
 	"github.com/luiccn/espresso-controller/internal/log"
 	"github.com/luiccn/espresso-controller/pkg/espressopb"
 	"github.com/pkg/errors"
 	"github.com/soheilhy/cmux"
-	"github.com/stianeikeland/go-rpio/v4"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
@@ -28,6 +36,7 @@ import (
 
 type Configuration struct {
 	Port                   int
+	GpioBackend            string
 	HeatingElementRelayPin int
 	PowerButtonRelayPin    int
 	PowerButtonPin         int
@@ -36,20 +45,100 @@ type Configuration struct {
 	BoilerThermClkPin      int
 	BoilerThermMisoPin     int
 	BoilerThermMosiPin     int
+	// Amplifier of the boiler thermometer, max31865, max6675 or max31855
+	BoilerThermSensor string
+	// How the max31865 is read, spi.TransportBitbang over the pins above or
+	// spi.TransportSpidev through the device node
+	BoilerThermTransport  string
+	BoilerThermSpiDevice  string
+	BoilerThermSpiSpeedHz uint32
+	// RTD connected to the max31865, see max31865.Config
+	BoilerThermRTDNominal  float32
+	BoilerThermRefResistor float32
+	BoilerThermWires       int
+	BoilerThermFilterHz    int
+	// Temperatures in °C outside of which the max31865 flags a fault
+	BoilerThermLowFaultThreshold  float32
+	BoilerThermHighFaultThreshold float32
+	ControlStrategy               string
+	Safety                        safety.Limits
+	Simulate                      bool
+	Simulation                    simulation.Parameters
+
+	// Boiler temperature in °C around the setpoint that counts as at
+	// temperature, e.g. for the power LED
+	AtTemperatureTolerance float32
+
+	// Kernel w1 sysfs root and ids of the optional ds18b20 thermometers, e.g.
+	// 28-0316a2795ff2
+	W1SysfsRoot    string
+	GroupThermId   string
+	AmbientThermId string
+
+	// Weekly power schedule, used until one is set at runtime and saved to
+	// PowerScheduleFile
+	PowerSchedule     *power_manager.PowerScheduleConfig
+	PowerScheduleFile string
+
+	// iCalendar file whose events power on the machine in addition to the
+	// weekly schedule, re-read when it changes
+	PowerScheduleCalendarFile    string
+	PowerScheduleCalendarRefresh time.Duration
+
+	// Log of power, configuration and safety events
+	EventLog event_log.Config
+
+	// Database of the shots pulled
+	ShotHistoryFile string
+
+	// Feed-forward heater boost at the start of shots, see
+	// control.ParseBoostProfile
+	BoostProfile string
+
+	AutoOff power_manager.AutoOffConfig
+	Brew    brew.Config
+	// Actions of the power button's gestures
+	Button button.Config
+	Steam  steam.Config
 }
 
 type Server struct {
 	c Configuration
 
+	gpio gpio.Driver
+
 	grpcEspressoServer espressopb.EspressoServer
+	grpcPowerServer    espressopb.PowerServiceServer
 	grpcServer         *grpc.Server
 
 	powerManager *power_manager.PowerManager
 
+	eventLog *event_log.Log
+
 	heatingElem *heating_element.HeatingElement
 
+	boiler *simulation.Boiler
+
+	boilerBus spi.Bus
+
+	supervisor *safety.Supervisor
+
 	groupMonitor *temperature.Monitor
 
+	ambientMonitor *temperature.Monitor
+
+	brewDetector *brew.Detector
+
+	shotTimer *brew.Timer
+
+	shots *shot_history.Store
+
+	shotRecorder *shot_history.Recorder
+
+	powerButton *button.Button
+
+	steamSelector *steam.Selector
+
 	fs embed.FS
 
 	shutdownCh chan struct{}
@@ -58,55 +147,158 @@ type Server struct {
 func New(c Configuration, fs embed.FS) *Server {
 	return &Server{
 		c:          c,
-		fs: fs,
+		fs:         fs,
 		shutdownCh: make(chan struct{}),
 	}
 }
 
 func (s *Server) Run() error {
-	if err := rpio.Open(); err != nil {
+	gpioBackend := s.c.GpioBackend
+	if s.c.Simulate {
+		log.Info("Simulating the espresso machine, no hardware will be accessed")
+		gpioBackend = gpio.BackendFake
+	}
+
+	driver, err := gpio.NewDriver(gpioBackend)
+	if err != nil {
+		return err
+	}
+	if err := driver.Open(); err != nil {
 		return errors.Wrap(err, "initializing gpio access")
 	}
+	s.gpio = driver
 
-	schedule := make(map[time.Weekday][]power_manager.PowerOnInterval)
+	eventLog, err := s.openEventLog()
+	if err != nil {
+		return err
+	}
+	s.eventLog = eventLog
 
-	days := []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday}
+	shots, err := s.openShotHistory()
+	if err != nil {
+		return err
+	}
+	s.shots = shots
 
-	for _, d := range days {
-		poi := make([]power_manager.PowerOnInterval, 3)
-		
-		if d == time.Saturday || d == time.Sunday {
-			poi[0] = power_manager.PowerOnInterval{From: 7, To: 9}
-			poi[1] = power_manager.PowerOnInterval{From: 11, To: 13}
-			poi[2] = power_manager.PowerOnInterval{From: 14, To: 15}
-		} else {
-			poi[0] = power_manager.PowerOnInterval{From: 6, To: 8}
-			poi[1] = power_manager.PowerOnInterval{From: 11, To: 13}
-			poi[2] = power_manager.PowerOnInterval{From: 14, To: 15}
-		}
-		
-		schedule[d] = poi
+	scheduleStore, schedule, err := s.loadPowerSchedule()
+	if err != nil {
+		return err
 	}
 
-	powerManager := power_manager.NewPowerManager(power_manager.PowerSchedule{Frames: schedule}, 60*time.Minute, s.c.PowerButtonRelayPin, s.c.PowerButtonPin, s.c.PowerLedPin)
+	powerManager := power_manager.NewPowerManager(driver, schedule, s.c.AutoOff, s.c.PowerButtonRelayPin, s.c.PowerLedPin)
+	powerManager.PersistScheduleTo(scheduleStore)
+	powerManager.LogEventsTo(eventLog)
+	calendarStore, err := s.powerScheduleCalendarStore()
+	if err != nil {
+		return err
+	}
+	if err := powerManager.UseCalendar(calendarStore, s.c.PowerScheduleCalendarRefresh); err != nil {
+		log.Error("Ignoring power schedule calendar", zap.Error(err))
+	}
 	s.powerManager = powerManager
 	powerManager.Run()
 
-	heatingElem := heating_element.NewHeatingElement(s.c.HeatingElementRelayPin)
+	powerButton, err := button.NewButton(s.c.Button, driver, s.c.PowerButtonPin)
+	if err != nil {
+		return errors.Wrap(err, "invalid power button configuration")
+	}
+	s.powerButton = powerButton
+	powerManager.HandleButton(powerButton)
+
+	steamSelector, err := steam.NewSelector(s.c.Steam, s.c.Safety.MaxTemperature, driver)
+	if err != nil {
+		return errors.Wrap(err, "invalid steam configuration")
+	}
+	s.steamSelector = steamSelector
+	steamSelector.WatchPower(powerManager)
+	steamSelector.HandleButton(powerButton)
+	steamSelector.Run()
+	powerButton.Run()
+
+	heatingElem := heating_element.NewHeatingElement(driver, s.c.HeatingElementRelayPin)
 	s.heatingElem = heatingElem
 	heatingElem.Run()
 
-	boilerMonitor := temperature.NewMonitor(
-		max31865.NewMax31865(s.c.BoilerThermCsPin, s.c.BoilerThermClkPin, s.c.BoilerThermMisoPin, s.c.BoilerThermMosiPin),
-		time.Second,
-	)
+	var boilerSampler temperature.Sampler
+	if s.c.Simulate {
+		boiler := simulation.NewBoiler(s.c.Simulation, heatingElem)
+		s.boiler = boiler
+		boiler.Run()
+		boilerSampler = boiler
+	} else {
+		mode, err := boilerThermometerSPIMode(s.c.BoilerThermSensor)
+		if err != nil {
+			return err
+		}
+		bus, err := spi.NewBus(driver, spi.Config{
+			Transport: s.c.BoilerThermTransport,
+			Mode:      mode,
+			CsPin:     s.c.BoilerThermCsPin,
+			ClkPin:    s.c.BoilerThermClkPin,
+			MisoPin:   s.c.BoilerThermMisoPin,
+			MosiPin:   s.c.BoilerThermMosiPin,
+			Device:    s.c.BoilerThermSpiDevice,
+			SpeedHz:   s.c.BoilerThermSpiSpeedHz,
+		})
+		if err != nil {
+			return errors.Wrap(err, "opening boiler thermometer spi bus")
+		}
+		s.boilerBus = bus
+		thermometer, err := newBoilerThermometer(s.c, bus)
+		if err != nil {
+			return errors.Wrap(err, "invalid boiler thermometer configuration")
+		}
+		boilerSampler = thermometer
+	}
+
+	supervisor := safety.NewSupervisor(boilerSampler, heatingElem, s.c.Safety)
+	supervisor.LogEventsTo(eventLog)
+	s.supervisor = supervisor
+	supervisor.Run()
+	powerManager.WatchFaults(supervisor)
+
+	boilerMonitor := temperature.NewMonitor(supervisor, time.Second)
 	boilerMonitor.Run()
 
-	grpcController, err := newGrpcController(s.c, heatingElem, boilerMonitor, nil, powerManager)
+	brewDetector := brew.NewDetector(s.c.Brew, driver, boilerMonitor)
+	s.brewDetector = brewDetector
+	brewDetector.WatchMachine(powerManager, steamSelector)
+	brewDetector.Run()
+	powerManager.WatchBrews(brewDetector)
+
+	shotTimer := brew.NewTimer(brewDetector, s.c.Brew.MaxShotDuration)
+	s.shotTimer = shotTimer
+	shotTimer.HandleButton(powerButton)
+	shotTimer.Run()
+
+	groupMonitor, err := newOneWireMonitor(s.c.W1SysfsRoot, s.c.GroupThermId)
+	if err != nil {
+		return errors.Wrap(err, "invalid group head thermometer configuration")
+	}
+	s.groupMonitor = groupMonitor
+
+	ambientMonitor, err := newOneWireMonitor(s.c.W1SysfsRoot, s.c.AmbientThermId)
+	if err != nil {
+		return errors.Wrap(err, "invalid ambient thermometer configuration")
+	}
+	s.ambientMonitor = ambientMonitor
+
+	grpcController, err := newGrpcController(s.c, heatingElem, boilerMonitor, groupMonitor, ambientMonitor, powerManager, supervisor, eventLog, steamSelector, shotTimer, shots)
 	if err != nil {
 		return err
 	}
+
+	// the group monitor is nil without a group head thermometer
+	var groupHistory shot_history.History
+	if groupMonitor != nil {
+		groupHistory = groupMonitor
+	}
+	shotRecorder := shot_history.NewRecorder(shots, grpcController, boilerMonitor, groupHistory)
+	s.shotRecorder = shotRecorder
+	shotRecorder.Run(shotTimer)
 	s.grpcEspressoServer = grpcController
+	powerManager.WatchTemperature(grpcController)
+	s.grpcPowerServer = newGrpcPowerController(powerManager, steamSelector)
 
 	grpcServer := grpc.NewServer(
 		grpc.UnaryInterceptor(grpc_middleware.ChainUnaryServer(
@@ -128,6 +320,83 @@ func (s *Server) Run() error {
 	}
 }
 
+// openEventLog opens the event log, which defaults to ~/.espresso/events.jsonl
+func (s *Server) openEventLog() (*event_log.Log, error) {
+	c := s.c.EventLog
+	if c.Path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, errors.Wrap(err, "locating event log")
+		}
+		c.Path = filepath.Join(home, ".espresso", "events.jsonl")
+	}
+	eventLog, err := event_log.Open(c)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening event log")
+	}
+	return eventLog, nil
+}
+
+// openShotHistory opens the shot history, which defaults to
+// ~/.espresso/shots.db
+func (s *Server) openShotHistory() (*shot_history.Store, error) {
+	path := s.c.ShotHistoryFile
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, errors.Wrap(err, "locating shot history")
+		}
+		path = filepath.Join(home, ".espresso", "shots.db")
+	}
+	return shot_history.Open(path)
+}
+
+// loadPowerSchedule returns the schedule last set at runtime, falling back to
+// the one in the config and then to the default one
+func (s *Server) loadPowerSchedule() (*power_manager.ScheduleStore, power_manager.PowerSchedule, error) {
+	path := s.c.PowerScheduleFile
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, power_manager.PowerSchedule{}, errors.Wrap(err, "locating power schedule file")
+		}
+		path = filepath.Join(home, ".espresso", "schedule.json")
+	}
+	store := power_manager.NewScheduleStore(path)
+
+	schedule, ok, err := store.Load()
+	if err != nil {
+		return nil, power_manager.PowerSchedule{}, err
+	}
+	if ok {
+		log.Info("Loaded power schedule", zap.String("path", path))
+		return store, schedule, nil
+	}
+
+	if s.c.PowerSchedule != nil {
+		schedule, err := power_manager.NewPowerSchedule(*s.c.PowerSchedule)
+		if err != nil {
+			return nil, power_manager.PowerSchedule{}, errors.Wrap(err, "invalid power schedule configuration")
+		}
+		return store, schedule, nil
+	}
+	return store, power_manager.DefaultPowerSchedule(), nil
+}
+
+// powerScheduleCalendarStore returns the store of the calendar file, which
+// defaults to ~/.espresso/schedule.ics
+func (s *Server) powerScheduleCalendarStore() (*power_manager.CalendarStore, error) {
+	path := s.c.PowerScheduleCalendarFile
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, errors.Wrap(err, "locating power schedule calendar file")
+		}
+		path = filepath.Join(home, ".espresso", "schedule.ics")
+	}
+	return power_manager.NewCalendarStore(path), nil
+}
+
 func (s *Server) serveTCP() error {
 	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", s.c.Port))
 	if err != nil {
@@ -136,6 +405,7 @@ func (s *Server) serveTCP() error {
 	}
 
 	espressopb.RegisterEspressoServer(s.grpcServer, s.grpcEspressoServer)
+	espressopb.RegisterPowerServiceServer(s.grpcServer, s.grpcPowerServer)
 
 	mux := cmux.New(listener)
 	grpcListener := mux.MatchWithWriters(cmux.HTTP2MatchHeaderFieldPrefixSendSettings("content-type", "application/grpc"))
@@ -162,7 +432,7 @@ func (s *Server) serveGRPC(listener net.Listener, grpcServer *grpc.Server) error
 func (s *Server) serveHTTP1(listener net.Listener, grpcServer *grpc.Server) error {
 	log.Info("Initializing gRPC web server", zap.Int("port", s.c.Port))
 	server := NewGRPCWebServer(grpcServer, s.fs)
-	if err := server.Listen(listener, true /*TODO*/, s.powerManager); err != nil {
+	if err := server.Listen(listener, true /*TODO*/, s.powerManager, s.supervisor, s.eventLog, s.shots, s.boiler); err != nil {
 		log.Error("gRPC web server failed", zap.Error(err))
 		return errors.Wrap(err, "gRPC web server failed")
 	}
@@ -180,10 +450,30 @@ func (s *Server) watchSignals() {
 func (s *Server) Shutdown() error {
 	log.Info("Shutting down heating element relay")
 	s.heatingElem.Shutdown()
+	s.supervisor.Shutdown()
+	s.shotRecorder.Shutdown()
+	s.shotTimer.Shutdown()
+	s.brewDetector.Shutdown()
+	s.powerButton.Shutdown()
+	s.steamSelector.Shutdown()
 	s.powerManager.Shutdown()
+	if err := s.eventLog.Close(); err != nil {
+		log.Error("Failed to close event log", zap.Error(err))
+	}
+	if err := s.shots.Close(); err != nil {
+		log.Error("Failed to close shot history", zap.Error(err))
+	}
+	if s.boiler != nil {
+		s.boiler.Shutdown()
+	}
+	if s.boilerBus != nil {
+		if err := s.boilerBus.Close(); err != nil {
+			log.Error("Failed to close boiler thermometer spi bus", zap.Error(err))
+		}
+	}
 
 	log.Info("Unmapping gpio memory")
-	if err := rpio.Close(); err != nil {
+	if err := s.gpio.Close(); err != nil {
 		return errors.Wrap(err, "unmapping gpio memory")
 	}
 	return nil
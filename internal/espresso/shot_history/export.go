@@ -0,0 +1,105 @@
+package shot_history
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type Format string
+
+const (
+	// FormatCSV writes a row per shot, without the traces
+	FormatCSV Format = "csv"
+	// FormatJSON writes an array of the shots, with their traces
+	FormatJSON Format = "json"
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case FormatCSV, FormatJSON:
+		return f, nil
+	case "":
+		return FormatCSV, nil
+	default:
+		return "", errors.Errorf("unknown export format %q, expected %s or %s", s, FormatCSV, FormatJSON)
+	}
+}
+
+// ContentType is the MIME type of the format
+func (f Format) ContentType() string {
+	if f == FormatJSON {
+		return "application/json"
+	}
+	return "text/csv"
+}
+
+var csvHeader = []string{
+	"id", "start", "end", "seconds", "source", "setpoint", "strategy", "parameters",
+	"boost", "dose", "yield", "bean", "grind", "rating",
+}
+
+// Export writes the shots in the format
+func Export(w io.Writer, format Format, shots []Shot) error {
+	switch format {
+	case FormatJSON:
+		if shots == nil {
+			shots = []Shot{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(shots)
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(csvHeader); err != nil {
+			return err
+		}
+		for _, s := range shots {
+			if err := cw.Write(csvRow(s)); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	default:
+		return errors.Errorf("unknown export format %q", format)
+	}
+}
+
+func csvRow(s Shot) []string {
+	float := func(f float32) string { return strconv.FormatFloat(float64(f), 'f', -1, 32) }
+	// e.g. "d=100 i=0.15 p=16"
+	var params []string
+	for name, value := range s.Parameters {
+		params = append(params, fmt.Sprintf("%s=%s", name, float(value)))
+	}
+	sort.Strings(params)
+	// e.g. "5s:100 20s:60"
+	var boost []string
+	for _, step := range s.Boost {
+		boost = append(boost, fmt.Sprintf("%ss:%s", float(step.Seconds), float(step.Output)))
+	}
+	return []string{
+		strconv.FormatUint(s.Id, 10),
+		s.Start.Format(time.RFC3339),
+		s.End.Format(time.RFC3339),
+		strconv.FormatFloat(s.Duration().Seconds(), 'f', 1, 64),
+		s.Source,
+		float(s.Setpoint),
+		s.Strategy,
+		strings.Join(params, " "),
+		strings.Join(boost, " "),
+		float(s.Notes.Dose),
+		float(s.Notes.Yield),
+		s.Notes.Bean,
+		s.Notes.Grind,
+		strconv.Itoa(s.Notes.Rating),
+	}
+}
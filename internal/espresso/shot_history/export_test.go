@@ -0,0 +1,83 @@
+package shot_history
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestExport(t *testing.T) {
+	shots := []Shot{
+		{
+			Id:          1,
+			Start:       testStart,
+			End:         testStart.Add(27500 * time.Millisecond),
+			Source:      "temperature",
+			Setpoint:    93,
+			Strategy:    "pid",
+			Parameters:  map[string]float32{"p": 16, "i": 0.15, "d": 100},
+			Boost:       []BoostStep{{Seconds: 5, Output: 100}, {Seconds: 20, Output: 60}},
+			BoilerTrace: []Point{{At: testStart, Value: 93}},
+			Notes:       Notes{Dose: 18, Yield: 36, Bean: "Huila, washed", Grind: "12", Rating: 4},
+		},
+		{Id: 2, Start: testStart.Add(time.Hour), End: testStart.Add(time.Hour + 30*time.Second), Source: "button"},
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := Export(&buf, FormatCSV, shots); err != nil {
+			t.Fatalf("Export() error = %v", err)
+		}
+		want := "id,start,end,seconds,source,setpoint,strategy,parameters,boost,dose,yield,bean,grind,rating\n" +
+			"1,2026-10-19T07:00:00Z,2026-10-19T07:00:27Z,27.5,temperature,93,pid,d=100 i=0.15 p=16,5s:100 20s:60,18,36,\"Huila, washed\",12,4\n" +
+			"2,2026-10-19T08:00:00Z,2026-10-19T08:00:30Z,30.0,button,0,,,,0,0,,,0\n"
+		if got := buf.String(); got != want {
+			t.Errorf("Export() =\n%s\nwant\n%s", got, want)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := Export(&buf, FormatJSON, shots); err != nil {
+			t.Fatalf("Export() error = %v", err)
+		}
+		var got []Shot
+		if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+			t.Fatalf("exported json is invalid: %v", err)
+		}
+		if !reflect.DeepEqual(got, shots) {
+			t.Errorf("Export() = %+v, want %+v", got, shots)
+		}
+	})
+
+	t.Run("json without shots", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := Export(&buf, FormatJSON, nil); err != nil || buf.String() != "[]\n" {
+			t.Errorf("Export() = %q, %v, want an empty array", buf.String(), err)
+		}
+	})
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    Format
+		wantErr bool
+	}{
+		{s: "csv", want: FormatCSV},
+		{s: "json", want: FormatJSON},
+		{s: "", want: FormatCSV},
+		{s: "xlsx", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := ParseFormat(tt.s)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("ParseFormat() = %q, %v, want %q, wantErr %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package shot_history
+
+import (
+	"sync"
+	"time"
+
+	"github.com/luiccn/espresso-controller/internal/espresso/brew"
+	"github.com/luiccn/espresso-controller/internal/espresso/temperature"
+	"github.com/luiccn/espresso-controller/internal/log"
+	"go.uber.org/zap"
+)
+
+// traceMargin is how long before and after the shot its traces extend
+const traceMargin = 10 * time.Second
+
+// Settings are the temperature control settings in effect for a shot
+type Settings struct {
+	Setpoint   float32
+	Strategy   string
+	Parameters map[string]float32
+	Boost      []BoostStep
+}
+
+// SettingsSource reports the temperature control settings in effect for a
+// shot
+type SettingsSource interface {
+	ShotSettings(shot brew.Shot) Settings
+}
+
+// History is the recent temperature history of a thermometer, e.g. a
+// temperature.Monitor
+type History interface {
+	GetHistory() []*temperature.Sample
+}
+
+// Recorder adds the shots timed by the shot timer to the store once the trace
+// margin after they stop has been sampled
+type Recorder struct {
+	store  *Store
+	source SettingsSource
+	boiler History
+	// nil without a group head thermometer
+	group History
+
+	// the shot being pulled and the settings it started with, only used by
+	// Run
+	started         brew.Shot
+	startedSettings Settings
+	// after waits for the trace margin after a stop, time.After but in tests
+	after func(d time.Duration) <-chan time.Time
+
+	wg         sync.WaitGroup
+	shutdownCh chan struct{}
+}
+
+// stoppedShot is a shot waiting for the samples after it to be taken
+type stoppedShot struct {
+	shot     brew.Shot
+	settings Settings
+	due      <-chan time.Time
+}
+
+func NewRecorder(store *Store, settings SettingsSource, boiler History, group History) *Recorder {
+	return &Recorder{
+		store:      store,
+		source:     settings,
+		boiler:     boiler,
+		group:      group,
+		after:      time.After,
+		shutdownCh: make(chan struct{}),
+	}
+}
+
+func (r *Recorder) Run(timer *brew.Timer) {
+	subId, shots := timer.Subscribe()
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		defer timer.Unsubscribe(subId)
+		r.loop(shots)
+	}()
+}
+
+// loop records the stopped shots once their trace margin has passed, or right
+// away on shutdown. Shots stop one after the other, so they are due in the
+// order they stopped.
+func (r *Recorder) loop(shots <-chan brew.Shot) {
+	var pending []stoppedShot
+	defer func() {
+		for _, p := range pending {
+			r.record(p.shot, p.settings)
+		}
+	}()
+	for {
+		var due <-chan time.Time
+		if len(pending) > 0 {
+			due = pending[0].due
+		}
+		select {
+		case <-r.shutdownCh:
+			return
+		case <-due:
+			r.record(pending[0].shot, pending[0].settings)
+			pending = pending[1:]
+		case shot, ok := <-shots:
+			if !ok {
+				return
+			}
+			if shot.Running() {
+				r.started = shot
+				r.startedSettings = r.source.ShotSettings(shot)
+				continue
+			}
+			pending = append(pending, stoppedShot{
+				shot:     shot,
+				settings: r.settings(shot),
+				due:      r.after(traceMargin),
+			})
+		}
+	}
+}
+
+// settings returns the settings the stopped shot started with, or the current
+// ones if its start was missed
+func (r *Recorder) settings(shot brew.Shot) Settings {
+	if !shot.Start.Equal(r.started.Start) {
+		return r.source.ShotSettings(shot)
+	}
+	return r.startedSettings
+}
+
+// record stores the stopped shot with its settings and the temperatures
+// sampled from the trace margin before it to the trace margin after it
+func (r *Recorder) record(shot brew.Shot, settings Settings) {
+	from, to := shot.Start.Add(-traceMargin), shot.End.Add(traceMargin)
+	s, err := r.store.Add(Shot{
+		Start:       shot.Start,
+		End:         shot.End,
+		Source:      string(shot.Source),
+		Setpoint:    settings.Setpoint,
+		Strategy:    settings.Strategy,
+		Parameters:  settings.Parameters,
+		Boost:       settings.Boost,
+		BoilerTrace: trace(r.boiler, from, to),
+		GroupTrace:  trace(r.group, from, to),
+	})
+	if err != nil {
+		log.Error("Failed to record shot", zap.Error(err))
+		return
+	}
+	log.Info("Recorded shot", zap.Uint64("id", s.Id), zap.Duration("duration", s.Duration()))
+}
+
+// trace returns the samples of the history between from and to
+func trace(h History, from time.Time, to time.Time) []Point {
+	if h == nil {
+		return nil
+	}
+	var points []Point
+	for _, s := range h.GetHistory() {
+		if !s.ObservedAt.Before(from) && !s.ObservedAt.After(to) {
+			points = append(points, Point{At: s.ObservedAt, Value: s.Value})
+		}
+	}
+	return points
+}
+
+// Shutdown records the shots still waiting for their trace margin with the
+// samples taken so far
+func (r *Recorder) Shutdown() {
+	close(r.shutdownCh)
+	r.wg.Wait()
+}
@@ -0,0 +1,140 @@
+package shot_history
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/luiccn/espresso-controller/internal/espresso/brew"
+	"github.com/luiccn/espresso-controller/internal/espresso/temperature"
+)
+
+// fakeSettings reports the settings, with the setpoint changed to 95 °C
+// during the shot
+type fakeSettings struct {
+	settings Settings
+}
+
+func (f *fakeSettings) ShotSettings(shot brew.Shot) Settings {
+	s := f.settings
+	if !shot.Running() {
+		s.Setpoint = 95
+	}
+	return s
+}
+
+type fakeHistory struct {
+	mu      sync.Mutex
+	samples []*temperature.Sample
+}
+
+func (f *fakeHistory) GetHistory() []*temperature.Sample {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]*temperature.Sample(nil), f.samples...)
+}
+
+func (f *fakeHistory) add(samples ...*temperature.Sample) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.samples = append(f.samples, samples...)
+}
+
+func TestRecorder_loop(t *testing.T) {
+	at := func(s int) time.Time { return testStart.Add(time.Duration(s) * time.Second) }
+	// boiler samples every 5s from the first to the last second
+	boilerSamples := func(first int, last int) []*temperature.Sample {
+		var samples []*temperature.Sample
+		for s := first; s <= last; s += 5 {
+			samples = append(samples, &temperature.Sample{Value: 93 - float32(s)/10, ObservedAt: at(s)})
+		}
+		return samples
+	}
+
+	boost := []BoostStep{{Seconds: 25, Output: 60}}
+	settings := &fakeSettings{Settings{Setpoint: 93, Strategy: "pid", Parameters: map[string]float32{"p": 16}, Boost: boost}}
+	started := brew.Shot{Start: at(0), Source: brew.SourceSwitch}
+	stopped := brew.Shot{Start: at(0), End: at(30), Source: brew.SourceSwitch}
+	traceUntilStop := []Point{
+		{At: at(-10), Value: 94}, {At: at(-5), Value: 93.5}, {At: at(0), Value: 93}, {At: at(5), Value: 92.5},
+		{At: at(10), Value: 92}, {At: at(15), Value: 91.5}, {At: at(20), Value: 91}, {At: at(25), Value: 90.5},
+		{At: at(30), Value: 90},
+	}
+	trace := append(append([]Point(nil), traceUntilStop...), Point{At: at(35), Value: 89.5}, Point{At: at(40), Value: 89})
+
+	tests := []struct {
+		name         string
+		group        bool
+		missedStart  bool
+		shutdown     bool
+		wantSetpoint float32
+		wantTrace    []Point
+		wantGroup    []Point
+	}{
+		{name: "settings at the start", wantSetpoint: 93, wantTrace: trace},
+		{name: "start missed", missedStart: true, wantSetpoint: 95, wantTrace: trace},
+		{name: "group thermometer", group: true, wantSetpoint: 93, wantTrace: trace, wantGroup: []Point{{At: at(0), Value: 88}}},
+		{name: "shutdown before the margin", shutdown: true, wantSetpoint: 93, wantTrace: traceUntilStop},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, _ := openTestStore(t)
+			boiler := &fakeHistory{samples: boilerSamples(-20, 30)}
+			var group History
+			if tt.group {
+				group = &fakeHistory{samples: []*temperature.Sample{{Value: 88, ObservedAt: at(0)}}}
+			}
+			r := NewRecorder(store, settings, boiler, group)
+			waited := make(chan time.Duration, 1)
+			due := make(chan time.Time)
+			r.after = func(d time.Duration) <-chan time.Time {
+				waited <- d
+				return due
+			}
+
+			shots := make(chan brew.Shot)
+			r.wg.Add(1)
+			go func() {
+				defer r.wg.Done()
+				r.loop(shots)
+			}()
+			if !tt.missedStart {
+				shots <- started
+			}
+			shots <- stopped
+			if d := <-waited; d != traceMargin {
+				t.Errorf("waited %s after the stop, want %s", d, traceMargin)
+			}
+			if recorded, _ := store.Range(at(-60), at(60)); len(recorded) != 0 {
+				t.Errorf("recorded %v before the trace margin passed", recorded)
+			}
+
+			if !tt.shutdown {
+				// sampled after the stop
+				boiler.add(boilerSamples(35, 60)...)
+				due <- at(40)
+			}
+			r.Shutdown()
+
+			recorded, err := store.Range(at(-60), at(60))
+			if err != nil || len(recorded) != 1 {
+				t.Fatalf("recorded %v, %v, want a shot", recorded, err)
+			}
+			shot := recorded[0]
+			if shot.Setpoint != tt.wantSetpoint || shot.Strategy != "pid" || shot.Duration() != 30*time.Second || shot.Source != "switch" {
+				t.Errorf("recorded %+v, want setpoint %v of a 30s pid shot from the switch", shot, tt.wantSetpoint)
+			}
+			if !reflect.DeepEqual(shot.Boost, boost) {
+				t.Errorf("boost = %v, want %v", shot.Boost, boost)
+			}
+			if !reflect.DeepEqual(shot.BoilerTrace, tt.wantTrace) {
+				t.Errorf("boiler trace = %v, want %v", shot.BoilerTrace, tt.wantTrace)
+			}
+			if !reflect.DeepEqual(shot.GroupTrace, tt.wantGroup) {
+				t.Errorf("group trace = %v, want %v", shot.GroupTrace, tt.wantGroup)
+			}
+		})
+	}
+}
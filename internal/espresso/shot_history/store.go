@@ -0,0 +1,305 @@
+// Package shot_history keeps the shots pulled, with the temperatures during
+// the shot and the user's notes, in an embedded database.
+package shot_history
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	defaultLimit = 50
+	maxLimit     = 1000
+	maxRating    = 5
+)
+
+var shotsBucket = []byte("shots")
+
+// ErrNotFound is returned for a shot id that is not in the store
+var ErrNotFound = errors.New("shot not found")
+
+// Point is a temperature in °C at a point in time
+type Point struct {
+	At    time.Time
+	Value float32
+}
+
+// Notes are entered by the user after pulling the shot
+type Notes struct {
+	// Ground coffee in g
+	Dose float32
+	// Espresso in the cup in g
+	Yield float32
+	Bean  string
+	Grind string
+	// 1 to 5, unrated if 0
+	Rating int
+}
+
+func (n Notes) Validate() error {
+	if n.Dose < 0 || n.Yield < 0 {
+		return errors.New("dose and yield must be >= 0")
+	}
+	if n.Rating < 0 || n.Rating > maxRating {
+		return errors.Errorf("rating must be in range [0, %d]", maxRating)
+	}
+	return nil
+}
+
+// BoostStep added Output percent of the heating element's power for Seconds,
+// see control.BoostProfile
+type BoostStep struct {
+	Seconds float32
+	Output  float32
+}
+
+type Shot struct {
+	Id    uint64
+	Start time.Time
+	End   time.Time
+	// how the shot was detected, see brew.Source
+	Source string
+	// setpoint, temperature control strategy and its parameters in effect
+	// when the shot started
+	Setpoint   float32
+	Strategy   string
+	Parameters map[string]float32
+	// feed-forward heater boost applied from the start of the shot, empty if
+	// none was
+	Boost []BoostStep `json:",omitempty"`
+	// temperatures from shortly before the shot started until shortly after
+	// it stopped, oldest first. GroupTrace is empty without a group head
+	// thermometer.
+	BoilerTrace []Point `json:",omitempty"`
+	GroupTrace  []Point `json:",omitempty"`
+	Notes       Notes
+}
+
+func (s Shot) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+// withoutTraces returns the shot without its temperature traces
+func (s Shot) withoutTraces() Shot {
+	s.BoilerTrace = nil
+	s.GroupTrace = nil
+	return s
+}
+
+type Query struct {
+	// shots started at or after From and before To, unbounded if zero
+	From time.Time
+	To   time.Time
+	// shots with an id below Before, the next page token of the previous
+	// page, from the newest if zero
+	Before uint64
+	Limit  int
+}
+
+type Page struct {
+	// newest first, without their traces
+	Shots []Shot
+	// id to pass as the Before of the next page, 0 if this is the last page
+	Next uint64
+}
+
+// Store keeps shots in a bbolt database. It is safe for concurrent use.
+type Store struct {
+	db *bolt.DB
+}
+
+// Open opens the database at the path, creating it if it does not exist
+func Open(path string) (*Store, error) {
+	if path == "" {
+		return nil, errors.New("shot history path is empty")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, errors.Wrap(err, "creating shot history directory")
+	}
+	// fails rather than blocking if another process has the database open
+	db, err := bolt.Open(path, 0644, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, errors.Wrap(err, "opening shot history")
+	}
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(shotsBucket)
+		return err
+	}); err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "creating shot history bucket")
+	}
+	return &Store{db: db}, nil
+}
+
+// key orders the shots by id
+func key(id uint64) []byte {
+	k := make([]byte, 8)
+	binary.BigEndian.PutUint64(k, id)
+	return k
+}
+
+func put(b *bolt.Bucket, shot Shot) error {
+	data, err := json.Marshal(shot)
+	if err != nil {
+		return errors.Wrap(err, "encoding shot")
+	}
+	return b.Put(key(shot.Id), data)
+}
+
+func decode(data []byte) (Shot, error) {
+	var shot Shot
+	if err := json.Unmarshal(data, &shot); err != nil {
+		return Shot{}, errors.Wrap(err, "decoding shot")
+	}
+	return shot, nil
+}
+
+// Add stores the shot with the next id, which it returns the shot with
+func (s *Store) Add(shot Shot) (Shot, error) {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(shotsBucket)
+		id, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		shot.Id = id
+		return put(b, shot)
+	})
+	if err != nil {
+		return Shot{}, errors.Wrap(err, "adding shot")
+	}
+	return shot, nil
+}
+
+func (s *Store) Get(id uint64) (Shot, error) {
+	var shot Shot
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(shotsBucket).Get(key(id))
+		if data == nil {
+			return ErrNotFound
+		}
+		var err error
+		shot, err = decode(data)
+		return err
+	})
+	return shot, err
+}
+
+// UpdateNotes replaces the notes of the shot
+func (s *Store) UpdateNotes(id uint64, notes Notes) (Shot, error) {
+	if err := notes.Validate(); err != nil {
+		return Shot{}, err
+	}
+	var shot Shot
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(shotsBucket)
+		data := b.Get(key(id))
+		if data == nil {
+			return ErrNotFound
+		}
+		var err error
+		if shot, err = decode(data); err != nil {
+			return err
+		}
+		shot.Notes = notes
+		return put(b, shot)
+	})
+	return shot, err
+}
+
+func (s *Store) Delete(id uint64) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(shotsBucket)
+		if b.Get(key(id)) == nil {
+			return ErrNotFound
+		}
+		return b.Delete(key(id))
+	})
+}
+
+// List returns a page of the shots matching the query, newest first
+func (s *Store) List(q Query) (Page, error) {
+	limit := q.Limit
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	if limit > maxLimit {
+		return Page{}, errors.Errorf("limit must be in range [1, %d]", maxLimit)
+	}
+	if err := checkRange(q.From, q.To); err != nil {
+		return Page{}, err
+	}
+
+	page := Page{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(shotsBucket).Cursor()
+		// start from the newest shot below Before
+		var k, data []byte
+		if q.Before == 0 {
+			k, data = c.Last()
+		} else if k, _ = c.Seek(key(q.Before)); k == nil {
+			k, data = c.Last()
+		} else {
+			k, data = c.Prev()
+		}
+		for ; k != nil; k, data = c.Prev() {
+			shot, err := decode(data)
+			if err != nil {
+				return err
+			}
+			if !inRange(shot, q.From, q.To) {
+				continue
+			}
+			if len(page.Shots) == limit {
+				page.Next = page.Shots[limit-1].Id
+				break
+			}
+			page.Shots = append(page.Shots, shot.withoutTraces())
+		}
+		return nil
+	})
+	return page, err
+}
+
+// Range returns the shots started at or after from and before to, oldest
+// first and with their traces. It is unbounded where from or to is zero.
+func (s *Store) Range(from time.Time, to time.Time) ([]Shot, error) {
+	if err := checkRange(from, to); err != nil {
+		return nil, err
+	}
+	var shots []Shot
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(shotsBucket).ForEach(func(k, data []byte) error {
+			shot, err := decode(data)
+			if err != nil {
+				return err
+			}
+			if inRange(shot, from, to) {
+				shots = append(shots, shot)
+			}
+			return nil
+		})
+	})
+	return shots, err
+}
+
+func checkRange(from time.Time, to time.Time) error {
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		return errors.New("the start of the time range must be before its end")
+	}
+	return nil
+}
+
+func inRange(shot Shot, from time.Time, to time.Time) bool {
+	return (from.IsZero() || !shot.Start.Before(from)) && (to.IsZero() || shot.Start.Before(to))
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,182 @@
+package shot_history
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var testStart = time.Date(2026, 10, 19, 7, 0, 0, 0, time.UTC)
+
+func openTestStore(t *testing.T) (*Store, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "espresso", "shots.db")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s, path
+}
+
+// addTestShots adds a shot every hour from testStart
+func addTestShots(t *testing.T, s *Store, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		start := testStart.Add(time.Duration(i) * time.Hour)
+		if _, err := s.Add(Shot{
+			Start:       start,
+			End:         start.Add(27 * time.Second),
+			BoilerTrace: []Point{{At: start, Value: 93}},
+		}); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+}
+
+func ids(shots []Shot) []uint64 {
+	var ids []uint64
+	for _, s := range shots {
+		ids = append(ids, s.Id)
+	}
+	return ids
+}
+
+func TestStore_Add(t *testing.T) {
+	s, path := openTestStore(t)
+
+	shot := Shot{
+		Start:       testStart,
+		End:         testStart.Add(28 * time.Second),
+		Source:      "switch",
+		Setpoint:    93,
+		Strategy:    "pid",
+		Parameters:  map[string]float32{"p": 16, "i": 0.15, "d": 100},
+		Boost:       []BoostStep{{Seconds: 25, Output: 60}},
+		BoilerTrace: []Point{{At: testStart, Value: 93.1}, {At: testStart.Add(time.Second), Value: 92.4}},
+		GroupTrace:  []Point{{At: testStart, Value: 88}},
+	}
+	added, err := s.Add(shot)
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if added.Id != 1 {
+		t.Errorf("Add() id = %d, want 1", added.Id)
+	}
+	shot.Id = 1
+	if got, err := s.Get(1); err != nil || !reflect.DeepEqual(got, shot) {
+		t.Errorf("Get() = %+v, %v, want %+v", got, err, shot)
+	}
+
+	// the shots and their ids survive reopening the store
+	s.Close()
+	s, err = Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer s.Close()
+	if got, err := s.Get(1); err != nil || !reflect.DeepEqual(got, shot) {
+		t.Errorf("Get() after reopening = %+v, %v, want %+v", got, err, shot)
+	}
+	if added, _ := s.Add(shot); added.Id != 2 {
+		t.Errorf("Add() after reopening id = %d, want 2", added.Id)
+	}
+}
+
+func TestStore_UpdateNotes(t *testing.T) {
+	s, _ := openTestStore(t)
+	addTestShots(t, s, 1)
+
+	notes := Notes{Dose: 18, Yield: 36.5, Bean: "Yirgacheffe", Grind: "12", Rating: 4}
+	if got, err := s.UpdateNotes(1, notes); err != nil || got.Notes != notes {
+		t.Errorf("UpdateNotes() = %+v, %v, want notes %+v", got, err, notes)
+	}
+	if got, _ := s.Get(1); got.Notes != notes || len(got.BoilerTrace) != 1 {
+		t.Errorf("Get() after UpdateNotes() = %+v, want the notes and trace", got)
+	}
+
+	if _, err := s.UpdateNotes(1, Notes{Rating: 6}); err == nil {
+		t.Errorf("UpdateNotes() with rating 6 should fail")
+	}
+	if _, err := s.UpdateNotes(1, Notes{Dose: -1}); err == nil {
+		t.Errorf("UpdateNotes() with a negative dose should fail")
+	}
+	if _, err := s.UpdateNotes(2, notes); err != ErrNotFound {
+		t.Errorf("UpdateNotes() of a missing shot error = %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestStore_Delete(t *testing.T) {
+	s, _ := openTestStore(t)
+	addTestShots(t, s, 2)
+
+	if err := s.Delete(1); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := s.Get(1); err != ErrNotFound {
+		t.Errorf("Get() of a deleted shot error = %v, want %v", err, ErrNotFound)
+	}
+	if err := s.Delete(1); err != ErrNotFound {
+		t.Errorf("Delete() of a deleted shot error = %v, want %v", err, ErrNotFound)
+	}
+	if page, _ := s.List(Query{}); !reflect.DeepEqual(ids(page.Shots), []uint64{2}) {
+		t.Errorf("List() after Delete() ids = %v, want [2]", ids(page.Shots))
+	}
+}
+
+func TestStore_List(t *testing.T) {
+	at := func(h int) time.Time { return testStart.Add(time.Duration(h) * time.Hour) }
+	tests := []struct {
+		name     string
+		q        Query
+		wantIds  []uint64
+		wantNext uint64
+		wantErr  bool
+	}{
+		{name: "all", q: Query{}, wantIds: []uint64{5, 4, 3, 2, 1}},
+		{name: "first page", q: Query{Limit: 2}, wantIds: []uint64{5, 4}, wantNext: 4},
+		{name: "second page", q: Query{Limit: 2, Before: 4}, wantIds: []uint64{3, 2}, wantNext: 2},
+		{name: "last page", q: Query{Limit: 2, Before: 2}, wantIds: []uint64{1}},
+		{name: "before a missing id", q: Query{Before: 10}, wantIds: []uint64{5, 4, 3, 2, 1}},
+		{name: "time range", q: Query{From: at(1), To: at(3)}, wantIds: []uint64{3, 2}},
+		{name: "from", q: Query{From: at(3)}, wantIds: []uint64{5, 4}},
+		{name: "inverted range", q: Query{From: at(3), To: at(1)}, wantErr: true},
+		{name: "limit too high", q: Query{Limit: maxLimit + 1}, wantErr: true},
+	}
+
+	s, _ := openTestStore(t)
+	addTestShots(t, s, 5)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := s.List(tt.q)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("List() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(ids(page.Shots), tt.wantIds) || page.Next != tt.wantNext {
+				t.Errorf("List() = %v next %d, want %v next %d", ids(page.Shots), page.Next, tt.wantIds, tt.wantNext)
+			}
+			for _, shot := range page.Shots {
+				if shot.BoilerTrace != nil {
+					t.Errorf("List() should leave out the traces")
+				}
+			}
+		})
+	}
+}
+
+func TestStore_Range(t *testing.T) {
+	s, _ := openTestStore(t)
+	addTestShots(t, s, 3)
+
+	shots, err := s.Range(testStart.Add(time.Hour), time.Time{})
+	if err != nil {
+		t.Fatalf("Range() error = %v", err)
+	}
+	if !reflect.DeepEqual(ids(shots), []uint64{2, 3}) {
+		t.Errorf("Range() ids = %v, want [2 3]", ids(shots))
+	}
+	if len(shots[0].BoilerTrace) != 1 {
+		t.Errorf("Range() should include the traces")
+	}
+}
@@ -0,0 +1,164 @@
+// Package simulation models a single boiler espresso machine, e.g. a Rancilio
+// Silvia, so that the controller can be developed, tuned and demoed without
+// real hardware.
+package simulation
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/luiccn/espresso-controller/internal/espresso/temperature"
+)
+
+const (
+	// specific heat of water, J/(g*K)
+	waterSpecificHeat float64 = 4.186
+
+	stepInterval = 100 * time.Millisecond
+)
+
+// Parameters of the simulated machine. The defaults in main.go approximate a
+// Rancilio Silvia.
+type Parameters struct {
+	// Power of the heating element at 100% duty, W
+	HeaterWatts float64
+	// Time constant of the heating element transferring heat to the water, s
+	HeaterLagSeconds float64
+	// Heat capacity of the boiler, its water and the group, J/K
+	ThermalMass float64
+	// Heat lost to the surroundings per degree above ambient, W/K
+	AmbientLoss float64
+	// Temperature of the surroundings, °C
+	AmbientTemperature float64
+	// Time constant of the sensor following the water temperature, s
+	SensorLagSeconds float64
+	// Standard deviation of the sensor's noise, °C
+	SensorNoise float64
+	// Rate at which cold water replaces boiler water while brewing, ml/s
+	BrewFlowRate float64
+	// Temperature of the water entering the boiler while brewing, °C
+	InletTemperature float64
+}
+
+// DutyFactorSource is the heating element driving the simulation, i.e.
+// *heating_element.HeatingElement.
+type DutyFactorSource interface {
+	AppliedDutyFactor() float32
+}
+
+type Status struct {
+	WaterTemperature  float64
+	SensorTemperature float64
+	HeatFlowWatts     float64
+	Brewing           bool
+}
+
+// Boiler is a lumped thermal model of the boiler. The heating element's
+// output reaches the water with a first order lag, the water loses heat to
+// the surroundings and to the cold water drawn in while brewing, and the
+// sensor lags the water temperature. Boiler implements temperature.Sampler.
+type Boiler struct {
+	p      Parameters
+	heater DutyFactorSource
+
+	mu                sync.Mutex
+	waterTemperature  float64
+	sensorTemperature float64
+	heatFlow          float64
+	brewing           bool
+	rand              *rand.Rand
+
+	shutdownCh chan struct{}
+}
+
+// NewBoiler creates a boiler at ambient temperature
+func NewBoiler(p Parameters, heater DutyFactorSource) *Boiler {
+	return &Boiler{
+		p:                 p,
+		heater:            heater,
+		waterTemperature:  p.AmbientTemperature,
+		sensorTemperature: p.AmbientTemperature,
+		rand:              rand.New(rand.NewSource(time.Now().UnixNano())),
+		shutdownCh:        make(chan struct{}),
+	}
+}
+
+// Run advances the simulation in real time
+func (b *Boiler) Run() {
+	go func() {
+		ticker := time.NewTicker(stepInterval)
+		defer ticker.Stop()
+		last := time.Now()
+		for {
+			select {
+			case <-b.shutdownCh:
+				return
+			case now := <-ticker.C:
+				b.Step(now.Sub(last))
+				last = now
+			}
+		}
+	}()
+}
+
+// Step advances the simulation by dt
+func (b *Boiler) Step(dt time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := dt.Seconds()
+	duty := math.Max(0, math.Min(1, float64(b.heater.AppliedDutyFactor())))
+	b.heatFlow += lag(s, b.p.HeaterLagSeconds) * (duty*b.p.HeaterWatts - b.heatFlow)
+
+	loss := b.p.AmbientLoss * (b.waterTemperature - b.p.AmbientTemperature)
+	if b.brewing {
+		loss += b.p.BrewFlowRate * waterSpecificHeat * (b.waterTemperature - b.p.InletTemperature)
+	}
+	if b.p.ThermalMass > 0 {
+		b.waterTemperature += s * (b.heatFlow - loss) / b.p.ThermalMass
+	}
+
+	b.sensorTemperature += lag(s, b.p.SensorLagSeconds) * (b.waterTemperature - b.sensorTemperature)
+}
+
+// lag is the fraction of the remaining distance a first order system with
+// time constant tau covers in dt seconds
+func lag(dt float64, tau float64) float64 {
+	if tau <= 0 {
+		return 1
+	}
+	return 1 - math.Exp(-dt/tau)
+}
+
+func (b *Boiler) Sample() (*temperature.Sample, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return &temperature.Sample{
+		Value:      float32(b.sensorTemperature + b.rand.NormFloat64()*b.p.SensorNoise),
+		ObservedAt: time.Now(),
+	}, nil
+}
+
+// SetBrewing starts or stops drawing water through the group
+func (b *Boiler) SetBrewing(brewing bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.brewing = brewing
+}
+
+func (b *Boiler) GetStatus() Status {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return Status{
+		WaterTemperature:  b.waterTemperature,
+		SensorTemperature: b.sensorTemperature,
+		HeatFlowWatts:     b.heatFlow,
+		Brewing:           b.brewing,
+	}
+}
+
+func (b *Boiler) Shutdown() {
+	close(b.shutdownCh)
+}
@@ -0,0 +1,105 @@
+package simulation
+
+import (
+	"testing"
+	"time"
+)
+
+type constantDuty float32
+
+func (d constantDuty) AppliedDutyFactor() float32 {
+	return float32(d)
+}
+
+var testParameters = Parameters{
+	HeaterWatts:        1100,
+	HeaterLagSeconds:   8,
+	ThermalMass:        2000,
+	AmbientLoss:        1,
+	AmbientTemperature: 22,
+	SensorLagSeconds:   4,
+	BrewFlowRate:       2.5,
+	InletTemperature:   20,
+}
+
+func run(b *Boiler, d time.Duration) {
+	for elapsed := time.Duration(0); elapsed < d; elapsed += stepInterval {
+		b.Step(stepInterval)
+	}
+}
+
+func TestBoiler_Step(t *testing.T) {
+	tests := []struct {
+		name     string
+		duty     float32
+		brewing  bool
+		initial  float64
+		duration time.Duration
+		wantMin  float64
+		wantMax  float64
+	}{
+		{
+			name:     "Idle boiler stays at ambient",
+			duty:     0,
+			initial:  22,
+			duration: 10 * time.Minute,
+			wantMin:  21.9,
+			wantMax:  22.1,
+		},
+		{
+			name:     "Full power heats to brew temperature within a few minutes",
+			duty:     1,
+			initial:  22,
+			duration: 3 * time.Minute,
+			wantMin:  93,
+			wantMax:  120,
+		},
+		{
+			name:     "Unheated boiler cools towards ambient",
+			duty:     0,
+			initial:  95,
+			duration: 10 * time.Minute,
+			wantMin:  70,
+			wantMax:  90,
+		},
+		{
+			name:     "Brewing without heating drops the temperature by several degrees",
+			duty:     0,
+			brewing:  true,
+			initial:  95,
+			duration: 25 * time.Second,
+			wantMin:  80,
+			wantMax:  92,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBoiler(testParameters, constantDuty(tt.duty))
+			b.waterTemperature = tt.initial
+			b.sensorTemperature = tt.initial
+			b.SetBrewing(tt.brewing)
+			run(b, tt.duration)
+			if got := b.GetStatus().WaterTemperature; got < tt.wantMin || got > tt.wantMax {
+				t.Errorf("water temperature = %v, want in [%v, %v]", got, tt.wantMin, tt.wantMax)
+			}
+		})
+	}
+}
+
+func TestBoiler_SensorLagsWater(t *testing.T) {
+	b := NewBoiler(testParameters, constantDuty(1))
+	run(b, time.Minute)
+
+	status := b.GetStatus()
+	if status.SensorTemperature >= status.WaterTemperature {
+		t.Errorf("sensor temperature %v should lag water temperature %v while heating", status.SensorTemperature, status.WaterTemperature)
+	}
+
+	sample, err := b.Sample()
+	if err != nil {
+		t.Fatalf("Sample() error = %v", err)
+	}
+	if float64(sample.Value) != float64(float32(status.SensorTemperature)) {
+		t.Errorf("Sample() = %v, want noiseless sensor temperature %v", sample.Value, status.SensorTemperature)
+	}
+}
@@ -0,0 +1,112 @@
+package spi
+
+import (
+	"errors"
+
+	"github.com/luiccn/espresso-controller/internal/espresso/gpio"
+)
+
+// Bitbang clocks the bus by toggling GPIO pins. It needs no kernel support
+// but is slow and sensitive to the scheduler on a busy Pi.
+type Bitbang struct {
+	mode Mode
+
+	csPin   gpio.Pin
+	clkPin  gpio.Pin
+	misoPin gpio.Pin
+	mosiPin gpio.Pin
+}
+
+func NewBitbang(driver gpio.Driver, cs int, clk int, miso int, mosi int, mode Mode) *Bitbang {
+	b := &Bitbang{
+		mode:    mode,
+		csPin:   driver.Pin(cs),
+		clkPin:  driver.Pin(clk),
+		misoPin: driver.Pin(miso),
+		mosiPin: driver.Pin(mosi),
+	}
+
+	b.csPin.Output()
+	b.csPin.High()
+
+	b.clkPin.Output()
+	b.idleClock()
+
+	b.misoPin.Input()
+	b.mosiPin.Output()
+
+	return b
+}
+
+func (b *Bitbang) Tx(w []byte, r []byte) error {
+	if r != nil && len(r) != len(w) {
+		return errors.New("read and write buffers must be of the same length")
+	}
+
+	b.idleClock()
+	b.csPin.Low()
+	for n := range w {
+		v := b.transfer8(w[n])
+		if r != nil {
+			r[n] = v
+		}
+	}
+	b.csPin.High()
+	return nil
+}
+
+func (b *Bitbang) Close() error {
+	return nil
+}
+
+func (b *Bitbang) idleClock() {
+	if b.mode.cpol() {
+		b.clkPin.High()
+	} else {
+		b.clkPin.Low()
+	}
+}
+
+func (b *Bitbang) activeClock() {
+	if b.mode.cpol() {
+		b.clkPin.Low()
+	} else {
+		b.clkPin.High()
+	}
+}
+
+func (b *Bitbang) writeBit(bit bool) {
+	if bit {
+		b.mosiPin.High()
+	} else {
+		b.mosiPin.Low()
+	}
+}
+
+// transfer8 shifts a byte out and in, most significant bit first. Without
+// clock phase the data is set before the leading edge and sampled on it,
+// with clock phase it is set on the leading and sampled on the trailing edge.
+func (b *Bitbang) transfer8(v uint8) uint8 {
+	var reply uint8 = 0
+	for i := 7; i >= 0; i-- {
+		reply <<= 1
+		bit := v&(1<<uint(i)) != 0
+
+		if b.mode.cpha() {
+			b.activeClock()
+			b.writeBit(bit)
+			b.idleClock()
+			if b.misoPin.Read() == gpio.High {
+				reply |= 1
+			}
+		} else {
+			b.writeBit(bit)
+			b.activeClock()
+			if b.misoPin.Read() == gpio.High {
+				reply |= 1
+			}
+			b.idleClock()
+		}
+	}
+	return reply
+}
@@ -0,0 +1,76 @@
+package spi
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/luiccn/espresso-controller/internal/espresso/gpio"
+)
+
+const (
+	testCsPin   = 5
+	testClkPin  = 11
+	testDataPin = 9
+)
+
+func TestBitbang_Tx(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     Mode
+		idleHigh bool
+	}{
+		{name: "mode 0", mode: Mode0},
+		{name: "mode 1", mode: Mode1},
+		{name: "mode 2", mode: Mode2, idleHigh: true},
+		{name: "mode 3", mode: Mode3, idleHigh: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver := gpio.NewFakeDriver()
+			// MISO wired to MOSI, so every byte written is read back
+			b := NewBitbang(driver, testCsPin, testClkPin, testDataPin, testDataPin, tt.mode)
+
+			w := []byte{0x00, 0xA5, 0xFF, 0x3C}
+			r := make([]byte, len(w))
+			if err := b.Tx(w, r); err != nil {
+				t.Fatalf("Tx() error = %v", err)
+			}
+			if !bytes.Equal(r, w) {
+				t.Errorf("Tx() read %x, want %x", r, w)
+			}
+
+			if driver.FakePin(testCsPin).Read() != gpio.High {
+				t.Errorf("chip select not released after Tx()")
+			}
+			if clk := driver.FakePin(testClkPin).Read(); (clk == gpio.High) != tt.idleHigh {
+				t.Errorf("clock idles %s after Tx()", clk)
+			}
+		})
+	}
+}
+
+func TestNewBus(t *testing.T) {
+	tests := []struct {
+		name    string
+		c       Config
+		wantErr bool
+	}{
+		{name: "bitbang", c: Config{Transport: TransportBitbang}},
+		{name: "default to bitbang", c: Config{}},
+		{name: "missing spidev", c: Config{Transport: TransportSpidev, Device: "/nonexistent/spidev0.0"}, wantErr: true},
+		{name: "unknown transport", c: Config{Transport: "i2c"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus, err := NewBus(gpio.NewFakeDriver(), tt.c)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewBus() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && bus == nil {
+				t.Errorf("NewBus() returned no bus")
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package spi
+
+import (
+	"errors"
+	"sync"
+)
+
+// FakeBus is an in-memory Bus. It records the bytes written in every
+// transaction and replies with whatever its respond function returns for
+// them, e.g. to emulate a sensor's registers in tests.
+type FakeBus struct {
+	mu      sync.Mutex
+	respond func(w []byte) []byte
+	txs     [][]byte
+	closed  bool
+}
+
+// NewFakeBus returns a bus replying with respond, which may be nil to reply
+// with zeros
+func NewFakeBus(respond func(w []byte) []byte) *FakeBus {
+	return &FakeBus{respond: respond}
+}
+
+func (b *FakeBus) Tx(w []byte, r []byte) error {
+	if r != nil && len(r) != len(w) {
+		return errors.New("read and write buffers must be of the same length")
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return errors.New("bus is closed")
+	}
+	b.txs = append(b.txs, append([]byte(nil), w...))
+
+	if b.respond == nil {
+		return nil
+	}
+	reply := b.respond(w)
+	if r != nil {
+		copy(r, reply)
+	}
+	return nil
+}
+
+func (b *FakeBus) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	return nil
+}
+
+// Txs returns the bytes written in each transaction so far
+func (b *FakeBus) Txs() [][]byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([][]byte(nil), b.txs...)
+}
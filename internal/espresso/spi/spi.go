@@ -0,0 +1,77 @@
+// Package spi abstracts the SPI bus a sensor is connected to, so that sensors
+// can be read by bit-banging GPIO pins, through the kernel's spidev interface
+// or from an in-memory fake in unit tests.
+package spi
+
+import (
+	"fmt"
+
+	"github.com/luiccn/espresso-controller/internal/espresso/gpio"
+)
+
+const (
+	// TransportBitbang toggles GPIO pins to clock the bus.
+	TransportBitbang = "bitbang"
+	// TransportSpidev uses the SPI controller through /dev/spidevX.Y.
+	TransportSpidev = "spidev"
+)
+
+// Mode is the SPI mode, i.e. clock polarity and phase.
+type Mode uint8
+
+const (
+	// Mode0 idles the clock low and samples on the rising edge
+	Mode0 Mode = 0
+	// Mode1 idles the clock low and samples on the falling edge
+	Mode1 Mode = 1
+	// Mode2 idles the clock high and samples on the falling edge
+	Mode2 Mode = 2
+	// Mode3 idles the clock high and samples on the rising edge
+	Mode3 Mode = 3
+)
+
+func (m Mode) cpol() bool {
+	return m&2 != 0
+}
+
+func (m Mode) cpha() bool {
+	return m&1 != 0
+}
+
+// Bus is a SPI bus with a single device on it.
+type Bus interface {
+	// Tx asserts chip select and writes w while reading into r. Both must be
+	// of the same length, r may be nil if the reply is not needed.
+	Tx(w []byte, r []byte) error
+	Close() error
+}
+
+type Config struct {
+	// TransportBitbang or TransportSpidev
+	Transport string
+	Mode      Mode
+	// GPIO pins of the bus when bit-banging
+	CsPin   int
+	ClkPin  int
+	MisoPin int
+	MosiPin int
+	// Device node and clock speed when using spidev, e.g. /dev/spidev0.0
+	Device  string
+	SpeedHz uint32
+}
+
+// NewBus opens the bus for the configured transport.
+func NewBus(driver gpio.Driver, c Config) (Bus, error) {
+	switch c.Transport {
+	case TransportBitbang, "":
+		return NewBitbang(driver, c.CsPin, c.ClkPin, c.MisoPin, c.MosiPin, c.Mode), nil
+	case TransportSpidev:
+		s, err := OpenSpidev(c.Device, c.Mode, c.SpeedHz)
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	default:
+		return nil, fmt.Errorf("unknown spi transport %q, expected one of [%s, %s]", c.Transport, TransportBitbang, TransportSpidev)
+	}
+}
@@ -0,0 +1,113 @@
+//go:build linux
+// +build linux
+
+package spi
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	spiIOCMagic = 'k'
+	// size of struct spi_ioc_transfer
+	spiIOCTransferSize = 32
+)
+
+// spiIOCTransfer is struct spi_ioc_transfer from linux/spi/spidev.h
+type spiIOCTransfer struct {
+	txBuf          uint64
+	rxBuf          uint64
+	len            uint32
+	speedHz        uint32
+	delayUsecs     uint16
+	bitsPerWord    uint8
+	csChange       uint8
+	txNbits        uint8
+	rxNbits        uint8
+	wordDelayUsecs uint8
+	pad            uint8
+}
+
+// iow is the _IOW ioctl request number
+func iow(nr uintptr, size uintptr) uintptr {
+	return 1<<30 | size<<16 | spiIOCMagic<<8 | nr
+}
+
+var (
+	spiIOCWrMode        = iow(1, 1)
+	spiIOCWrBitsPerWord = iow(3, 1)
+	spiIOCWrMaxSpeedHz  = iow(4, 4)
+	spiIOCMessage1      = iow(0, spiIOCTransferSize)
+)
+
+// Spidev uses the SPI controller through the kernel's spidev interface, which
+// must be enabled, e.g. with dtparam=spi=on on a Raspberry Pi. The chip select
+// is driven by the controller.
+type Spidev struct {
+	f       *os.File
+	speedHz uint32
+}
+
+// OpenSpidev opens the device node, e.g. /dev/spidev0.0, and configures the
+// mode and clock speed
+func OpenSpidev(device string, mode Mode, speedHz uint32) (*Spidev, error) {
+	f, err := os.OpenFile(device, os.O_RDWR, 0)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening spidev")
+	}
+	s := &Spidev{f: f, speedHz: speedHz}
+
+	m := uint8(mode)
+	if err := s.ioctl(spiIOCWrMode, unsafe.Pointer(&m)); err != nil {
+		f.Close()
+		return nil, errors.Wrap(err, "setting spi mode")
+	}
+	bits := uint8(8)
+	if err := s.ioctl(spiIOCWrBitsPerWord, unsafe.Pointer(&bits)); err != nil {
+		f.Close()
+		return nil, errors.Wrap(err, "setting spi bits per word")
+	}
+	if speedHz > 0 {
+		if err := s.ioctl(spiIOCWrMaxSpeedHz, unsafe.Pointer(&speedHz)); err != nil {
+			f.Close()
+			return nil, errors.Wrap(err, "setting spi speed")
+		}
+	}
+	return s, nil
+}
+
+func (s *Spidev) Tx(w []byte, r []byte) error {
+	if len(w) == 0 {
+		return nil
+	}
+	if r == nil {
+		r = make([]byte, len(w))
+	}
+	if len(r) != len(w) {
+		return errors.New("read and write buffers must be of the same length")
+	}
+
+	tr := spiIOCTransfer{
+		txBuf:       uint64(uintptr(unsafe.Pointer(&w[0]))),
+		rxBuf:       uint64(uintptr(unsafe.Pointer(&r[0]))),
+		len:         uint32(len(w)),
+		speedHz:     s.speedHz,
+		bitsPerWord: 8,
+	}
+	return errors.Wrap(s.ioctl(spiIOCMessage1, unsafe.Pointer(&tr)), "spi transfer")
+}
+
+func (s *Spidev) Close() error {
+	return s.f.Close()
+}
+
+func (s *Spidev) ioctl(req uintptr, arg unsafe.Pointer) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, s.f.Fd(), req, uintptr(arg)); errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+//go:build !linux
+// +build !linux
+
+package spi
+
+import (
+	"errors"
+)
+
+// Spidev is only available on linux
+type Spidev struct{}
+
+func OpenSpidev(device string, mode Mode, speedHz uint32) (*Spidev, error) {
+	return nil, errors.New("spidev is only supported on linux")
+}
+
+func (s *Spidev) Tx(w []byte, r []byte) error {
+	return errors.New("spidev is only supported on linux")
+}
+
+func (s *Spidev) Close() error {
+	return nil
+}
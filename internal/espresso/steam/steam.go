@@ -0,0 +1,338 @@
+// Package steam switches the machine between brewing and steaming, which heats
+// the boiler to a higher setpoint, from the API, the power button or a steam
+// switch.
+package steam
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/luiccn/espresso-controller/internal/espresso/button"
+	"github.com/luiccn/espresso-controller/internal/espresso/gpio"
+	"github.com/luiccn/espresso-controller/internal/log"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const (
+	subscriptionBufferSize = 10
+	pollInterval           = 50 * time.Millisecond
+
+	minTemperature = 100
+	maxTemperature = 150
+	// how far below the safety cut-off the steam temperature must be, for the
+	// overshoot of the pid strategy reaching it
+	cutOffHeadroom = 5
+)
+
+type Mode string
+
+const (
+	ModeBrew  Mode = "brew"
+	ModeSteam Mode = "steam"
+)
+
+func ParseMode(s string) (Mode, error) {
+	switch m := Mode(s); m {
+	case ModeBrew, ModeSteam:
+		return m, nil
+	default:
+		return "", errors.Errorf("unknown mode %q, expected %s or %s", s, ModeBrew, ModeSteam)
+	}
+}
+
+// Reason is why the mode changed
+type Reason string
+
+const (
+	ReasonStartup     Reason = "startup"
+	ReasonUser        Reason = "user"
+	ReasonPowerButton Reason = "power_button"
+	ReasonSwitch      Reason = "steam_switch"
+	ReasonTimeout     Reason = "timeout"
+	ReasonPowerOff    Reason = "power_off"
+)
+
+// ErrPoweredOff is returned when steaming while the machine is powered off
+var ErrPoweredOff = errors.New("the machine is powered off")
+
+// Config of steam mode. The setpoint and gains are those of the boiler while
+// steaming, the brew ones being kept by the temperature control strategy.
+type Config struct {
+	// Boiler temperature in °C while steaming
+	Temperature float32
+	// Gains of the pid strategy while steaming
+	P float32
+	I float32
+	D float32
+	// Time after which steam mode reverts to brew mode, never if 0
+	Timeout time.Duration
+	// GPIO connected to the steam switch, high while steaming. There is none
+	// if negative.
+	SwitchPin int
+}
+
+func (c Config) Validate() error {
+	if c.Temperature < minTemperature || c.Temperature > maxTemperature {
+		return errors.Errorf("steam temperature must be in range [%d, %d] °C", minTemperature, maxTemperature)
+	}
+	if c.P < 0 || c.I < 0 || c.D < 0 {
+		return errors.New("steam pid gains must be >= 0")
+	}
+	if c.Timeout < 0 {
+		return errors.New("steam timeout must be >= 0")
+	}
+	return nil
+}
+
+// Status is the current mode and why it was entered
+type Status struct {
+	Mode   Mode
+	Reason Reason
+	Since  time.Time
+	// when steam mode reverts to brew mode, zero if it does not
+	RevertAt time.Time
+}
+
+// PowerSource reports whether the machine is powered on
+type PowerSource interface {
+	IsMachinePowerOn() bool
+}
+
+// Selector keeps the mode, reverting to brew mode after the timeout or when
+// the machine is powered off, and publishes its changes to its subscribers
+type Selector struct {
+	switchPin gpio.Pin
+	// last read and debounced state of the switch, only used by Run
+	lastRead    gpio.State
+	switchState gpio.State
+
+	// safety cut-off in °C the steam temperature must stay below
+	cutOff float64
+
+	mu                sync.Mutex
+	c                 Config
+	status            Status
+	powerSource       PowerSource
+	subscriptionChans map[uuid.UUID]chan Status
+	// the clock, replaced in tests
+	now func() time.Time
+
+	shutdownCh chan struct{}
+}
+
+// NewSelector starts in brew mode. The steam switch is watched if
+// c.SwitchPin is not negative. The steam temperature must leave headroom below
+// the safety cut-off, see safety.Limits.MaxTemperature.
+func NewSelector(c Config, cutOff float64, driver gpio.Driver) (*Selector, error) {
+	s := &Selector{
+		cutOff:            cutOff,
+		c:                 c,
+		status:            Status{Mode: ModeBrew, Reason: ReasonStartup, Since: time.Now()},
+		subscriptionChans: map[uuid.UUID]chan Status{},
+		now:               time.Now,
+		shutdownCh:        make(chan struct{}),
+	}
+	if err := s.Validate(c); err != nil {
+		return nil, err
+	}
+	if c.SwitchPin >= 0 {
+		s.switchPin = driver.Pin(c.SwitchPin)
+		s.switchPin.Input()
+		s.switchPin.PullDown()
+	}
+	return s, nil
+}
+
+// Run polls the steam switch and reverts to brew mode when needed
+func (s *Selector) Run() {
+	if s.switchPin != nil {
+		s.lastRead = s.switchPin.Read()
+		s.switchState = s.lastRead
+	}
+	go func() {
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.shutdownCh:
+				return
+			case <-ticker.C:
+			}
+
+			if s.switchPin != nil {
+				s.pollSwitch()
+			}
+			s.mu.Lock()
+			s.revertBehaviour()
+			s.mu.Unlock()
+		}
+	}()
+}
+
+// pollSwitch follows the steam switch, debouncing it by requiring two equal
+// reads in a row. Only turning the switch on or off changes the mode, so that
+// a switch left on does not start steaming on startup or again after the
+// timeout.
+func (s *Selector) pollSwitch() {
+	state := s.switchPin.Read()
+	if state == s.lastRead && state != s.switchState {
+		s.switchState = state
+		mode := ModeBrew
+		if state == gpio.High {
+			mode = ModeSteam
+		}
+		if err := s.SetMode(mode, ReasonSwitch); err != nil {
+			log.Info("Ignoring steam switch", zap.Error(err))
+		}
+	}
+	s.lastRead = state
+}
+
+// revertBehaviour reverts to brew mode once steaming timed out or the machine
+// was powered off. mu must be held.
+func (s *Selector) revertBehaviour() {
+	if s.status.Mode != ModeSteam {
+		return
+	}
+	if !s.poweredOn() {
+		s.setMode(ModeBrew, ReasonPowerOff)
+	} else if !s.status.RevertAt.IsZero() && !s.now().Before(s.status.RevertAt) {
+		s.setMode(ModeBrew, ReasonTimeout)
+	}
+}
+
+// poweredOn is true without a power source. mu must be held.
+func (s *Selector) poweredOn() bool {
+	return s.powerSource == nil || s.powerSource.IsMachinePowerOn()
+}
+
+// SetMode switches to the mode, failing to steam while the machine is powered
+// off. Steaming again restarts the timeout.
+func (s *Selector) SetMode(mode Mode, reason Reason) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if mode == ModeSteam && !s.poweredOn() {
+		return ErrPoweredOff
+	}
+	s.setMode(mode, reason)
+	return nil
+}
+
+// Toggle switches between brewing and steaming
+func (s *Selector) Toggle(reason Reason) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	mode := ModeSteam
+	if s.status.Mode == ModeSteam {
+		mode = ModeBrew
+	} else if !s.poweredOn() {
+		return ErrPoweredOff
+	}
+	s.setMode(mode, reason)
+	return nil
+}
+
+// setMode publishes the new status. mu must be held.
+func (s *Selector) setMode(mode Mode, reason Reason) {
+	if mode == s.status.Mode && mode == ModeBrew {
+		return
+	}
+	now := s.now()
+	s.status = Status{Mode: mode, Reason: reason, Since: now}
+	if mode == ModeSteam && s.c.Timeout > 0 {
+		s.status.RevertAt = now.Add(s.c.Timeout)
+	}
+	log.Info("Switched mode", zap.String("mode", string(mode)), zap.String("reason", string(reason)))
+	for subId, ch := range s.subscriptionChans {
+		select {
+		case ch <- s.status:
+		default:
+			log.Debug("Dropped mode status for slow subscriber", zap.Stringer("subId", subId))
+		}
+	}
+}
+
+func (s *Selector) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status
+}
+
+// Validate checks the configuration, including that the steam temperature
+// leaves headroom below the safety cut-off
+func (s *Selector) Validate(c Config) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	if limit := s.cutOff - cutOffHeadroom; float64(c.Temperature) > limit {
+		return errors.Errorf("steam temperature must be at most %.1f °C, %d °C below the safety cut-off of %.1f °C", limit, cutOffHeadroom, s.cutOff)
+	}
+	return nil
+}
+
+// IsSteaming reports whether the machine is in steam mode
+func (s *Selector) IsSteaming() bool {
+	return s.Status().Mode == ModeSteam
+}
+
+func (s *Selector) Config() Config {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.c
+}
+
+// SetConfig validates and applies the configuration, except for the switch
+// pin which cannot be changed. A new timeout applies from the next time steam
+// mode is entered.
+func (s *Selector) SetConfig(c Config) error {
+	if err := s.Validate(c); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c.SwitchPin = s.c.SwitchPin
+	s.c = c
+	return nil
+}
+
+// WatchPower reverts to brew mode when the machine is powered off
+func (s *Selector) WatchPower(source PowerSource) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.powerSource = source
+}
+
+// HandleButton toggles steam mode on the power button's toggle_steam gesture
+func (s *Selector) HandleButton(b *button.Button) {
+	b.Handle(button.ActionToggleSteam, func() {
+		if err := s.Toggle(ReasonPowerButton); err != nil {
+			log.Info("Ignoring steam button", zap.Error(err))
+		}
+	})
+}
+
+// Subscribe returns a channel receiving the status after every change of mode
+func (s *Selector) Subscribe() (uuid.UUID, chan Status) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subId := uuid.New()
+	ch := make(chan Status, subscriptionBufferSize)
+	s.subscriptionChans[subId] = ch
+	return subId, ch
+}
+
+// Unsubscribe stops publishing to the subscription and closes its channel
+func (s *Selector) Unsubscribe(subId uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if ch, ok := s.subscriptionChans[subId]; ok {
+		delete(s.subscriptionChans, subId)
+		close(ch)
+	}
+}
+
+func (s *Selector) Shutdown() {
+	close(s.shutdownCh)
+}
@@ -0,0 +1,230 @@
+package steam
+
+import (
+	"testing"
+	"time"
+
+	"github.com/luiccn/espresso-controller/internal/espresso/gpio"
+)
+
+const (
+	testSwitchPin = 22
+	testCutOff    = 160
+)
+
+var testConfig = Config{Temperature: 140, P: 16, I: 0.15, D: 100, Timeout: 10 * time.Minute, SwitchPin: -1}
+
+type fakePowerSource struct {
+	on bool
+}
+
+func (f *fakePowerSource) IsMachinePowerOn() bool {
+	return f.on
+}
+
+func newTestSelector(t *testing.T, c Config) (*Selector, *gpio.FakeDriver, *fakePowerSource, *time.Time) {
+	t.Helper()
+	driver := gpio.NewFakeDriver()
+	s, err := NewSelector(c, testCutOff, driver)
+	if err != nil {
+		t.Fatalf("NewSelector() error = %v", err)
+	}
+	now := time.Date(2026, 10, 19, 7, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	power := &fakePowerSource{on: true}
+	s.WatchPower(power)
+	return s, driver, power, &now
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(c *Config)
+		wantErr bool
+	}{
+		{name: "valid", change: func(c *Config) {}},
+		{name: "no timeout", change: func(c *Config) { c.Timeout = 0 }},
+		{name: "too cold", change: func(c *Config) { c.Temperature = 95 }, wantErr: true},
+		{name: "too hot", change: func(c *Config) { c.Temperature = 155 }, wantErr: true},
+		{name: "negative gain", change: func(c *Config) { c.I = -1 }, wantErr: true},
+		{name: "negative timeout", change: func(c *Config) { c.Timeout = -time.Second }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := testConfig
+			tt.change(&c)
+			if err := c.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSelector_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		temperature float32
+		cutOff      float64
+		wantErr     bool
+	}{
+		{name: "below the cut-off", temperature: 140, cutOff: 160},
+		{name: "at the headroom", temperature: 135, cutOff: 140},
+		{name: "within the headroom", temperature: 137, cutOff: 140, wantErr: true},
+		{name: "at the cut-off", temperature: 140, cutOff: 140, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := testConfig
+			c.Temperature = tt.temperature
+			_, err := NewSelector(c, tt.cutOff, gpio.NewFakeDriver())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewSelector() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			s, _, _, _ := newTestSelector(t, testConfig)
+			s.cutOff = tt.cutOff
+			if err := s.SetConfig(c); (err != nil) != tt.wantErr {
+				t.Errorf("SetConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSelector_SetMode(t *testing.T) {
+	s, _, power, now := newTestSelector(t, testConfig)
+	if status := s.Status(); status.Mode != ModeBrew || status.Reason != ReasonStartup {
+		t.Fatalf("status = %+v, want brew mode on startup", status)
+	}
+
+	if err := s.SetMode(ModeSteam, ReasonUser); err != nil {
+		t.Fatalf("SetMode(steam) error = %v", err)
+	}
+	want := Status{Mode: ModeSteam, Reason: ReasonUser, Since: *now, RevertAt: now.Add(10 * time.Minute)}
+	if status := s.Status(); status != want {
+		t.Errorf("status = %+v, want %+v", status, want)
+	}
+
+	if err := s.Toggle(ReasonPowerButton); err != nil || s.Status().Mode != ModeBrew {
+		t.Errorf("Toggle() while steaming should switch to brew mode, error = %v", err)
+	}
+
+	power.on = false
+	if err := s.SetMode(ModeSteam, ReasonUser); err != ErrPoweredOff {
+		t.Errorf("SetMode(steam) while powered off error = %v, want %v", err, ErrPoweredOff)
+	}
+	if err := s.Toggle(ReasonPowerButton); err != ErrPoweredOff {
+		t.Errorf("Toggle() while powered off error = %v, want %v", err, ErrPoweredOff)
+	}
+	if s.Status().Mode != ModeBrew {
+		t.Errorf("mode = %s, want brew mode while powered off", s.Status().Mode)
+	}
+}
+
+func TestSelector_revertBehaviour(t *testing.T) {
+	tests := []struct {
+		name       string
+		timeout    time.Duration
+		elapsed    time.Duration
+		powerOff   bool
+		wantMode   Mode
+		wantReason Reason
+	}{
+		{name: "before the timeout", timeout: time.Minute, elapsed: 59 * time.Second, wantMode: ModeSteam, wantReason: ReasonUser},
+		{name: "timed out", timeout: time.Minute, elapsed: time.Minute, wantMode: ModeBrew, wantReason: ReasonTimeout},
+		{name: "no timeout", elapsed: 24 * time.Hour, wantMode: ModeSteam, wantReason: ReasonUser},
+		{name: "powered off", timeout: time.Minute, powerOff: true, wantMode: ModeBrew, wantReason: ReasonPowerOff},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := testConfig
+			c.Timeout = tt.timeout
+			s, _, power, now := newTestSelector(t, c)
+			if err := s.SetMode(ModeSteam, ReasonUser); err != nil {
+				t.Fatalf("SetMode(steam) error = %v", err)
+			}
+
+			*now = now.Add(tt.elapsed)
+			power.on = !tt.powerOff
+			s.mu.Lock()
+			s.revertBehaviour()
+			s.mu.Unlock()
+			if status := s.Status(); status.Mode != tt.wantMode || status.Reason != tt.wantReason {
+				t.Errorf("status = %+v, want %s mode because of %s", status, tt.wantMode, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestSelector_pollSwitch(t *testing.T) {
+	c := testConfig
+	c.SwitchPin = testSwitchPin
+	s, driver, _, now := newTestSelector(t, c)
+	pin := driver.FakePin(testSwitchPin)
+	if pin.IsOutput() {
+		t.Fatalf("steam switch should be an input")
+	}
+
+	poll := func(state gpio.State, want Mode) {
+		t.Helper()
+		pin.Set(state)
+		s.pollSwitch()
+		if mode := s.Status().Mode; mode != want {
+			t.Errorf("mode = %s, want %s", mode, want)
+		}
+	}
+
+	// a single read is a bounce
+	poll(gpio.High, ModeBrew)
+	poll(gpio.Low, ModeBrew)
+	poll(gpio.High, ModeBrew)
+	poll(gpio.High, ModeSteam)
+	if reason := s.Status().Reason; reason != ReasonSwitch {
+		t.Errorf("reason = %s, want %s", reason, ReasonSwitch)
+	}
+	poll(gpio.Low, ModeSteam)
+	poll(gpio.Low, ModeBrew)
+
+	// a switch left on after the timeout does not steam again
+	poll(gpio.High, ModeBrew)
+	poll(gpio.High, ModeSteam)
+	*now = now.Add(c.Timeout)
+	s.mu.Lock()
+	s.revertBehaviour()
+	s.mu.Unlock()
+	poll(gpio.High, ModeBrew)
+	poll(gpio.High, ModeBrew)
+}
+
+func TestSelector_Subscribe(t *testing.T) {
+	s, _, _, _ := newTestSelector(t, testConfig)
+	subId, ch := s.Subscribe()
+
+	s.SetMode(ModeSteam, ReasonUser)
+	// brewing while brewing is no change
+	s.SetMode(ModeBrew, ReasonUser)
+	s.SetMode(ModeBrew, ReasonUser)
+
+	for _, want := range []Mode{ModeSteam, ModeBrew} {
+		select {
+		case status := <-ch:
+			if status.Mode != want {
+				t.Errorf("published mode = %s, want %s", status.Mode, want)
+			}
+		default:
+			t.Fatalf("no status published, want %s", want)
+		}
+	}
+	select {
+	case status := <-ch:
+		t.Errorf("unexpected status %+v", status)
+	default:
+	}
+
+	s.Unsubscribe(subId)
+	if _, ok := <-ch; ok {
+		t.Errorf("Unsubscribe() should close the channel")
+	}
+}
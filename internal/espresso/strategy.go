@@ -0,0 +1,30 @@
+package espresso
+
+import (
+	"fmt"
+
+	"github.com/luiccn/espresso-controller/internal/espresso/heating_element"
+	"github.com/luiccn/espresso-controller/internal/espresso/power_manager"
+	"github.com/luiccn/espresso-controller/internal/espresso/temperature"
+	"github.com/luiccn/espresso-controller/pkg/control"
+	"github.com/luiccn/espresso-controller/pkg/control/bangbang"
+	"github.com/luiccn/espresso-controller/pkg/control/pid"
+)
+
+// newStrategy creates the temperature control strategy with the given name.
+// The strategy is not running yet.
+func newStrategy(
+	name string,
+	heatingElem *heating_element.HeatingElement,
+	powerManager *power_manager.PowerManager,
+	boilerMonitor *temperature.Monitor,
+) (control.Strategy, error) {
+	switch name {
+	case pid.Name, "":
+		return pid.NewPid(heatingElem, powerManager, boilerMonitor)
+	case bangbang.Name:
+		return bangbang.NewBangBang(heatingElem, powerManager, boilerMonitor)
+	default:
+		return nil, fmt.Errorf("unknown control strategy %q, expected one of [%s, %s]", name, pid.Name, bangbang.Name)
+	}
+}
@@ -0,0 +1,162 @@
+// Package ds18b20 reads DS18B20 one-wire thermometers through the kernel's
+// w1-therm driver, which exposes each sensor under
+// /sys/bus/w1/devices/28-xxxxxxxxxxxx/w1_slave. Load it with dtoverlay=w1-gpio
+// in /boot/config.txt.
+// https://datasheets.maximintegrated.com/en/ds/DS18B20.pdf
+package ds18b20
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/luiccn/espresso-controller/internal/espresso/temperature"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	readErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "espresso_ds18b20_read_errors_total",
+		Help: "Number of failed ds18b20 readings by sensor id",
+	}, []string{"sensor"})
+)
+
+const (
+	Name = "ds18b20"
+
+	// DefaultSysfsRoot is where the kernel lists one-wire devices
+	DefaultSysfsRoot = "/sys/bus/w1/devices"
+
+	// family code prefixing the id of every ds18b20
+	_FAMILY = "28-"
+	// temperature register value after power-up, before the first conversion
+	_POWER_ON_RESET float32 = 85
+	// change from the last good reading beyond which a reading of the power-on
+	// reset value is taken as the sensor having been reset rather than a real
+	// 85 °C. Samples are taken every second or so, which is well beyond what a
+	// group head heats or cools by in that time.
+	_MAX_RESET_JUMP float32 = 10
+)
+
+var (
+	// ErrCRC is returned when the scratchpad read by the kernel fails its crc
+	// check, usually due to a noisy or long bus
+	ErrCRC = errors.New("ds18b20 crc check failed")
+	// ErrPowerOnReset is returned for the 85 °C power-on reset value, which
+	// the sensor reports when it lost power during a conversion, if it is the
+	// first reading or an implausible jump from the last good one
+	ErrPowerOnReset = errors.New("ds18b20 reported its power-on reset value")
+)
+
+type DS18B20 struct {
+	id   string
+	path string
+	// last is the last good reading, zero until the first one
+	last temperature.Sample
+}
+
+// NewDS18B20 creates a sampler for the sensor with the id, e.g.
+// 28-0316a2795ff2, under the sysfs root. The root defaults to
+// DefaultSysfsRoot.
+func NewDS18B20(root string, id string) (*DS18B20, error) {
+	if root == "" {
+		root = DefaultSysfsRoot
+	}
+	if id == "" {
+		return nil, errors.New("ds18b20 sensor id must not be empty")
+	}
+	path := filepath.Join(root, id, "w1_slave")
+	if _, err := ioutil.ReadFile(path); err != nil {
+		sensors, _ := Sensors(root)
+		return nil, errors.Wrapf(err, "ds18b20 %s not found, sensors present: [%s]", id, strings.Join(sensors, ", "))
+	}
+	return &DS18B20{id: id, path: path}, nil
+}
+
+// Sensors lists the ids of the ds18b20s under the sysfs root
+func Sensors(root string) ([]string, error) {
+	if root == "" {
+		root = DefaultSysfsRoot
+	}
+	entries, err := ioutil.ReadDir(root)
+	if err != nil {
+		return nil, errors.Wrap(err, "listing one-wire devices")
+	}
+	var ids []string
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), _FAMILY) {
+			ids = append(ids, e.Name())
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// Sample triggers a conversion, which takes up to 750ms at 12 bit resolution,
+// and returns the result
+func (d *DS18B20) Sample() (*temperature.Sample, error) {
+	data, err := ioutil.ReadFile(d.path)
+	if err != nil {
+		readErrorsTotal.WithLabelValues(d.id).Inc()
+		return nil, errors.Wrapf(err, "reading ds18b20 %s", d.id)
+	}
+
+	t, err := parse(string(data))
+	if err != nil {
+		readErrorsTotal.WithLabelValues(d.id).Inc()
+		return nil, err
+	}
+	if t == _POWER_ON_RESET && !d.plausible(t) {
+		readErrorsTotal.WithLabelValues(d.id).Inc()
+		return nil, ErrPowerOnReset
+	}
+	d.last = temperature.Sample{
+		Value:      t,
+		ObservedAt: time.Now(),
+	}
+	sample := d.last
+	return &sample, nil
+}
+
+// plausible reports whether the reading is close enough to the last good one
+// to be real, which it can't be without a last one
+func (d *DS18B20) plausible(t float32) bool {
+	if d.last.ObservedAt.IsZero() {
+		return false
+	}
+	jump := t - d.last.Value
+	return jump <= _MAX_RESET_JUMP && jump >= -_MAX_RESET_JUMP
+}
+
+// parse decodes the w1_slave file, e.g.
+//
+//	72 01 4b 46 7f ff 0e 10 57 : crc=57 YES
+//	72 01 4b 46 7f ff 0e 10 57 t=23125
+//
+// where the first line holds the crc check and the second the temperature in
+// thousandths of a °C
+func parse(data string) (float32, error) {
+	lines := strings.Split(strings.TrimSpace(data), "\n")
+	if len(lines) != 2 {
+		return 0, fmt.Errorf("malformed w1_slave, expected 2 lines but got %d", len(lines))
+	}
+	if !strings.HasSuffix(strings.TrimSpace(lines[0]), "YES") {
+		return 0, ErrCRC
+	}
+
+	i := strings.LastIndex(lines[1], "t=")
+	if i < 0 {
+		return 0, errors.New("malformed w1_slave, temperature missing")
+	}
+	milli, err := strconv.Atoi(strings.TrimSpace(lines[1][i+2:]))
+	if err != nil {
+		return 0, errors.Wrap(err, "malformed w1_slave temperature")
+	}
+	return float32(milli) / 1000, nil
+}
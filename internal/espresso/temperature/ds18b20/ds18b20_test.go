@@ -0,0 +1,141 @@
+package ds18b20
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/luiccn/espresso-controller/internal/espresso/temperature"
+)
+
+const testRoot = "testdata/devices"
+
+func TestDS18B20_Sample(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      string
+		want    float32
+		wantErr error
+	}{
+		{name: "ambient", id: "28-0316a2795ff2", want: 23.125},
+		{name: "group head", id: "28-0416b1c5a3ff", want: 85.75},
+		{name: "power-on reset", id: "28-000005e2fdc3", wantErr: ErrPowerOnReset},
+		{name: "crc mismatch", id: "28-00000a1b2c3d", wantErr: ErrCRC},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := NewDS18B20(testRoot, tt.id)
+			if err != nil {
+				t.Fatalf("NewDS18B20() error = %v", err)
+			}
+			got, err := d.Sample()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Sample() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && got.Value != tt.want {
+				t.Errorf("Sample() = %v, want %v", got.Value, tt.want)
+			}
+		})
+	}
+}
+
+func TestDS18B20_Sample_powerOnReset(t *testing.T) {
+	tests := []struct {
+		name string
+		// readings in thousandths of a °C, the last of which is checked
+		readings []int
+		want     float32
+		wantErr  error
+	}{
+		{name: "first reading", readings: []int{85000}, wantErr: ErrPowerOnReset},
+		{name: "heating up through 85 °C", readings: []int{84750, 85000}, want: 85},
+		{name: "cooling down through 85 °C", readings: []int{85250, 85000}, want: 85},
+		{name: "jump from ambient", readings: []int{23125, 85000}, wantErr: ErrPowerOnReset},
+		{name: "again after a reset", readings: []int{85000, 85000}, wantErr: ErrPowerOnReset},
+		{name: "holding at 85 °C", readings: []int{84750, 85000, 85000}, want: 85},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			id := "28-0416b1c5a3ff"
+			if err := os.Mkdir(filepath.Join(root, id), 0755); err != nil {
+				t.Fatal(err)
+			}
+			write := func(milli int) {
+				data := fmt.Sprintf("50 05 4b 46 7f ff 0c 10 1c : crc=1c YES\n50 05 4b 46 7f ff 0c 10 1c t=%d\n", milli)
+				if err := ioutil.WriteFile(filepath.Join(root, id, "w1_slave"), []byte(data), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			write(tt.readings[0])
+			d, err := NewDS18B20(root, id)
+			if err != nil {
+				t.Fatalf("NewDS18B20() error = %v", err)
+			}
+
+			var got *temperature.Sample
+			for _, milli := range tt.readings {
+				write(milli)
+				got, err = d.Sample()
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Sample() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && got.Value != tt.want {
+				t.Errorf("Sample() = %v, want %v", got.Value, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewDS18B20_Missing(t *testing.T) {
+	if _, err := NewDS18B20(testRoot, "28-ffffffffffff"); err == nil {
+		t.Errorf("NewDS18B20() of a missing sensor should fail")
+	}
+	if _, err := NewDS18B20(testRoot, ""); err == nil {
+		t.Errorf("NewDS18B20() without an id should fail")
+	}
+}
+
+func TestSensors(t *testing.T) {
+	got, err := Sensors(testRoot)
+	if err != nil {
+		t.Fatalf("Sensors() error = %v", err)
+	}
+	want := []string{"28-000005e2fdc3", "28-00000a1b2c3d", "28-0316a2795ff2", "28-0416b1c5a3ff"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Sensors() = %v, want %v", got, want)
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    float32
+		wantErr bool
+	}{
+		{name: "below freezing", data: "5e ff 4b 46 7f ff 02 10 0e : crc=0e YES\n5e ff 4b 46 7f ff 02 10 0e t=-10125\n", want: -10.125},
+		{name: "truncated", data: "5e ff 4b 46 7f ff 02 10 0e : crc=0e YES\n", wantErr: true},
+		{name: "no temperature", data: "5e ff 4b 46 7f ff 02 10 0e : crc=0e YES\n5e ff 4b 46 7f ff 02 10 0e\n", wantErr: true},
+		{name: "garbled temperature", data: "5e ff 4b 46 7f ff 02 10 0e : crc=0e YES\n5e ff 4b 46 7f ff 02 10 0e t=12a\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parse(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
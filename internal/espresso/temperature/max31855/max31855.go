@@ -0,0 +1,138 @@
+// Package max31855 reads a thermocouple through a MAX31855 amplifier with
+// cold-junction compensation.
+// https://datasheets.maximintegrated.com/en/ds/MAX31855.pdf
+package max31855
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/luiccn/espresso-controller/internal/espresso/spi"
+	"github.com/luiccn/espresso-controller/internal/espresso/temperature"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	faultsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "espresso_max31855_faults_total",
+		Help: "Number of faults flagged by the max31855 by fault bit",
+	}, []string{"fault"})
+)
+
+const (
+	Name = "max31855"
+
+	// SPIMode is the SPI mode the max31855 is read in
+	SPIMode = spi.Mode0
+)
+
+const (
+	_FAULT_BIT uint32 = 0x00010000
+	_FAULT_SCV uint8  = 0x04
+	_FAULT_SCG uint8  = 0x02
+	_FAULT_OC  uint8  = 0x01
+
+	// resolution of the 14 bit thermocouple temperature, °C
+	_THERMOCOUPLE_RESOLUTION float32 = 0.25
+	// resolution of the 12 bit internal temperature, °C
+	_INTERNAL_RESOLUTION float32 = 0.0625
+)
+
+var (
+	// ErrOpenThermocouple is flagged when the thermocouple is not connected
+	ErrOpenThermocouple = errors.New("thermocouple open")
+	// ErrShortToGND is flagged when the thermocouple is shorted to GND
+	ErrShortToGND = errors.New("thermocouple shorted to GND")
+	// ErrShortToVCC is flagged when the thermocouple is shorted to VCC
+	ErrShortToVCC = errors.New("thermocouple shorted to VCC")
+)
+
+var faultBits = []struct {
+	bit   uint8
+	err   error
+	label string
+}{
+	{_FAULT_OC, ErrOpenThermocouple, "open"},
+	{_FAULT_SCG, ErrShortToGND, "short_to_gnd"},
+	{_FAULT_SCV, ErrShortToVCC, "short_to_vcc"},
+}
+
+// FaultError is returned when the max31855 flags a fault. Use errors.Is to
+// test for the individual faults, e.g. ErrOpenThermocouple.
+type FaultError struct {
+	Status uint8
+}
+
+func (e *FaultError) Error() string {
+	var msgs []string
+	for _, f := range faultBits {
+		if e.Status&f.bit != 0 {
+			msgs = append(msgs, f.err.Error())
+		}
+	}
+	return fmt.Sprintf("max31855 fault 0x%x: %s", e.Status, strings.Join(msgs, ", "))
+}
+
+func (e *FaultError) Is(target error) bool {
+	for _, f := range faultBits {
+		if e.Status&f.bit != 0 && f.err == target {
+			return true
+		}
+	}
+	return false
+}
+
+// Max31855 converts continuously, a conversion takes up to 100ms.
+type Max31855 struct {
+	bus spi.Bus
+}
+
+func NewMax31855(bus spi.Bus) *Max31855 {
+	return &Max31855{bus: bus}
+}
+
+func (m *Max31855) Sample() (*temperature.Sample, error) {
+	t, _, err := m.ReadTemperatures()
+	if err != nil {
+		return nil, err
+	}
+	return &temperature.Sample{
+		Value:      t,
+		ObservedAt: time.Now(),
+	}, nil
+}
+
+// ReadTemperatures returns the thermocouple and the internal, i.e. cold
+// junction, temperature
+func (m *Max31855) ReadTemperatures() (float32, float32, error) {
+	r := make([]byte, 4)
+	if err := m.bus.Tx(make([]byte, 4), r); err != nil {
+		return 0, 0, errors.Wrap(err, "reading max31855")
+	}
+
+	v := uint32(r[0])<<24 | uint32(r[1])<<16 | uint32(r[2])<<8 | uint32(r[3])
+	thermocouple, internal, err := decode(v)
+	if fault, ok := err.(*FaultError); ok {
+		for _, f := range faultBits {
+			if fault.Status&f.bit != 0 {
+				faultsTotal.WithLabelValues(f.label).Inc()
+			}
+		}
+	}
+	return thermocouple, internal, err
+}
+
+// decode converts the 32 bit reading. D31-D18 are the signed thermocouple
+// temperature, D16 flags a fault, D15-D4 are the signed internal temperature
+// and D2-D0 the fault bits.
+func decode(v uint32) (float32, float32, error) {
+	internal := float32(int16(v&0xFFF0)>>4) * _INTERNAL_RESOLUTION
+	if v&_FAULT_BIT != 0 {
+		return 0, internal, &FaultError{Status: uint8(v & 0x07)}
+	}
+	thermocouple := float32(int32(v)>>18) * _THERMOCOUPLE_RESOLUTION
+	return thermocouple, internal, nil
+}
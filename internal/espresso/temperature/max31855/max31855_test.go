@@ -0,0 +1,50 @@
+package max31855
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/luiccn/espresso-controller/internal/espresso/spi"
+)
+
+func TestMax31855_ReadTemperatures(t *testing.T) {
+	tests := []struct {
+		name             string
+		reply            []byte
+		wantThermocouple float32
+		wantInternal     float32
+		wantErr          []error
+	}{
+		// examples from the datasheet's temperature data format table
+		{name: "brewing", reply: []byte{0x06, 0x40, 0x19, 0x00}, wantThermocouple: 100, wantInternal: 25},
+		{name: "max", reply: []byte{0x64, 0x00, 0x7F, 0x00}, wantThermocouple: 1600, wantInternal: 127},
+		{name: "below zero", reply: []byte{0xFF, 0xFC, 0xFF, 0xF0}, wantThermocouple: -0.25, wantInternal: -0.0625},
+		{name: "open thermocouple", reply: []byte{0x00, 0x01, 0x19, 0x01}, wantInternal: 25, wantErr: []error{ErrOpenThermocouple}},
+		{name: "short to gnd", reply: []byte{0x00, 0x01, 0x19, 0x02}, wantInternal: 25, wantErr: []error{ErrShortToGND}},
+		{name: "short to vcc", reply: []byte{0x00, 0x01, 0x19, 0x04}, wantInternal: 25, wantErr: []error{ErrShortToVCC}},
+		{name: "several faults", reply: []byte{0x00, 0x01, 0x19, 0x05}, wantInternal: 25, wantErr: []error{ErrOpenThermocouple, ErrShortToVCC}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMax31855(spi.NewFakeBus(func(w []byte) []byte {
+				return tt.reply
+			}))
+			thermocouple, internal, err := m.ReadTemperatures()
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("ReadTemperatures() error = %v", err)
+			}
+			for _, target := range tt.wantErr {
+				if !errors.Is(err, target) {
+					t.Errorf("ReadTemperatures() error = %v, want %v", err, target)
+				}
+			}
+			if err == nil && thermocouple != tt.wantThermocouple {
+				t.Errorf("ReadTemperatures() thermocouple = %v, want %v", thermocouple, tt.wantThermocouple)
+			}
+			if internal != tt.wantInternal {
+				t.Errorf("ReadTemperatures() internal = %v, want %v", internal, tt.wantInternal)
+			}
+		})
+	}
+}
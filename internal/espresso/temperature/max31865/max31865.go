@@ -1,11 +1,23 @@
 package max31865
 
 import (
+	"fmt"
 	"math"
+	"strings"
 	"time"
 
+	"github.com/luiccn/espresso-controller/internal/espresso/spi"
 	"github.com/luiccn/espresso-controller/internal/espresso/temperature"
-	"github.com/stianeikeland/go-rpio/v4"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	faultsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "espresso_max31865_faults_total",
+		Help: "Number of faults flagged by the max31865 by fault status bit",
+	}, []string{"fault"})
 )
 
 const (
@@ -43,58 +55,210 @@ const (
 const (
 	_RTD_A float32 = 3.9083e-3
 	_RTD_B float32 = -5.775e-7
+	_RTD_C float32 = -4.183e-12
+)
+
+var (
+	// ErrRTDOpen is flagged when the RTD resistance is above the high fault
+	// threshold, typically because the RTD is open or disconnected
+	ErrRTDOpen = errors.New("rtd resistance above high threshold, rtd open?")
+	// ErrRTDShort is flagged when the RTD resistance is below the low fault
+	// threshold, typically because the RTD is shorted
+	ErrRTDShort = errors.New("rtd resistance below low threshold, rtd shorted?")
+	// ErrREFINOutOfRange is flagged when REFIN- is outside of its valid range
+	// relative to VBIAS, e.g. because FORCE- is open
+	ErrREFINOutOfRange = errors.New("REFIN- out of range")
+	// ErrRTDINOutOfRange is flagged when RTDIN- is below 0.85 x VBIAS,
+	// e.g. because FORCE- is open
+	ErrRTDINOutOfRange = errors.New("RTDIN- out of range")
+	// ErrOverUnderVoltage is flagged when an input is over or under voltage
+	ErrOverUnderVoltage = errors.New("over or under voltage")
+)
+
+var faultBits = []struct {
+	bit   uint8
+	err   error
+	label string
+}{
+	{_FAULT_HIGHTHRESH, ErrRTDOpen, "rtd_high_threshold"},
+	{_FAULT_LOWTHRESH, ErrRTDShort, "rtd_low_threshold"},
+	{_FAULT_REFINLOW, ErrREFINOutOfRange, "refin_low"},
+	{_FAULT_REFINHIGH, ErrREFINOutOfRange, "refin_high"},
+	{_FAULT_RTDINLOW, ErrRTDINOutOfRange, "rtdin_low"},
+	{_FAULT_OVUV, ErrOverUnderVoltage, "over_under_voltage"},
+}
+
+// FaultError is returned when the fault status register flags a fault. Use
+// errors.Is to test for the individual faults, e.g. ErrRTDOpen.
+type FaultError struct {
+	Status uint8
+}
+
+func (e *FaultError) Error() string {
+	var msgs []string
+	for _, f := range faultBits {
+		if e.Status&f.bit != 0 {
+			msgs = append(msgs, f.err.Error())
+		}
+	}
+	return fmt.Sprintf("max31865 fault 0x%02x: %s", e.Status, strings.Join(msgs, ", "))
+}
+
+func (e *FaultError) Is(target error) bool {
+	for _, f := range faultBits {
+		if e.Status&f.bit != 0 && f.err == target {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	WIRE_2 = 2
+	WIRE_3 = 3
+	WIRE_4 = 4
+)
+
+const (
+	FILTER_50HZ = 50
+	FILTER_60HZ = 60
 )
 
+// Config of the max31865 and the RTD connected to it
+type Config struct {
+	// Resistance of the RTD at 0 °C, i.e. 100 for a PT100 or 1000 for a PT1000
+	RTDNominal float32
+	// Resistance of the board's reference resistor, e.g. 430 for a PT100 or
+	// 4300 for a PT1000 on Adafruit boards
+	RefResistor float32
+	// Number of wires connecting the RTD, WIRE_2, WIRE_3 or WIRE_4
+	Wires int
+	// Mains frequency rejected by the notch filter, FILTER_50HZ or FILTER_60HZ
+	FilterHz int
+	// Temperatures in °C outside of which the max31865 flags a fault
+	LowFaultThreshold  float32
+	HighFaultThreshold float32
+}
+
+func (c Config) validate() error {
+	if c.RTDNominal <= 0 {
+		return fmt.Errorf("rtd nominal resistance must be positive, got %v", c.RTDNominal)
+	}
+	if c.RefResistor <= c.RTDNominal {
+		return fmt.Errorf("reference resistor must be larger than the rtd nominal resistance %v, got %v", c.RTDNominal, c.RefResistor)
+	}
+	if c.Wires != WIRE_2 && c.Wires != WIRE_3 && c.Wires != WIRE_4 {
+		return fmt.Errorf("rtd wires must be 2, 3 or 4, got %d", c.Wires)
+	}
+	if c.FilterHz != FILTER_50HZ && c.FilterHz != FILTER_60HZ {
+		return fmt.Errorf("filter must be 50 or 60 Hz, got %d", c.FilterHz)
+	}
+	if c.LowFaultThreshold >= c.HighFaultThreshold {
+		return fmt.Errorf("low fault threshold %v must be below high fault threshold %v", c.LowFaultThreshold, c.HighFaultThreshold)
+	}
+	return nil
+}
+
 const (
-	WIRE_2 = 0
-	WIRE_3 = 1
-	WIRE_4 = 0
+	Name = "max31865"
+
+	// SPIMode is the SPI mode the max31865 is read in
+	SPIMode = spi.Mode1
 )
 
 type Max31865 struct {
-	csPin   rpio.Pin
-	misoPin rpio.Pin
-	mosiPin rpio.Pin
-	clkPin  rpio.Pin
+	c   Config
+	bus spi.Bus
 }
 
+// Sample converts a temperature, returning a *FaultError if the max31865
+// flagged a fault during the conversion.
 func (m *Max31865) Sample() (*temperature.Sample, error) {
-	t := m.ReadTemperature(100, 430)
+	t, err := m.ReadTemperature()
+	if err != nil {
+		return nil, err
+	}
+	fault, err := m.readFault()
+	if err != nil {
+		return nil, err
+	}
+	if fault != 0 {
+		if err := m.clearFault(); err != nil {
+			return nil, err
+		}
+		for _, f := range faultBits {
+			if fault&f.bit != 0 {
+				faultsTotal.WithLabelValues(f.label).Inc()
+			}
+		}
+		return nil, &FaultError{Status: fault}
+	}
 	return &temperature.Sample{
 		Value:      t,
 		ObservedAt: time.Now(),
 	}, nil
 }
 
-func NewMax31865(cs int, clk int, miso int, mosi int) *Max31865 {
-
-	s := &Max31865{}
+// NewMax31865 configures the max31865 on the bus, which must be in SPIMode
+func NewMax31865(bus spi.Bus, c Config) (*Max31865, error) {
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
 
-	s.csPin = rpio.Pin(cs)
-	s.misoPin = rpio.Pin(miso)
-	s.mosiPin = rpio.Pin(mosi)
-	s.clkPin = rpio.Pin(clk)
+	s := &Max31865{c: c, bus: bus}
 
-	s.csPin.Output()
-	s.csPin.High()
+	if err := s.setWires(c.Wires); err != nil {
+		return nil, err
+	}
+	if err := s.setFilter(c.FilterHz); err != nil {
+		return nil, err
+	}
+	if err := s.enableBias(false); err != nil {
+		return nil, err
+	}
+	if err := s.autoConvert(false); err != nil {
+		return nil, err
+	}
+	if err := s.setFaultThresholds(
+		temperatureToCode(c.LowFaultThreshold, c.RTDNominal, c.RefResistor),
+		temperatureToCode(c.HighFaultThreshold, c.RTDNominal, c.RefResistor),
+	); err != nil {
+		return nil, err
+	}
+	if err := s.clearFault(); err != nil {
+		return nil, err
+	}
 
-	s.clkPin.Output()
-	s.clkPin.Low()
+	return s, nil
+}
 
-	s.misoPin.Input()
-	s.mosiPin.Output()
+// temperatureToCode converts a temperature to the 15 bit ADC code the
+// max31865 reads for it, using the Callendar-Van Dusen equation
+func temperatureToCode(t float32, RTDnominal float32, refResistor float32) uint16 {
+	r := 1 + _RTD_A*t + _RTD_B*t*t
+	if t < 0 {
+		r += _RTD_C * (t - 100) * t * t * t
+	}
+	r *= RTDnominal
 
-	s.setWires(WIRE_3)
-	s.enableBias(false)
-	s.autoConvert(false)
-	s.clearFault()
+	code := math.Round(float64(r / refResistor * 32768))
+	return uint16(math.Max(0, math.Min(0x7FFF, code)))
+}
 
-	return s
+func (s *Max31865) ReadTemperature() (float32, error) {
+	code, err := s.ReadRTD()
+	if err != nil {
+		return 0, err
+	}
+	return codeToTemperature(code, s.c.RTDNominal, s.c.RefResistor), nil
 }
 
-func (s *Max31865) ReadTemperature(RTDnominal float32, refResistor float32) float32 {
+// codeToTemperature converts the 15 bit ADC code to a temperature, solving the
+// Callendar-Van Dusen equation above 0 °C and approximating it with a
+// polynomial below
+func codeToTemperature(code uint16, RTDnominal float32, refResistor float32) float32 {
 
-	Rt := float32(s.ReadRTD())
+	Rt := float32(code)
 
 	Rt /= 32768
 	Rt *= refResistor
@@ -111,6 +275,7 @@ func (s *Max31865) ReadTemperature(RTDnominal float32, refResistor float32) floa
 		return temp
 	}
 
+	// the polynomial is for a PT100
 	Rt /= RTDnominal
 	Rt *= 100
 
@@ -120,7 +285,7 @@ func (s *Max31865) ReadTemperature(RTDnominal float32, refResistor float32) floa
 	temp += 2.2228 * rpoly
 	rpoly *= Rt
 	temp += 2.5859e-3 * rpoly
-	rpoly *= RTDnominal
+	rpoly *= Rt
 	temp -= 4.8260e-6 * rpoly
 	rpoly *= Rt
 	temp -= 2.8183e-8 * rpoly
@@ -130,121 +295,112 @@ func (s *Max31865) ReadTemperature(RTDnominal float32, refResistor float32) floa
 	return temp
 }
 
-func (s *Max31865) readFault() uint8 {
+// setFaultThresholds sets the ADC codes outside of which a fault is flagged
+func (s *Max31865) setFaultThresholds(low uint16, high uint16) error {
+	if err := s.write(_HFAULTMSB_REG, []uint8{uint8(high >> 7), uint8(high << 1)}); err != nil {
+		return err
+	}
+	return s.write(_LFAULTMSB_REG, []uint8{uint8(low >> 7), uint8(low << 1)})
+}
+
+func (s *Max31865) readFault() (uint8, error) {
 	return s.read8(_FAULTSTAT_REG)
 }
 
-func (s *Max31865) clearFault() {
-	t := s.read8(_CONFIG_REG)
-	t &= ^uint8(0x2C)
-	t |= _CONFIG_FAULTSTAT
-	s.write8(_CONFIG_REG, t)
+func (s *Max31865) clearFault() error {
+	return s.updateConfig(func(t uint8) uint8 {
+		t &= ^uint8(0x2C)
+		return t | _CONFIG_FAULTSTAT
+	})
 }
 
-func (s *Max31865) enableBias(b bool) {
-	t := s.read8(_CONFIG_REG)
-	if b {
-		t |= _CONFIG_BIAS
-	} else {
-		t &= ^_CONFIG_BIAS
-	}
-	s.write8(_CONFIG_REG, t)
+func (s *Max31865) enableBias(b bool) error {
+	return s.setConfigBit(_CONFIG_BIAS, b)
 }
 
-func (s *Max31865) autoConvert(b bool) {
-	t := s.read8(_CONFIG_REG)
-	if b {
-		t |= _CONFIG_MODEAUTO
-	} else {
-		t &= ^_CONFIG_MODEAUTO
-	}
-	s.write8(_CONFIG_REG, t)
+func (s *Max31865) autoConvert(b bool) error {
+	return s.setConfigBit(_CONFIG_MODEAUTO, b)
 }
 
-func (s *Max31865) setWires(wires int) {
-	t := s.read8(_CONFIG_REG)
-	if wires == WIRE_3 {
-		t |= _CONFIG_3WIRE
-	} else {
-		t &= ^_CONFIG_3WIRE
-	}
-	s.write8(_CONFIG_REG, t)
+func (s *Max31865) setFilter(hz int) error {
+	return s.setConfigBit(_CONFIG_FILT50HZ, hz == FILTER_50HZ)
+}
+
+func (s *Max31865) setWires(wires int) error {
+	return s.setConfigBit(_CONFIG_3WIRE, wires == WIRE_3)
 }
 
-func (s *Max31865) ReadRTD() uint16 {
+func (s *Max31865) setConfigBit(bit uint8, set bool) error {
+	return s.updateConfig(func(t uint8) uint8 {
+		if set {
+			return t | bit
+		}
+		return t & ^bit
+	})
+}
 
-	s.clearFault()
-	s.enableBias(true)
+// updateConfig reads, modifies and writes back the configuration register
+func (s *Max31865) updateConfig(modify func(uint8) uint8) error {
+	t, err := s.read8(_CONFIG_REG)
+	if err != nil {
+		return err
+	}
+	return s.write8(_CONFIG_REG, modify(t))
+}
+
+func (s *Max31865) ReadRTD() (uint16, error) {
+	if err := s.clearFault(); err != nil {
+		return 0, err
+	}
+	if err := s.enableBias(true); err != nil {
+		return 0, err
+	}
 	time.Sleep(10 * time.Millisecond)
 
-	t := s.read8(_CONFIG_REG)
-	t |= _CONFIG_1SHOT
-	s.write8(_CONFIG_REG, t)
+	if err := s.setConfigBit(_CONFIG_1SHOT, true); err != nil {
+		return 0, err
+	}
 	time.Sleep(65 * time.Millisecond)
 
-	rtd := s.read16(_RTDMSB_REG)
+	rtd, err := s.read16(_RTDMSB_REG)
+	if err != nil {
+		return 0, err
+	}
 	rtd >>= 1
-	return rtd
-
+	return rtd, nil
 }
 
-func (s *Max31865) write(addr uint8, v []uint8) {
-	addr |= 0x80
-	s.clkPin.Low()
-	s.csPin.Low()
-
-	s.transfer8(addr)
-	for n := 0; n < len(v); n++ {
-		s.transfer8(v[n])
-	}
-	s.csPin.High()
+func (s *Max31865) write(addr uint8, v []uint8) error {
+	w := append([]uint8{addr | 0x80}, v...)
+	return errors.Wrap(s.bus.Tx(w, nil), "writing max31865 register")
 }
 
-func (s *Max31865) write8(addr uint8, v uint8) {
-	s.write(addr, []uint8{v})
+func (s *Max31865) write8(addr uint8, v uint8) error {
+	return s.write(addr, []uint8{v})
 }
 
-func (s *Max31865) read(addr uint8, v []uint8) {
-	addr &= 0x7F
-	s.clkPin.Low()
-	s.csPin.Low()
-	s.transfer8(addr)
-	for n := 0; n < len(v); n++ {
-		v[n] = s.transfer8(0xFF)
+func (s *Max31865) read(addr uint8, v []uint8) error {
+	w := make([]uint8, len(v)+1)
+	w[0] = addr & 0x7F
+	for n := 1; n < len(w); n++ {
+		w[n] = 0xFF
 	}
-	s.csPin.High()
+	r := make([]uint8, len(w))
+	if err := s.bus.Tx(w, r); err != nil {
+		return errors.Wrap(err, "reading max31865 register")
+	}
+	copy(v, r[1:])
+	return nil
 }
 
-func (s *Max31865) read8(addr uint8) uint8 {
+func (s *Max31865) read8(addr uint8) (uint8, error) {
 	var v = make([]uint8, 1)
-	s.read(addr, v)
-	return v[0]
+	err := s.read(addr, v)
+	return v[0], err
 }
 
-func (s *Max31865) read16(addr uint8) uint16 {
+func (s *Max31865) read16(addr uint8) (uint16, error) {
 	var v = make([]uint8, 2)
-	s.read(addr, v)
-	return (uint16(v[0]) << 8) | uint16(v[1])
-}
-
-func (s *Max31865) transfer8(v uint8) uint8 {
-	var reply uint8 = 0
-	for i := 7; i >= 0; i-- {
-		reply <<= 1
-		s.clkPin.High()
-		bv := v & (1 << uint(i))
-
-		if bv != 0 {
-			s.mosiPin.High()
-		} else {
-			s.mosiPin.Low()
-		}
-
-		s.clkPin.Low()
-
-		if s.misoPin.Read() == rpio.High {
-			reply |= 1
-		}
-	}
-	return reply
+	err := s.read(addr, v)
+	return (uint16(v[0]) << 8) | uint16(v[1]), err
 }
@@ -0,0 +1,249 @@
+package max31865
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/luiccn/espresso-controller/internal/espresso/spi"
+)
+
+// fakeDevice emulates the max31865's registers behind a spi.FakeBus. A one
+// shot conversion reads code, and flags fault if it is set.
+type fakeDevice struct {
+	regs  [8]uint8
+	code  uint16
+	fault uint8
+}
+
+func (d *fakeDevice) respond(w []byte) []byte {
+	r := make([]byte, len(w))
+	addr := w[0] & 0x7F
+	if w[0]&0x80 == 0 {
+		copy(r[1:], d.regs[addr:])
+		return r
+	}
+
+	copy(d.regs[addr:], w[1:])
+	if addr == _CONFIG_REG {
+		if d.regs[_CONFIG_REG]&_CONFIG_FAULTSTAT != 0 {
+			d.regs[_FAULTSTAT_REG] = 0
+			d.regs[_CONFIG_REG] &= ^_CONFIG_FAULTSTAT
+		}
+		if d.regs[_CONFIG_REG]&_CONFIG_1SHOT != 0 {
+			rtd := d.code << 1
+			if d.fault != 0 {
+				rtd |= 1
+				d.regs[_FAULTSTAT_REG] = d.fault
+			}
+			d.regs[_RTDMSB_REG] = uint8(rtd >> 8)
+			d.regs[_RTDLSB_REG] = uint8(rtd)
+			d.regs[_CONFIG_REG] &= ^_CONFIG_1SHOT
+		}
+	}
+	return r
+}
+
+func TestFaultError_Is(t *testing.T) {
+	tests := []struct {
+		name   string
+		status uint8
+		is     []error
+		isNot  []error
+	}{
+		{
+			name:   "open rtd",
+			status: _FAULT_HIGHTHRESH | _FAULT_REFINLOW,
+			is:     []error{ErrRTDOpen, ErrREFINOutOfRange},
+			isNot:  []error{ErrRTDShort, ErrRTDINOutOfRange, ErrOverUnderVoltage},
+		},
+		{
+			name:   "shorted rtd",
+			status: _FAULT_LOWTHRESH,
+			is:     []error{ErrRTDShort},
+			isNot:  []error{ErrRTDOpen, ErrREFINOutOfRange},
+		},
+		{
+			name:   "force- open",
+			status: _FAULT_RTDINLOW | _FAULT_REFINHIGH,
+			is:     []error{ErrRTDINOutOfRange, ErrREFINOutOfRange},
+			isNot:  []error{ErrRTDOpen, ErrRTDShort},
+		},
+		{
+			name:   "over voltage",
+			status: _FAULT_OVUV,
+			is:     []error{ErrOverUnderVoltage},
+			isNot:  []error{ErrRTDOpen},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error = &FaultError{Status: tt.status}
+			for _, target := range tt.is {
+				if !errors.Is(err, target) {
+					t.Errorf("expected %q to be %q", err, target)
+				}
+			}
+			for _, target := range tt.isNot {
+				if errors.Is(err, target) {
+					t.Errorf("expected %q not to be %q", err, target)
+				}
+			}
+		})
+	}
+}
+
+func TestTemperatureToCode(t *testing.T) {
+	tests := []struct {
+		name        string
+		temperature float32
+		rtdNominal  float32
+		refResistor float32
+		want        uint16
+	}{
+		// 100 Ω at 0 °C is 100/430 of full scale
+		{name: "PT100 0 °C", temperature: 0, rtdNominal: 100, refResistor: 430, want: 7620},
+		// 138.51 Ω at 100 °C
+		{name: "PT100 100 °C", temperature: 100, rtdNominal: 100, refResistor: 430, want: 10555},
+		// 18.52 Ω at -200 °C
+		{name: "PT100 -200 °C", temperature: -200, rtdNominal: 100, refResistor: 430, want: 1411},
+		{name: "PT1000 100 °C", temperature: 100, rtdNominal: 1000, refResistor: 4300, want: 10555},
+		{name: "clamped at full scale", temperature: 2000, rtdNominal: 100, refResistor: 430, want: 0x7FFF},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := temperatureToCode(tt.temperature, tt.rtdNominal, tt.refResistor)
+			if diff := int(got) - int(tt.want); diff < -1 || diff > 1 {
+				t.Errorf("temperatureToCode(%v) = %d, want %d", tt.temperature, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCodeToTemperature(t *testing.T) {
+	tests := []struct {
+		name        string
+		temperature float32
+		rtdNominal  float32
+		refResistor float32
+	}{
+		{name: "PT100 boiling", temperature: 100, rtdNominal: 100, refResistor: 430},
+		{name: "PT100 brewing", temperature: 93.5, rtdNominal: 100, refResistor: 430},
+		{name: "PT100 below 0 °C", temperature: -50, rtdNominal: 100, refResistor: 430},
+		{name: "PT1000 on 4300 Ω brewing", temperature: 93.5, rtdNominal: 1000, refResistor: 4300},
+		{name: "PT1000 on 4300 Ω steaming", temperature: 140, rtdNominal: 1000, refResistor: 4300},
+		{name: "PT1000 below 0 °C", temperature: -50, rtdNominal: 1000, refResistor: 4300},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code := temperatureToCode(tt.temperature, tt.rtdNominal, tt.refResistor)
+			got := codeToTemperature(code, tt.rtdNominal, tt.refResistor)
+			// one ADC step is ~0.03 °C
+			if math.Abs(float64(got-tt.temperature)) > 0.1 {
+				t.Errorf("codeToTemperature(%d) = %v, want %v", code, got, tt.temperature)
+			}
+		})
+	}
+}
+
+func TestConfig_validate(t *testing.T) {
+	valid := Config{
+		RTDNominal:         100,
+		RefResistor:        430,
+		Wires:              WIRE_3,
+		FilterHz:           FILTER_60HZ,
+		LowFaultThreshold:  -40,
+		HighFaultThreshold: 250,
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(c *Config)
+		wantErr bool
+	}{
+		{name: "valid", modify: func(c *Config) {}},
+		{name: "PT1000 4 wire 50 Hz", modify: func(c *Config) {
+			c.RTDNominal, c.RefResistor, c.Wires, c.FilterHz = 1000, 4300, WIRE_4, FILTER_50HZ
+		}},
+		{name: "2 wire", modify: func(c *Config) { c.Wires = WIRE_2 }},
+		{name: "unknown wires", modify: func(c *Config) { c.Wires = 1 }, wantErr: true},
+		{name: "unknown filter", modify: func(c *Config) { c.FilterHz = 55 }, wantErr: true},
+		{name: "no rtd nominal", modify: func(c *Config) { c.RTDNominal = 0 }, wantErr: true},
+		{name: "reference below rtd", modify: func(c *Config) { c.RTDNominal = 1000 }, wantErr: true},
+		{name: "thresholds swapped", modify: func(c *Config) { c.LowFaultThreshold = 300 }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := valid
+			tt.modify(&c)
+			if err := c.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMax31865(t *testing.T) {
+	c := Config{
+		RTDNominal:         1000,
+		RefResistor:        4300,
+		Wires:              WIRE_3,
+		FilterHz:           FILTER_50HZ,
+		LowFaultThreshold:  -40,
+		HighFaultThreshold: 250,
+	}
+
+	tests := []struct {
+		name    string
+		fault   uint8
+		want    float32
+		wantErr error
+	}{
+		{name: "brewing", want: 93.5},
+		{name: "open rtd", fault: _FAULT_HIGHTHRESH | _FAULT_REFINLOW, wantErr: ErrRTDOpen},
+		{name: "shorted rtd", fault: _FAULT_LOWTHRESH, wantErr: ErrRTDShort},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &fakeDevice{
+				code:  temperatureToCode(93.5, c.RTDNominal, c.RefResistor),
+				fault: tt.fault,
+			}
+			m, err := NewMax31865(spi.NewFakeBus(d.respond), c)
+			if err != nil {
+				t.Fatalf("NewMax31865() error = %v", err)
+			}
+
+			config := d.regs[_CONFIG_REG]
+			if config&_CONFIG_3WIRE == 0 || config&_CONFIG_FILT50HZ == 0 {
+				t.Errorf("config register = %08b, want 3 wire and 50 Hz filter", config)
+			}
+			high := (uint16(d.regs[_HFAULTMSB_REG])<<8 | uint16(d.regs[_HFAULTLSB_REG])) >> 1
+			if want := temperatureToCode(c.HighFaultThreshold, c.RTDNominal, c.RefResistor); high != want {
+				t.Errorf("high fault threshold = %d, want %d", high, want)
+			}
+
+			sample, err := m.Sample()
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Sample() error = %v, want %v", err, tt.wantErr)
+				}
+				if d.regs[_FAULTSTAT_REG] != 0 {
+					t.Errorf("fault status not cleared after Sample()")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Sample() error = %v", err)
+			}
+			if math.Abs(float64(sample.Value-tt.want)) > 0.1 {
+				t.Errorf("Sample() = %v, want %v", sample.Value, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+// Package max6675 reads a type K thermocouple through a MAX6675 amplifier.
+// https://datasheets.maximintegrated.com/en/ds/MAX6675.pdf
+package max6675
+
+import (
+	"time"
+
+	"github.com/luiccn/espresso-controller/internal/espresso/spi"
+	"github.com/luiccn/espresso-controller/internal/espresso/temperature"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	faultsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "espresso_max6675_faults_total",
+		Help: "Number of open thermocouple faults flagged by the max6675",
+	})
+)
+
+const (
+	Name = "max6675"
+
+	// SPIMode is the SPI mode the max6675 is read in
+	SPIMode = spi.Mode0
+)
+
+const (
+	_OPEN_BIT uint16 = 0x0004
+	// resolution of the 12 bit temperature, °C
+	_RESOLUTION float32 = 0.25
+)
+
+// ErrOpenThermocouple is flagged when the thermocouple input is open
+var ErrOpenThermocouple = errors.New("thermocouple input open")
+
+// Max6675 converts continuously, a conversion takes up to 220ms. Reading it
+// more often aborts the conversion in progress and returns the previous one.
+type Max6675 struct {
+	bus spi.Bus
+}
+
+func NewMax6675(bus spi.Bus) *Max6675 {
+	return &Max6675{bus: bus}
+}
+
+func (m *Max6675) Sample() (*temperature.Sample, error) {
+	r := make([]byte, 2)
+	if err := m.bus.Tx(make([]byte, 2), r); err != nil {
+		return nil, errors.Wrap(err, "reading max6675")
+	}
+
+	t, err := decode(uint16(r[0])<<8 | uint16(r[1]))
+	if err != nil {
+		faultsTotal.Inc()
+		return nil, err
+	}
+	return &temperature.Sample{
+		Value:      t,
+		ObservedAt: time.Now(),
+	}, nil
+}
+
+// decode converts the 16 bit reading, D14-D3 being the temperature and D2
+// flagging an open thermocouple
+func decode(v uint16) (float32, error) {
+	if v&_OPEN_BIT != 0 {
+		return 0, ErrOpenThermocouple
+	}
+	return float32(v>>3) * _RESOLUTION, nil
+}
@@ -0,0 +1,37 @@
+package max6675
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/luiccn/espresso-controller/internal/espresso/spi"
+)
+
+func TestMax6675_Sample(t *testing.T) {
+	tests := []struct {
+		name    string
+		reply   []byte
+		want    float32
+		wantErr error
+	}{
+		{name: "room temperature", reply: []byte{0x02, 0xC0}, want: 22},
+		{name: "brewing", reply: []byte{0x0B, 0xC0}, want: 94},
+		{name: "full scale", reply: []byte{0x7F, 0xF8}, want: 1023.75},
+		{name: "open thermocouple", reply: []byte{0x00, 0x04}, wantErr: ErrOpenThermocouple},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMax6675(spi.NewFakeBus(func(w []byte) []byte {
+				return tt.reply
+			}))
+			got, err := m.Sample()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Sample() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && got.Value != tt.want {
+				t.Errorf("Sample() = %v, want %v", got.Value, tt.want)
+			}
+		})
+	}
+}
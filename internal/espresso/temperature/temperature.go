@@ -13,6 +13,8 @@ import (
 	movingaverage "github.com/RobinUS2/golang-moving-average"
 )
 
+const subscriptionBufferSize = 10
+
 type Sample struct {
 	Value      float32
 	ObservedAt time.Time
@@ -59,9 +61,7 @@ func (m *Monitor) Run() {
 			m.temperatureHistory = append(m.temperatureHistory, sample)
 			m.temperatureHistoryMu.Unlock()
 
-			for _, ch := range m.subscriptionChans {
-				ch <- sample
-			}
+			m.publish(sample)
 
 			time.Sleep(time.Second)
 		}
@@ -84,19 +84,37 @@ func (m *Monitor) Run() {
 	}()
 }
 
+// publish sends the sample to all subscribers. Slow subscribers miss samples
+// rather than holding up sampling for everyone else.
+func (m *Monitor) publish(sample *Sample) {
+	m.channelMu.RLock()
+	defer m.channelMu.RUnlock()
+	for subId, ch := range m.subscriptionChans {
+		select {
+		case ch <- sample:
+		default:
+			log.Debug("Dropped temperature sample for slow subscriber", zap.Stringer("subId", subId))
+		}
+	}
+}
+
 func (m *Monitor) Subscribe() (uuid.UUID, chan *Sample) {
 	m.channelMu.Lock()
 	defer m.channelMu.Unlock()
 	subId := uuid.New()
-	subscriptionCh := make(chan *Sample)
+	subscriptionCh := make(chan *Sample, subscriptionBufferSize)
 	m.subscriptionChans[subId] = subscriptionCh
 	return subId, subscriptionCh
 }
 
+// Unsubscribe stops publishing to the subscription and closes its channel
 func (m *Monitor) Unsubscribe(subId uuid.UUID) {
 	m.channelMu.Lock()
 	defer m.channelMu.Unlock()
-	delete(m.subscriptionChans, subId)
+	if ch, ok := m.subscriptionChans[subId]; ok {
+		delete(m.subscriptionChans, subId)
+		close(ch)
+	}
 }
 
 func (m *Monitor) GetHistory() []*Sample {
@@ -0,0 +1,66 @@
+package espresso
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/luiccn/espresso-controller/internal/espresso/spi"
+	"github.com/luiccn/espresso-controller/internal/espresso/temperature"
+	"github.com/luiccn/espresso-controller/internal/espresso/temperature/ds18b20"
+	"github.com/luiccn/espresso-controller/internal/espresso/temperature/max31855"
+	"github.com/luiccn/espresso-controller/internal/espresso/temperature/max31865"
+	"github.com/luiccn/espresso-controller/internal/espresso/temperature/max6675"
+)
+
+// boilerThermometerSPIMode returns the SPI mode of the boiler thermometer's
+// amplifier
+func boilerThermometerSPIMode(sensor string) (spi.Mode, error) {
+	switch sensor {
+	case max31865.Name, "":
+		return max31865.SPIMode, nil
+	case max6675.Name:
+		return max6675.SPIMode, nil
+	case max31855.Name:
+		return max31855.SPIMode, nil
+	default:
+		return 0, fmt.Errorf("unknown boiler thermometer sensor %q, expected one of [%s, %s, %s]", sensor, max31865.Name, max6675.Name, max31855.Name)
+	}
+}
+
+// newBoilerThermometer creates the sampler for the boiler thermometer's
+// amplifier on the bus
+func newBoilerThermometer(c Configuration, bus spi.Bus) (temperature.Sampler, error) {
+	switch c.BoilerThermSensor {
+	case max31865.Name, "":
+		return max31865.NewMax31865(bus, max31865.Config{
+			RTDNominal:         c.BoilerThermRTDNominal,
+			RefResistor:        c.BoilerThermRefResistor,
+			Wires:              c.BoilerThermWires,
+			FilterHz:           c.BoilerThermFilterHz,
+			LowFaultThreshold:  c.BoilerThermLowFaultThreshold,
+			HighFaultThreshold: c.BoilerThermHighFaultThreshold,
+		})
+	case max6675.Name:
+		return max6675.NewMax6675(bus), nil
+	case max31855.Name:
+		return max31855.NewMax31855(bus), nil
+	default:
+		return nil, fmt.Errorf("unknown boiler thermometer sensor %q, expected one of [%s, %s, %s]", c.BoilerThermSensor, max31865.Name, max6675.Name, max31855.Name)
+	}
+}
+
+// newOneWireMonitor starts monitoring the ds18b20 with the id under the w1
+// sysfs root. It returns nil when no id is configured, the sensor being
+// optional.
+func newOneWireMonitor(root string, id string) (*temperature.Monitor, error) {
+	if id == "" {
+		return nil, nil
+	}
+	sensor, err := ds18b20.NewDS18B20(root, id)
+	if err != nil {
+		return nil, err
+	}
+	monitor := temperature.NewMonitor(sensor, time.Second)
+	monitor.Run()
+	return monitor, nil
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"embed"
+	"time"
 
 	"github.com/luiccn/espresso-controller/cmd/espresso/cmdutil"
 	"github.com/luiccn/espresso-controller/cmd/espresso/config"
@@ -13,14 +14,76 @@ import (
 )
 
 var configKeys = []config.Key{
+	{Path: "ConfigFile", ShortFlag: "c", Description: "Path to a yaml, json or toml config file whose keys are the ones below, none if empty", Default: ""},
 	{Path: "Port", ShortFlag: "p", Description: "Port on which the espresso server should listen", Default: "8080"},
+	{Path: "GpioBackend", ShortFlag: "", Description: "The GPIO backend used to drive the hardware, either rpio or fake", Default: "rpio"},
 	{Path: "HeatingElementRelayPin", ShortFlag: "r", Description: "The GPIO connected to the heating element relay", Default: 14},
 	{Path: "PowerButtonPin", ShortFlag: "", Description: "The GPIO connected to the power button of the espresso machine", Default: 17},
+	{Path: "PowerLedPin", ShortFlag: "", Description: "The GPIO connected to the power status LED", Default: 27},
 	{Path: "PowerButtonRelayPin", ShortFlag: "", Description: "The GPIO connected to the power button relay", Default: 16},
-	{Path: "BoilerThermCsPin", ShortFlag: "", Description: "The GPIO pin connected to the boiler thermometer's max31865 chip select, aka chip enable", Default: 5},
-	{Path: "BoilerThermClkPin", ShortFlag: "", Description: "The GPIO pin connected to the boiler thermometer's max31865 clock", Default: 11},
-	{Path: "BoilerThermMisoPin", ShortFlag: "", Description: "The GPIO pin connected to the boiler thermometer's max31865 data output", Default: 9},
-	{Path: "BoilerThermMosiPin", ShortFlag: "", Description: "The GPIO pin connected to the boiler thermometer's max31865 data input", Default: 10},
+	{Path: "BoilerThermSensor", ShortFlag: "", Description: "The boiler thermometer's amplifier, max31865 for an rtd or max6675 or max31855 for a thermocouple", Default: "max31865"},
+	{Path: "BoilerThermCsPin", ShortFlag: "", Description: "The GPIO pin connected to the boiler thermometer's amplifier chip select, aka chip enable", Default: 5},
+	{Path: "BoilerThermClkPin", ShortFlag: "", Description: "The GPIO pin connected to the boiler thermometer's amplifier clock", Default: 11},
+	{Path: "BoilerThermMisoPin", ShortFlag: "", Description: "The GPIO pin connected to the boiler thermometer's amplifier data output", Default: 9},
+	{Path: "BoilerThermMosiPin", ShortFlag: "", Description: "The GPIO pin connected to the boiler thermometer's amplifier data input", Default: 10},
+	{Path: "BoilerThermTransport", ShortFlag: "", Description: "How the boiler thermometer's amplifier is read, bitbang over the GPIO pins or spidev through the kernel's SPI driver", Default: "bitbang"},
+	{Path: "BoilerThermSpiDevice", ShortFlag: "", Description: "The spidev device node of the boiler thermometer's amplifier", Default: "/dev/spidev0.0"},
+	{Path: "BoilerThermSpiSpeedHz", ShortFlag: "", Description: "The spidev clock speed in Hz for the boiler thermometer's amplifier", Default: 1000000},
+	{Path: "BoilerThermRTDNominal", ShortFlag: "", Description: "Resistance in Ω of the boiler thermometer's rtd at 0 °C, 100 for a PT100 or 1000 for a PT1000", Default: 100.0},
+	{Path: "BoilerThermRefResistor", ShortFlag: "", Description: "Resistance in Ω of the max31865 board's reference resistor, e.g. 430 for a PT100 or 4300 for a PT1000", Default: 430.0},
+	{Path: "BoilerThermWires", ShortFlag: "", Description: "Number of wires connecting the boiler thermometer's rtd, 2, 3 or 4", Default: 3},
+	{Path: "BoilerThermFilterHz", ShortFlag: "", Description: "Mains frequency in Hz the max31865 filters out, 50 or 60", Default: 60},
+	{Path: "BoilerThermLowFaultThreshold", ShortFlag: "", Description: "Boiler temperature in °C below which the max31865 flags a fault, e.g. a shorted rtd", Default: -40.0},
+	{Path: "BoilerThermHighFaultThreshold", ShortFlag: "", Description: "Boiler temperature in °C above which the max31865 flags a fault, e.g. an open rtd", Default: 250.0},
+	{Path: "W1SysfsRoot", ShortFlag: "", Description: "Directory in which the kernel lists one-wire devices", Default: "/sys/bus/w1/devices"},
+	{Path: "GroupThermId", ShortFlag: "", Description: "The one-wire id of the ds18b20 on the group head, e.g. 28-0316a2795ff2, none if empty", Default: ""},
+	{Path: "AmbientThermId", ShortFlag: "", Description: "The one-wire id of the ds18b20 measuring the ambient temperature, none if empty", Default: ""},
+	{Path: "ControlStrategy", ShortFlag: "", Description: "The boiler temperature control strategy, either pid or bangbang", Default: "pid"},
+	{Path: "AtTemperatureTolerance", ShortFlag: "", Description: "Boiler temperature in °C around the setpoint that counts as at temperature, e.g. for the power LED", Default: 1.0},
+	{Path: "Safety.MaxTemperature", ShortFlag: "", Description: "Boiler temperature in °C above which the heating element is cut off", Default: 160.0},
+	{Path: "Safety.MinPlausibleTemperature", ShortFlag: "", Description: "Boiler temperature readings in °C below this are treated as a sensor fault", Default: -20.0},
+	{Path: "Safety.MaxPlausibleTemperature", ShortFlag: "", Description: "Boiler temperature readings in °C above this are treated as a sensor fault", Default: 300.0},
+	{Path: "Safety.RunawayWindow", ShortFlag: "", Description: "Time within which the boiler temperature must rise while heating at full power", Default: 90 * time.Second},
+	{Path: "Safety.RunawayMinRise", ShortFlag: "", Description: "Rise in °C the boiler temperature must show within the runaway window while heating at full power", Default: 1.0},
+	{Path: "Safety.StuckWindow", ShortFlag: "", Description: "Time within which the boiler temperature reading must change while heating", Default: 2 * time.Minute},
+	{Path: "Safety.MaxSensorErrors", ShortFlag: "", Description: "Number of consecutive failed boiler temperature readings that cut off the heating element", Default: 3},
+	{Path: "Safety.StaleWindow", ShortFlag: "", Description: "Time without a boiler temperature reading after which the heating element is cut off", Default: 10 * time.Second},
+	{Path: "Simulate", ShortFlag: "", Description: "Replace the relays, power button and boiler thermometer with a simulated espresso machine", Default: false},
+	{Path: "Simulation.HeaterWatts", ShortFlag: "", Description: "Power of the simulated heating element in W", Default: 1100.0},
+	{Path: "Simulation.HeaterLagSeconds", ShortFlag: "", Description: "Time constant of the simulated heating element in seconds", Default: 8.0},
+	{Path: "Simulation.ThermalMass", ShortFlag: "", Description: "Heat capacity of the simulated boiler, water and group in J/K", Default: 2000.0},
+	{Path: "Simulation.AmbientLoss", ShortFlag: "", Description: "Heat lost by the simulated boiler per degree above ambient in W/K", Default: 1.0},
+	{Path: "Simulation.AmbientTemperature", ShortFlag: "", Description: "Temperature around the simulated machine in °C", Default: 22.0},
+	{Path: "Simulation.SensorLagSeconds", ShortFlag: "", Description: "Time constant of the simulated boiler thermometer in seconds", Default: 4.0},
+	{Path: "Simulation.SensorNoise", ShortFlag: "", Description: "Standard deviation of the simulated boiler thermometer's noise in °C", Default: 0.05},
+	{Path: "Simulation.BrewFlowRate", ShortFlag: "", Description: "Rate at which cold water enters the simulated boiler while brewing in ml/s", Default: 2.5},
+	{Path: "Simulation.InletTemperature", ShortFlag: "", Description: "Temperature of the water entering the simulated boiler in °C", Default: 20.0},
+	{Path: "PowerScheduleFile", ShortFlag: "", Description: "File the power schedule is saved to when changed at runtime, ~/.espresso/schedule.json if empty. It takes precedence over the PowerSchedule in the config file.", Default: ""},
+	{Path: "PowerScheduleCalendarFile", ShortFlag: "", Description: "iCalendar file whose events power on the machine in addition to the weekly schedule, ~/.espresso/schedule.ics if empty", Default: ""},
+	{Path: "PowerScheduleCalendarRefresh", ShortFlag: "", Description: "Interval at which the power schedule calendar file is checked for changes", Default: 5 * time.Minute},
+	{Path: "EventLog.Path", ShortFlag: "", Description: "File power, configuration and safety events are logged to, ~/.espresso/events.jsonl if empty", Default: ""},
+	{Path: "EventLog.MaxSize", ShortFlag: "", Description: "Size in MB at which the event log is rotated", Default: 1},
+	{Path: "EventLog.MaxBackups", ShortFlag: "", Description: "Number of rotated event logs kept", Default: 5},
+	{Path: "ShotHistoryFile", ShortFlag: "", Description: "Database the shots pulled are kept in, ~/.espresso/shots.db if empty", Default: ""},
+	{Path: "AutoOff.Duration", ShortFlag: "", Description: "Time after which a machine powered on outside of the schedule is powered off, never if 0", Default: 60 * time.Minute},
+	{Path: "AutoOff.Idle", ShortFlag: "", Description: "Count the auto-off duration from the last brew or interaction through the button or the API instead of from power on", Default: false},
+	{Path: "AutoOff.Warning", ShortFlag: "", Description: "Time before auto-off at which subscribers are warned, never if 0", Default: 5 * time.Minute},
+	{Path: "Brew.SwitchPin", ShortFlag: "", Description: "The GPIO connected to the brew switch, high while brewing. Brews are detected from the boiler temperature dropping if negative.", Default: -1},
+	{Path: "Brew.DropThreshold", ShortFlag: "", Description: "Drop of the boiler temperature in °C within the drop window that is detected as a brew", Default: 2.0},
+	{Path: "Brew.DropWindow", ShortFlag: "", Description: "Time within which the boiler temperature must drop to detect a brew", Default: 10 * time.Second},
+	{Path: "Brew.MaxShotDuration", ShortFlag: "", Description: "Time after which the shot timer stops a shot that did not stop, e.g. one started with the power button, never if 0", Default: 2 * time.Minute},
+	{Path: "BoostProfile", ShortFlag: "", Description: "Heater boost added to the pid strategy's output from the start of shots started by the brew switch or detected from the temperature, as durations and percent of the heater's power, e.g. 5s:100,20s:60. None if empty.", Default: ""},
+	{Path: "Button.ShortPress", ShortFlag: "", Description: "Action of a short press of the power button: toggle, total_off, suppress_schedule, toggle_steam, start_shot_timer or none", Default: "toggle"},
+	{Path: "Button.LongPress", ShortFlag: "", Description: "Action of a long press of the power button", Default: "total_off"},
+	{Path: "Button.DoublePress", ShortFlag: "", Description: "Action of a double press of the power button. Short presses are run without waiting for a second one if none.", Default: "suppress_schedule"},
+	{Path: "Button.LongPressDuration", ShortFlag: "", Description: "Time the power button must be held for a long press", Default: time.Second},
+	{Path: "Button.DoublePressWindow", ShortFlag: "", Description: "Time after a short press of the power button within which a second press makes a double press", Default: 400 * time.Millisecond},
+	{Path: "Steam.Temperature", ShortFlag: "", Description: "Boiler temperature in °C while steaming, 100 to 150 and at least 5 below Safety.MaxTemperature", Default: 140.0},
+	{Path: "Steam.P", ShortFlag: "", Description: "Proportional gain of the pid strategy while steaming", Default: 16.0},
+	{Path: "Steam.I", ShortFlag: "", Description: "Integral gain of the pid strategy while steaming", Default: 0.15},
+	{Path: "Steam.D", ShortFlag: "", Description: "Derivative gain of the pid strategy while steaming", Default: 100.0},
+	{Path: "Steam.Timeout", ShortFlag: "", Description: "Time after which steam mode reverts to brew mode, never if 0", Default: 10 * time.Minute},
+	{Path: "Steam.SwitchPin", ShortFlag: "", Description: "The GPIO connected to the steam switch, high while steaming, none if negative", Default: -1},
 	{Path: "Verbose", ShortFlag: "v", Description: "verbose output", Default: false},
 }
 
@@ -44,6 +107,13 @@ func newRootCmd() *cobra.Command {
 			log.Info(cmdutil.Logo)
 			log.Info("For more information, go to https://github.com/luiccn/espresso-controller\n")
 
+			if configFile := viper.GetString("ConfigFile"); configFile != "" {
+				viper.SetConfigFile(configFile)
+				if err := viper.ReadInConfig(); err != nil {
+					log.Fatal("Reading config file: %s\n", err.Error())
+				}
+			}
+
 			if verbose := viper.GetBool("Verbose"); verbose {
 				serverLogger.UseDevLogger()
 			} else {
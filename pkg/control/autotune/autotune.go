@@ -0,0 +1,330 @@
+// Package autotune finds PID gains with the relay method of Åström and
+// Hägglund: the heating element is switched between two duty factors around
+// the setpoint, making the boiler temperature oscillate. The amplitude and
+// period of the oscillation give the ultimate gain and period of the boiler,
+// from which gains are computed with a tuning rule.
+// https://en.wikipedia.org/wiki/Ziegler%E2%80%93Nichols_method
+package autotune
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/luiccn/espresso-controller/internal/espresso/heating_element"
+	"github.com/luiccn/espresso-controller/internal/espresso/power_manager"
+	"github.com/luiccn/espresso-controller/internal/espresso/temperature"
+	"github.com/luiccn/espresso-controller/internal/log"
+	"github.com/luiccn/espresso-controller/pkg/control"
+	"github.com/luiccn/espresso-controller/pkg/control/pid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+type Rule string
+
+const (
+	ZieglerNichols Rule = "ziegler-nichols"
+	TyreusLuyben   Rule = "tyreus-luyben"
+)
+
+type Phase string
+
+const (
+	// PhaseHeating is the initial approach to the setpoint, which is not
+	// measured
+	PhaseHeating Phase = "heating"
+	// PhaseOscillating is the measurement of the oscillation
+	PhaseOscillating Phase = "oscillating"
+)
+
+const (
+	defaultOutputHigh float32 = 1
+	defaultOutputLow  float32 = 0
+	defaultHysteresis float32 = 0.5
+	defaultCycles     int     = 4
+	defaultTimeout            = 45 * time.Minute
+)
+
+type Options struct {
+	// Temperature to oscillate around
+	Setpoint float32
+	// Duty factors applied below and above the setpoint
+	OutputHigh float32
+	OutputLow  float32
+	// Noise band around the setpoint in which the relay does not switch
+	Hysteresis float32
+	// Number of oscillations to measure
+	Cycles  int
+	Rule    Rule
+	Timeout time.Duration
+}
+
+// withDefaults fills in unset options and validates them
+func (o Options) withDefaults() (Options, error) {
+	if o.OutputHigh == 0 {
+		o.OutputHigh = defaultOutputHigh
+	}
+	if o.Hysteresis == 0 {
+		o.Hysteresis = defaultHysteresis
+	}
+	if o.Cycles == 0 {
+		o.Cycles = defaultCycles
+	}
+	if o.Rule == "" {
+		o.Rule = ZieglerNichols
+	}
+	if o.Timeout == 0 {
+		o.Timeout = defaultTimeout
+	}
+
+	if o.OutputLow < 0 || o.OutputHigh > 1 || o.OutputLow >= o.OutputHigh {
+		return o, errors.New("relay outputs must satisfy 0 <= low < high <= 1")
+	}
+	if o.Hysteresis < 0 {
+		return o, errors.New("hysteresis must be >= 0")
+	}
+	if o.Cycles < 2 {
+		return o, errors.New("at least 2 cycles must be measured")
+	}
+	if o.Rule != ZieglerNichols && o.Rule != TyreusLuyben {
+		return o, fmt.Errorf("unknown tuning rule %q, expected one of [%s, %s]", o.Rule, ZieglerNichols, TyreusLuyben)
+	}
+	return o, nil
+}
+
+type Progress struct {
+	Phase       Phase
+	Cycle       int
+	Cycles      int
+	Temperature float32
+	DutyFactor  float32
+	ObservedAt  time.Time
+}
+
+type Result struct {
+	Rule Rule
+	// Ultimate gain in duty factor per °C
+	UltimateGain float32
+	// Ultimate period of the oscillation
+	UltimatePeriod time.Duration
+	// Amplitude of the oscillation in °C
+	Amplitude float32
+	// Gains in the units of the pid strategy, i.e. percent duty per °C of
+	// error, per °C*s of integrated error and per °C/s of slope
+	P float32
+	I float32
+	D float32
+}
+
+// Parameters returns the gains as parameters of the pid strategy
+func (r *Result) Parameters() control.Parameters {
+	return control.Parameters{
+		pid.ParamP: r.P,
+		pid.ParamI: r.I,
+		pid.ParamD: r.D,
+	}
+}
+
+// gains computes the PID gains for the ultimate gain ku and period pu (in
+// seconds) according to the rule
+func gains(rule Rule, ku float64, pu float64) (p, i, d float64) {
+	var kp, ti, td float64
+	switch rule {
+	case TyreusLuyben:
+		kp, ti, td = ku/2.2, 2.2*pu, pu/6.3
+	default:
+		kp, ti, td = 0.6*ku, pu/2, pu/8
+	}
+	// the pid strategy's gains are in percent
+	kp *= 100
+	return kp, kp / ti, kp * td
+}
+
+// relay is the relay experiment, fed one temperature sample at a time
+type relay struct {
+	o Options
+
+	phase   Phase
+	heating bool
+	// time of the last switch from low to high output
+	risingAt time.Time
+	// extreme temperature since the last switch
+	extreme float32
+
+	peaks   []float32
+	troughs []float32
+	periods []time.Duration
+}
+
+func newRelay(o Options) *relay {
+	return &relay{
+		o:       o,
+		phase:   PhaseHeating,
+		heating: true,
+	}
+}
+
+// update consumes a sample and returns the duty factor to apply, and whether
+// enough cycles have been measured. Troughs are the minimums while heating and
+// peaks the maximums while cooling. The overshoot of the initial heat-up is
+// not measured.
+func (r *relay) update(s *temperature.Sample) (float32, bool) {
+	if r.heating {
+		if s.Value < r.extreme {
+			r.extreme = s.Value
+		}
+		if s.Value > r.o.Setpoint+r.o.Hysteresis {
+			if r.phase == PhaseOscillating {
+				r.troughs = append(r.troughs, r.extreme)
+			}
+			r.phase = PhaseOscillating
+			r.heating = false
+			r.extreme = s.Value
+		}
+	} else {
+		if s.Value > r.extreme {
+			r.extreme = s.Value
+		}
+		if s.Value < r.o.Setpoint-r.o.Hysteresis {
+			if !r.risingAt.IsZero() {
+				r.peaks = append(r.peaks, r.extreme)
+				r.periods = append(r.periods, s.ObservedAt.Sub(r.risingAt))
+			}
+			r.risingAt = s.ObservedAt
+			r.heating = true
+			r.extreme = s.Value
+		}
+	}
+
+	done := len(r.periods) >= r.o.Cycles && len(r.troughs) >= r.o.Cycles
+	if r.heating {
+		return r.o.OutputHigh, done
+	}
+	return r.o.OutputLow, done
+}
+
+func (r *relay) cycle() int {
+	return len(r.periods)
+}
+
+func (r *relay) result() (*Result, error) {
+	if len(r.periods) == 0 || len(r.peaks) == 0 || len(r.troughs) == 0 {
+		return nil, errors.New("no oscillation was measured")
+	}
+
+	amplitude := (average(r.peaks) - average(r.troughs)) / 2
+	if amplitude <= float64(r.o.Hysteresis) {
+		return nil, fmt.Errorf("oscillation amplitude %.2f °C is within the hysteresis, increase the relay outputs' difference", amplitude)
+	}
+
+	var periodSum time.Duration
+	for _, p := range r.periods {
+		periodSum += p
+	}
+	period := periodSum / time.Duration(len(r.periods))
+
+	// describing function of a relay with hysteresis
+	d := float64(r.o.OutputHigh-r.o.OutputLow) / 2
+	eps := float64(r.o.Hysteresis)
+	ku := 4 * d / (math.Pi * math.Sqrt(amplitude*amplitude-eps*eps))
+
+	p, i, dGain := gains(r.o.Rule, ku, period.Seconds())
+	return &Result{
+		Rule:           r.o.Rule,
+		UltimateGain:   float32(ku),
+		UltimatePeriod: period,
+		Amplitude:      float32(amplitude),
+		P:              float32(p),
+		I:              float32(i),
+		D:              float32(dGain),
+	}, nil
+}
+
+func average(vs []float32) float64 {
+	sum := 0.0
+	for _, v := range vs {
+		sum += float64(v)
+	}
+	return sum / float64(len(vs))
+}
+
+// Autotuner runs the relay experiment on the machine. No control strategy may
+// drive the heating element while it is running.
+type Autotuner struct {
+	heatingElement     *heating_element.HeatingElement
+	temperatureMonitor *temperature.Monitor
+	powerManager       *power_manager.PowerManager
+}
+
+func NewAutotuner(heatingElem *heating_element.HeatingElement, powerManager *power_manager.PowerManager, monitor *temperature.Monitor) *Autotuner {
+	return &Autotuner{
+		heatingElement:     heatingElem,
+		temperatureMonitor: monitor,
+		powerManager:       powerManager,
+	}
+}
+
+// Run drives the heating element until enough oscillations are measured, the
+// context is done or the machine is powered off. progress is called with
+// every temperature sample. The heating element is left off.
+func (a *Autotuner) Run(ctx context.Context, o Options, progress func(Progress)) (*Result, error) {
+	o, err := o.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+	if a.powerManager.IsMachinePowerOff() {
+		return nil, errors.New("the machine must be powered on to autotune")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, o.Timeout)
+	defer cancel()
+
+	subId, subCh := a.temperatureMonitor.Subscribe()
+	defer a.temperatureMonitor.Unsubscribe(subId)
+	defer a.heatingElement.SetDutyFactor(0)
+
+	log.Info("Starting autotune", zap.Float32("setpoint", o.Setpoint), zap.String("rule", string(o.Rule)))
+
+	r := newRelay(o)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, errors.Wrap(ctx.Err(), "autotune did not finish")
+		case sample, ok := <-subCh:
+			if !ok {
+				return nil, errors.New("temperature monitor stopped publishing")
+			}
+			if a.powerManager.IsMachinePowerOff() {
+				return nil, errors.New("the machine was powered off while autotuning")
+			}
+
+			out, done := r.update(sample)
+			a.heatingElement.SetDutyFactor(out)
+			progress(Progress{
+				Phase:       r.phase,
+				Cycle:       r.cycle(),
+				Cycles:      o.Cycles,
+				Temperature: sample.Value,
+				DutyFactor:  out,
+				ObservedAt:  sample.ObservedAt,
+			})
+
+			if done {
+				result, err := r.result()
+				if err != nil {
+					return nil, err
+				}
+				log.Info("Finished autotune",
+					zap.Float32("ultimateGain", result.UltimateGain),
+					zap.Duration("ultimatePeriod", result.UltimatePeriod),
+					zap.Float32("p", result.P),
+					zap.Float32("i", result.I),
+					zap.Float32("d", result.D),
+				)
+				return result, nil
+			}
+		}
+	}
+}
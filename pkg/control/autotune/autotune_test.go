@@ -0,0 +1,109 @@
+package autotune
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/luiccn/espresso-controller/internal/espresso/simulation"
+)
+
+type duty struct {
+	value float32
+}
+
+func (d *duty) AppliedDutyFactor() float32 {
+	return d.value
+}
+
+func TestGains(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  Rule
+		ku    float64
+		pu    float64
+		wantP float64
+		wantI float64
+		wantD float64
+	}{
+		{
+			name:  "Ziegler-Nichols",
+			rule:  ZieglerNichols,
+			ku:    0.5,
+			pu:    100,
+			wantP: 30,
+			wantI: 0.6,
+			wantD: 375,
+		},
+		{
+			name:  "Tyreus-Luyben",
+			rule:  TyreusLuyben,
+			ku:    0.22,
+			pu:    63,
+			wantP: 10,
+			wantI: 10 / 138.6,
+			wantD: 100,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, i, d := gains(tt.rule, tt.ku, tt.pu)
+			for _, c := range []struct {
+				name      string
+				got, want float64
+			}{{"p", p, tt.wantP}, {"i", i, tt.wantI}, {"d", d, tt.wantD}} {
+				if math.Abs(c.got-c.want) > 1e-6*math.Max(1, c.want) {
+					t.Errorf("gains() %s = %v, want %v", c.name, c.got, c.want)
+				}
+			}
+		})
+	}
+}
+
+func TestRelay_SimulatedBoiler(t *testing.T) {
+	heater := &duty{}
+	boiler := simulation.NewBoiler(simulation.Parameters{
+		HeaterWatts:        1100,
+		HeaterLagSeconds:   8,
+		ThermalMass:        2000,
+		AmbientLoss:        1,
+		AmbientTemperature: 22,
+		SensorLagSeconds:   4,
+	}, heater)
+
+	o, err := Options{Setpoint: 93}.withDefaults()
+	if err != nil {
+		t.Fatalf("withDefaults() error = %v", err)
+	}
+	r := newRelay(o)
+
+	now := time.Unix(0, 0)
+	done := false
+	for elapsed := 0; elapsed < 3600 && !done; elapsed++ {
+		for i := 0; i < 10; i++ {
+			boiler.Step(100 * time.Millisecond)
+		}
+		now = now.Add(time.Second)
+		sample, _ := boiler.Sample()
+		sample.ObservedAt = now
+		heater.value, done = r.update(sample)
+	}
+	if !done {
+		t.Fatalf("relay did not finish after an hour, measured %d cycles", r.cycle())
+	}
+
+	result, err := r.result()
+	if err != nil {
+		t.Fatalf("result() error = %v", err)
+	}
+	if result.UltimatePeriod < 10*time.Second || result.UltimatePeriod > 5*time.Minute {
+		t.Errorf("UltimatePeriod = %v, want a boiler's period of tens of seconds", result.UltimatePeriod)
+	}
+	if result.Amplitude <= o.Hysteresis {
+		t.Errorf("Amplitude = %v, want > hysteresis %v", result.Amplitude, o.Hysteresis)
+	}
+	if result.P <= 0 || result.I <= 0 || result.D <= 0 {
+		t.Errorf("gains = (%v, %v, %v), want positive", result.P, result.I, result.D)
+	}
+	t.Logf("ku=%v pu=%v a=%v p=%v i=%v d=%v", result.UltimateGain, result.UltimatePeriod, result.Amplitude, result.P, result.I, result.D)
+}
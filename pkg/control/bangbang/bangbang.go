@@ -0,0 +1,145 @@
+package bangbang
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/luiccn/espresso-controller/internal/espresso/heating_element"
+	"github.com/luiccn/espresso-controller/internal/espresso/power_manager"
+	"github.com/luiccn/espresso-controller/internal/espresso/temperature"
+	"github.com/luiccn/espresso-controller/internal/log"
+	"github.com/luiccn/espresso-controller/pkg/control"
+	"go.uber.org/zap"
+)
+
+const (
+	// Name identifies the bang-bang strategy in configuration
+	Name = "bangbang"
+
+	ParamHysteresis = "hysteresis"
+
+	defaultHysteresis float32 = 1
+)
+
+// BangBang is a temperature controller that switches the heating element
+// fully on below the target temperature and fully off above it. A hysteresis
+// band centered on the target keeps it from chattering the relay. It
+// satisfies the control.Strategy interface.
+// https://en.wikipedia.org/wiki/Bang%E2%80%93bang_control
+type BangBang struct {
+	mu                 sync.RWMutex
+	hysteresis         float32
+	targetTemperature  control.TargetTemperature
+	heatingElement     *heating_element.HeatingElement
+	temperatureMonitor *temperature.Monitor
+	powerManager       *power_manager.PowerManager
+	temperatureSubId   uuid.UUID
+	// heating is only accessed by the goroutine started by Run
+	heating bool
+}
+
+func NewBangBang(heatingElem *heating_element.HeatingElement, powerManager *power_manager.PowerManager, sampler *temperature.Monitor) (*BangBang, error) {
+	return &BangBang{
+		hysteresis:         defaultHysteresis,
+		targetTemperature:  control.TargetTemperature{Value: 93, SetAt: time.Now()},
+		heatingElement:     heatingElem,
+		powerManager:       powerManager,
+		temperatureMonitor: sampler,
+	}, nil
+}
+
+func (c *BangBang) Run() error {
+	subId, subCh := c.temperatureMonitor.Subscribe()
+	c.temperatureSubId = subId
+
+	go func() {
+		for sample := range subCh {
+			c.heatingElement.SetDutyFactor(c.update(sample.Value, c.powerManager.IsMachinePowerOn()))
+		}
+	}()
+	return nil
+}
+
+// update returns the duty factor for a temperature sample, switching the
+// heating element on below the hysteresis band and off above it
+func (c *BangBang) update(temperature float32, poweredOn bool) float32 {
+	if !poweredOn {
+		c.heating = false
+		return 0
+	}
+
+	c.mu.RLock()
+	hysteresis := c.hysteresis
+	targetTemperature := c.targetTemperature.Value
+	c.mu.RUnlock()
+
+	if temperature < targetTemperature-hysteresis/2 {
+		c.heating = true
+	} else if temperature > targetTemperature+hysteresis/2 {
+		c.heating = false
+	}
+
+	var out float32
+	if c.heating {
+		out = 1
+	}
+
+	log.Debug("Setting duty factor",
+		zap.Float32("dutyFactor", out),
+		zap.Float32("curTemperature", temperature),
+		zap.Float32("targetTemperature", targetTemperature),
+	)
+	return out
+}
+
+func (c *BangBang) GetTargetTemperature() control.TargetTemperature {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.targetTemperature
+}
+
+func (c *BangBang) SetTargetTemperature(temperature float32) control.TargetTemperature {
+	targetTemperature := control.TargetTemperature{
+		Value: temperature,
+		SetAt: time.Now(),
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.targetTemperature = targetTemperature
+	return targetTemperature
+}
+
+func (c *BangBang) GetParameters() control.Parameters {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return control.Parameters{
+		ParamHysteresis: c.hysteresis,
+	}
+}
+
+func (c *BangBang) SetParameters(params control.Parameters) error {
+	for name, value := range params {
+		switch name {
+		case ParamHysteresis:
+			if value < 0 {
+				return fmt.Errorf("hysteresis must be >= 0")
+			}
+		default:
+			return fmt.Errorf("unknown bang-bang parameter %q", name)
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if v, ok := params[ParamHysteresis]; ok {
+		c.hysteresis = v
+	}
+	return nil
+}
+
+func (c *BangBang) Shutdown() error {
+	c.temperatureMonitor.Unsubscribe(c.temperatureSubId)
+	return nil
+}
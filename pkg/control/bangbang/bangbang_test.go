@@ -0,0 +1,115 @@
+package bangbang
+
+import (
+	"testing"
+
+	"github.com/luiccn/espresso-controller/pkg/control"
+)
+
+type sample struct {
+	temperature float32
+	poweredOn   bool
+}
+
+func TestBangBang_update(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples []sample
+		want    float32
+	}{
+		{
+			name:    "on below the band",
+			samples: []sample{{92.4, true}},
+			want:    1,
+		},
+		{
+			name:    "off above the band",
+			samples: []sample{{92.4, true}, {93.6, true}},
+			want:    0,
+		},
+		{
+			name:    "stays on inside the band",
+			samples: []sample{{92.4, true}, {93.4, true}},
+			want:    1,
+		},
+		{
+			name:    "stays off inside the band",
+			samples: []sample{{93.6, true}, {92.6, true}},
+			want:    0,
+		},
+		{
+			name:    "off inside the band at start",
+			samples: []sample{{93, true}},
+			want:    0,
+		},
+		{
+			name:    "off when powered off",
+			samples: []sample{{80, false}},
+			want:    0,
+		},
+		{
+			name:    "off inside the band after being powered off",
+			samples: []sample{{92.4, true}, {92.6, false}, {92.6, true}},
+			want:    0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := NewBangBang(nil, nil, nil)
+			c.SetTargetTemperature(93)
+			var got float32
+			for _, s := range tt.samples {
+				got = c.update(s.temperature, s.poweredOn)
+			}
+			if got != tt.want {
+				t.Errorf("update() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBangBang_SetParameters(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  control.Parameters
+		want    float32
+		wantErr bool
+	}{
+		{
+			name:   "hysteresis",
+			params: control.Parameters{ParamHysteresis: 2},
+			want:   2,
+		},
+		{
+			name:   "zero hysteresis",
+			params: control.Parameters{ParamHysteresis: 0},
+			want:   0,
+		},
+		{
+			name:    "negative hysteresis",
+			params:  control.Parameters{ParamHysteresis: -1},
+			want:    defaultHysteresis,
+			wantErr: true,
+		},
+		{
+			name:    "unknown parameter",
+			params:  control.Parameters{ParamHysteresis: 2, "p": 1},
+			want:    defaultHysteresis,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := NewBangBang(nil, nil, nil)
+			err := c.SetParameters(tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SetParameters() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := c.GetParameters()[ParamHysteresis]; got != tt.want {
+				t.Errorf("hysteresis = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
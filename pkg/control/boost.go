@@ -0,0 +1,98 @@
+package control
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxBoostDuration bounds the length of a boost profile, longer than any shot
+const maxBoostDuration = 2 * time.Minute
+
+// BoostStep adds Output percent of the heating element's power to the
+// strategy's output for Duration
+type BoostStep struct {
+	Duration time.Duration
+	Output   float32
+}
+
+// BoostProfile is a feed-forward heater boost, its steps following each other
+// from the start of a shot. It heats ahead of the cold water entering the
+// boiler rather than after the temperature dropped.
+type BoostProfile []BoostStep
+
+// At returns the output added elapsed into the profile, 0 outside of it
+func (p BoostProfile) At(elapsed time.Duration) float32 {
+	if elapsed < 0 {
+		return 0
+	}
+	for _, s := range p {
+		if elapsed < s.Duration {
+			return s.Output
+		}
+		elapsed -= s.Duration
+	}
+	return 0
+}
+
+// Duration is the total duration of the steps
+func (p BoostProfile) Duration() time.Duration {
+	var d time.Duration
+	for _, s := range p {
+		d += s.Duration
+	}
+	return d
+}
+
+func (p BoostProfile) Validate() error {
+	for i, s := range p {
+		if s.Duration <= 0 {
+			return fmt.Errorf("boost step %d must last longer than 0", i+1)
+		}
+		if s.Output < 0 || s.Output > 100 {
+			return fmt.Errorf("boost step %d output must be in [0, 100], got %v", i+1, s.Output)
+		}
+	}
+	if d := p.Duration(); d > maxBoostDuration {
+		return fmt.Errorf("boost profile must last at most %s, got %s", maxBoostDuration, d)
+	}
+	return nil
+}
+
+// String formats the profile as parsed by ParseBoostProfile
+func (p BoostProfile) String() string {
+	steps := make([]string, len(p))
+	for i, s := range p {
+		steps[i] = fmt.Sprintf("%s:%g", s.Duration, s.Output)
+	}
+	return strings.Join(steps, ",")
+}
+
+// ParseBoostProfile parses a comma separated list of steps, each a duration
+// and the output in percent, e.g. "5s:80,20s:50". It is empty if s is.
+func ParseBoostProfile(s string) (BoostProfile, error) {
+	var p BoostProfile
+	if strings.TrimSpace(s) == "" {
+		return p, nil
+	}
+	for _, step := range strings.Split(s, ",") {
+		parts := strings.Split(strings.TrimSpace(step), ":")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid boost step %q, want duration:output", step)
+		}
+		d, err := time.ParseDuration(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid boost step duration %q", parts[0])
+		}
+		out, err := strconv.ParseFloat(parts[1], 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid boost step output %q", parts[1])
+		}
+		p = append(p, BoostStep{Duration: d, Output: float32(out)})
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
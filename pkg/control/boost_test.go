@@ -0,0 +1,64 @@
+package control
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBoostProfile_At(t *testing.T) {
+	p := BoostProfile{{Duration: 5 * time.Second, Output: 80}, {Duration: 20 * time.Second, Output: 50}}
+	tests := []struct {
+		elapsed time.Duration
+		want    float32
+	}{
+		{elapsed: -time.Second, want: 0},
+		{elapsed: 0, want: 80},
+		{elapsed: 4900 * time.Millisecond, want: 80},
+		{elapsed: 5 * time.Second, want: 50},
+		{elapsed: 24 * time.Second, want: 50},
+		{elapsed: 25 * time.Second, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.elapsed.String(), func(t *testing.T) {
+			if got := p.At(tt.elapsed); got != tt.want {
+				t.Errorf("At() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseBoostProfile(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    BoostProfile
+		wantErr bool
+	}{
+		{s: "", want: nil},
+		{s: "5s:80, 20s:50", want: BoostProfile{{Duration: 5 * time.Second, Output: 80}, {Duration: 20 * time.Second, Output: 50}}},
+		{s: "1.5s:100", want: BoostProfile{{Duration: 1500 * time.Millisecond, Output: 100}}},
+		{s: "5s", wantErr: true},
+		{s: "5:80", wantErr: true},
+		{s: "5s:high", wantErr: true},
+		{s: "0s:80", wantErr: true},
+		{s: "5s:120", wantErr: true},
+		{s: "5s:-10", wantErr: true},
+		{s: "1m:50,90s:50", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := ParseBoostProfile(tt.s)
+			if (err != nil) != tt.wantErr || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseBoostProfile() = %v, %v, want %v, wantErr %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+
+	// String formats what ParseBoostProfile parses
+	p := BoostProfile{{Duration: 5 * time.Second, Output: 80}, {Duration: 1500 * time.Millisecond, Output: 42.5}}
+	if got, err := ParseBoostProfile(p.String()); err != nil || !reflect.DeepEqual(got, p) {
+		t.Errorf("ParseBoostProfile(%q) = %v, %v, want %v", p.String(), got, err, p)
+	}
+}
@@ -8,3 +8,39 @@ type TargetTemperature struct {
 	Value float32
 	SetAt time.Time
 }
+
+// Parameters are the tunable parameters of a Strategy by name, e.g. "p" for
+// the proportional gain of a PID controller.
+type Parameters map[string]float32
+
+// Strategy controls the boiler temperature by driving the heating element.
+type Strategy interface {
+	// Run starts controlling the temperature in the background
+	Run() error
+	// Shutdown stops controlling the temperature
+	Shutdown() error
+	GetTargetTemperature() TargetTemperature
+	SetTargetTemperature(temperature float32) TargetTemperature
+	// GetParameters returns all of the strategy's parameters
+	GetParameters() Parameters
+	// SetParameters updates the given parameters, leaving the others as they
+	// are. Nothing is updated if any parameter is unknown or invalid.
+	SetParameters(params Parameters) error
+}
+
+// Resetter is implemented by strategies whose state builds up around the
+// setpoint, e.g. the integral of a PID controller
+type Resetter interface {
+	// Reset discards the state, so that the strategy reacts to the next
+	// setpoint at once rather than transferring to it smoothly, e.g. when
+	// switching between brewing and steaming
+	Reset()
+}
+
+// Booster is implemented by strategies that can add a feed-forward boost to
+// their output
+type Booster interface {
+	// Boost adds the profile to the output from start on, replacing the boost
+	// in progress. An empty profile ends it.
+	Boost(profile BoostProfile, start time.Time)
+}
@@ -0,0 +1,141 @@
+package pid
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// the output is a duty factor in percent
+	outputMin = 0
+	outputMax = 100
+	// samples further apart than this, e.g. after the thermometer failed or
+	// the machine was powered off, restart the derivative rather than taking
+	// a slope over the gap
+	maxSampleInterval = 10 * time.Second
+)
+
+// Gains of the controller in percent duty per °C of error, per °C*s of
+// integrated error and per °C/s of slope. The slope is that of the
+// measurement, low-pass filtered with the time constant DFilter.
+type Gains struct {
+	P       float64
+	I       float64
+	D       float64
+	DFilter time.Duration
+}
+
+// Terms are the contributions to the output of the last update, in percent
+type Terms struct {
+	P           float64
+	I           float64
+	D           float64
+	FeedForward float64
+	Output      float64
+}
+
+// controller is a discrete PID controller:
+//
+//	u = P*e + ∫ I*e dt - D*dy/dt + f
+//
+// with e the error, y the measurement and f a feed-forward term, integrated
+// over the actual time between samples. The derivative acts on the filtered measurement so that
+// setpoint changes do not kick the output. The integral is kept as its
+// contribution to the output, so that changing I does not bump the output,
+// and changes of P, D and the setpoint are absorbed into it. It is not
+// integrated while the output saturates in the direction of the error, and is
+// bounded by the output range, so that it does not wind up while heating up.
+type controller struct {
+	gains    Gains
+	setpoint float64
+
+	started         bool
+	lastAt          time.Time
+	lastMeasurement float64
+	// filtered slope of the measurement, °C/s
+	slope    float64
+	integral float64
+	terms    Terms
+}
+
+func newController(gains Gains, setpoint float64) *controller {
+	return &controller{gains: gains, setpoint: setpoint}
+}
+
+// setGains changes the gains, keeping the output as it is
+func (c *controller) setGains(gains Gains) {
+	if gains == c.gains {
+		return
+	}
+	if c.started {
+		e := c.setpoint - c.lastMeasurement
+		before := c.gains.P*e - c.gains.D*c.slope
+		after := gains.P*e - gains.D*c.slope
+		c.integral = clamp(c.integral+before-after, -outputMax, outputMax)
+	}
+	c.gains = gains
+}
+
+// setSetpoint changes the setpoint, keeping the output as it is. The output
+// then follows the new error through the integral.
+func (c *controller) setSetpoint(setpoint float64) {
+	if setpoint == c.setpoint {
+		return
+	}
+	if c.started {
+		c.integral = clamp(c.integral+c.gains.P*(c.setpoint-setpoint), -outputMax, outputMax)
+	}
+	c.setpoint = setpoint
+}
+
+// reset forgets the integral and the past measurements, e.g. when the
+// heating element was not controlled for a while
+func (c *controller) reset() {
+	c.started = false
+	c.slope = 0
+	c.integral = 0
+	c.terms = Terms{}
+}
+
+// update computes the output in percent for the measurement taken at the
+// given time, adding feedForward percent
+func (c *controller) update(measurement float64, at time.Time, feedForward float64) float64 {
+	dt := at.Sub(c.lastAt)
+	if !c.started || dt <= 0 || dt > maxSampleInterval {
+		// nothing to integrate or differentiate over yet
+		c.started = true
+		c.slope = 0
+		dt = 0
+	} else {
+		raw := (measurement - c.lastMeasurement) / dt.Seconds()
+		c.slope += lowPass(dt, c.gains.DFilter) * (raw - c.slope)
+	}
+	c.lastAt = at
+	c.lastMeasurement = measurement
+
+	e := c.setpoint - measurement
+	p := c.gains.P * e
+	d := -c.gains.D * c.slope
+	// conditional integration
+	unsaturated := p + c.integral + d + feedForward
+	if !(unsaturated >= outputMax && e > 0) && !(unsaturated <= outputMin && e < 0) {
+		c.integral = clamp(c.integral+c.gains.I*e*dt.Seconds(), -outputMax, outputMax)
+	}
+	out := clamp(p+c.integral+d+feedForward, outputMin, outputMax)
+
+	c.terms = Terms{P: p, I: c.integral, D: d, FeedForward: feedForward, Output: out}
+	return out
+}
+
+// lowPass is the smoothing factor of a first order low-pass filter with time
+// constant tau sampled every dt
+func lowPass(dt time.Duration, tau time.Duration) float64 {
+	if tau <= 0 {
+		return 1
+	}
+	return dt.Seconds() / (tau.Seconds() + dt.Seconds())
+}
+
+func clamp(v float64, min float64, max float64) float64 {
+	return math.Max(min, math.Min(max, v))
+}
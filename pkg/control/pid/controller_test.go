@@ -0,0 +1,303 @@
+package pid
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/luiccn/espresso-controller/internal/espresso/simulation"
+	"github.com/luiccn/espresso-controller/pkg/control"
+)
+
+type sample struct {
+	measurement float64
+	// seconds since the first sample
+	at float64
+}
+
+func TestController_update(t *testing.T) {
+	tests := []struct {
+		name        string
+		gains       Gains
+		samples     []sample
+		feedForward float64
+		want        Terms
+	}{
+		{
+			name:    "proportional",
+			gains:   Gains{P: 2},
+			samples: []sample{{90, 0}},
+			want:    Terms{P: 6, Output: 6},
+		},
+		{
+			name:    "integral over the sample intervals",
+			gains:   Gains{I: 0.5},
+			samples: []sample{{91, 0}, {91, 1}, {91, 4}},
+			want:    Terms{I: 4, Output: 4},
+		},
+		{
+			name:    "derivative on measurement",
+			gains:   Gains{D: 10},
+			samples: []sample{{90, 0}, {92, 2}},
+			want:    Terms{D: -10, Output: 0},
+		},
+		{
+			name:    "filtered derivative",
+			gains:   Gains{P: 10, D: 10, DFilter: time.Second},
+			samples: []sample{{90, 0}, {91, 1}},
+			want:    Terms{P: 20, D: -5, Output: 15},
+		},
+		{
+			name:    "derivative restarted after a gap",
+			gains:   Gains{P: 10, I: 1, D: 10},
+			samples: []sample{{80, 0}, {90, 30}},
+			want:    Terms{P: 30, Output: 30},
+		},
+		{
+			name:    "saturated",
+			gains:   Gains{P: 10},
+			samples: []sample{{50, 0}},
+			want:    Terms{P: 430, Output: 100},
+		},
+		{
+			name:    "no windup while saturated",
+			gains:   Gains{P: 10, I: 1},
+			samples: []sample{{50, 0}, {50, 1}, {50, 2}, {50, 3}},
+			want:    Terms{P: 430, Output: 100},
+		},
+		{
+			name:    "integral bounded",
+			gains:   Gains{I: 10},
+			samples: []sample{{88, 0}, {88, 1}, {88, 2}, {88, 3}},
+			want:    Terms{I: 100, Output: 100},
+		},
+		{
+			name:        "feed-forward",
+			gains:       Gains{P: 2},
+			samples:     []sample{{90, 0}},
+			feedForward: 10,
+			want:        Terms{P: 6, FeedForward: 10, Output: 16},
+		},
+		{
+			name:        "no windup while saturated by the feed-forward",
+			gains:       Gains{P: 10, I: 1},
+			samples:     []sample{{90, 0}, {90, 1}, {90, 2}},
+			feedForward: 80,
+			want:        Terms{P: 30, FeedForward: 80, Output: 100},
+		},
+	}
+
+	start := time.Date(2026, 10, 19, 7, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newController(tt.gains, 93)
+			for _, s := range tt.samples {
+				c.update(s.measurement, start.Add(time.Duration(s.at*float64(time.Second))), tt.feedForward)
+			}
+			if !termsEqual(c.terms, tt.want) {
+				t.Errorf("terms = %+v, want %+v", c.terms, tt.want)
+			}
+		})
+	}
+}
+
+func termsEqual(a Terms, b Terms) bool {
+	near := func(x, y float64) bool { return math.Abs(x-y) < 1e-9 }
+	return near(a.P, b.P) && near(a.I, b.I) && near(a.D, b.D) && near(a.FeedForward, b.FeedForward) && near(a.Output, b.Output)
+}
+
+func TestController_bumpless(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(c *controller)
+	}{
+		{name: "gains", change: func(c *controller) { c.setGains(Gains{P: 30, I: 0.3, D: 50, DFilter: 5 * time.Second}) }},
+		{name: "setpoint", change: func(c *controller) { c.setSetpoint(95) }},
+	}
+
+	start := time.Date(2026, 10, 19, 7, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newController(Gains{P: 16, I: 0.15, D: 100, DFilter: 5 * time.Second}, 93)
+			for i, y := range []float64{92, 92.2, 92.3, 92.5} {
+				c.update(y, start.Add(time.Duration(i)*time.Second), 0)
+			}
+			before := c.terms.Output
+
+			tt.change(c)
+			e := c.setpoint - c.lastMeasurement
+			after := c.gains.P*e + c.integral - c.gains.D*c.slope
+			if math.Abs(after-before) > 1e-9 {
+				t.Errorf("output after changing the %s = %v, want %v", tt.name, after, before)
+			}
+		})
+	}
+}
+
+type heater struct {
+	duty float32
+}
+
+func (h *heater) AppliedDutyFactor() float32 {
+	return h.duty
+}
+
+// silvia are the parameters the simulation defaults to
+var silvia = simulation.Parameters{
+	HeaterWatts:        1100,
+	HeaterLagSeconds:   8,
+	ThermalMass:        2000,
+	AmbientLoss:        1,
+	AmbientTemperature: 22,
+	SensorLagSeconds:   4,
+	BrewFlowRate:       2.5,
+	InletTemperature:   20,
+}
+
+func TestController_simulatedBoiler(t *testing.T) {
+	type event struct {
+		// seconds since the start
+		at       int
+		setpoint float64
+		brewing  bool
+		// reset the controller before changing the setpoint
+		reset bool
+		// feed-forward boost from the event on, until the next one
+		boost control.BoostProfile
+	}
+	tests := []struct {
+		name  string
+		noise float64
+		// seconds between samples, cycled through
+		intervals []float64
+		events    []event
+		// seconds after the last event the temperature must be at the
+		// setpoint by, within tolerance °C, and stay there
+		settle       int
+		tolerance    float64
+		maxOvershoot float64
+		// how far the temperature may fall below the setpoint after the
+		// first event, unchecked if 0
+		maxUndershoot float64
+	}{
+		{
+			name:         "cold start",
+			intervals:    []float64{1},
+			settle:       300,
+			tolerance:    0.2,
+			maxOvershoot: 1,
+		},
+		{
+			name:         "setpoint change",
+			intervals:    []float64{1},
+			events:       []event{{at: 1200, setpoint: 96}},
+			settle:       300,
+			tolerance:    0.2,
+			maxOvershoot: 1,
+		},
+		{
+			name:         "steam after a reset",
+			intervals:    []float64{1},
+			events:       []event{{at: 1200, setpoint: 140, reset: true}},
+			settle:       300,
+			tolerance:    0.3,
+			maxOvershoot: 1.5,
+		},
+		{
+			name:      "brew",
+			intervals: []float64{1},
+			events:    []event{{at: 1200, setpoint: 93, brewing: true}, {at: 1230, setpoint: 93}},
+			settle:    300,
+			tolerance: 0.3,
+			// recovering from cold water flowing in for 30s
+			maxOvershoot:  2.5,
+			maxUndershoot: 5,
+		},
+		{
+			name:      "brew with a boost",
+			intervals: []float64{1},
+			events: []event{
+				{at: 1200, setpoint: 93, brewing: true, boost: control.BoostProfile{{Duration: 25 * time.Second, Output: 60}}},
+				{at: 1230, setpoint: 93},
+			},
+			settle:    300,
+			tolerance: 0.3,
+			// heating ahead of the cold water halves the drop and the
+			// overshoot recovering from it
+			maxOvershoot:  1.5,
+			maxUndershoot: 2.5,
+		},
+		{
+			name:         "noisy sensor sampled irregularly",
+			noise:        0.05,
+			intervals:    []float64{0.5, 1.5, 1, 2, 0.8},
+			settle:       400,
+			tolerance:    0.4,
+			maxOvershoot: 1.5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := silvia
+			p.SensorNoise = tt.noise
+			h := &heater{}
+			boiler := simulation.NewBoiler(p, h)
+			c := newController(Gains{
+				P:       float64(defaultP),
+				I:       float64(defaultI),
+				D:       float64(defaultD),
+				DFilter: time.Duration(defaultDFilter) * time.Second,
+			}, 93)
+
+			start := time.Date(2026, 10, 19, 7, 0, 0, 0, time.UTC)
+			settleAt := float64(tt.settle)
+			if len(tt.events) > 0 {
+				settleAt += float64(tt.events[len(tt.events)-1].at)
+			}
+			const step = 100 * time.Millisecond
+			elapsed := time.Duration(0)
+			next := time.Duration(0)
+			overshoot, undershoot := 0.0, 0.0
+			var boost control.BoostProfile
+			var boostStart time.Duration
+			for i := 0; elapsed.Seconds() < settleAt+600; {
+				for _, e := range tt.events {
+					if elapsed == time.Duration(e.at)*time.Second {
+						if e.reset {
+							c.reset()
+						}
+						c.setSetpoint(e.setpoint)
+						boiler.SetBrewing(e.brewing)
+						boost, boostStart = e.boost, elapsed
+					}
+				}
+				if elapsed >= next {
+					s, _ := boiler.Sample()
+					// the monitor reports tenths of a degree
+					y := math.Round(float64(s.Value)*10) / 10
+					h.duty = float32(c.update(y, start.Add(elapsed), float64(boost.At(elapsed-boostStart))) / 100)
+					next += time.Duration(tt.intervals[i%len(tt.intervals)] * float64(time.Second))
+					i++
+
+					water := boiler.GetStatus().WaterTemperature
+					overshoot = math.Max(overshoot, water-c.setpoint)
+					if len(tt.events) > 0 && elapsed.Seconds() >= float64(tt.events[0].at) {
+						undershoot = math.Max(undershoot, c.setpoint-water)
+					}
+					if elapsed.Seconds() >= settleAt && math.Abs(water-c.setpoint) > tt.tolerance {
+						t.Fatalf("boiler at %.2f °C after %s, want %v ± %v", water, elapsed, c.setpoint, tt.tolerance)
+					}
+				}
+				boiler.Step(step)
+				elapsed += step
+			}
+			if overshoot > tt.maxOvershoot {
+				t.Errorf("overshoot = %.2f °C, want at most %v", overshoot, tt.maxOvershoot)
+			}
+			if tt.maxUndershoot > 0 && undershoot > tt.maxUndershoot {
+				t.Errorf("undershoot = %.2f °C, want at most %v", undershoot, tt.maxUndershoot)
+			}
+		})
+	}
+}
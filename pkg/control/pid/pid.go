@@ -1,46 +1,64 @@
 package pid
 
 import (
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/luiccn/espresso-controller/internal/espresso/heating_element"
 	"github.com/luiccn/espresso-controller/internal/espresso/power_manager"
 	"github.com/luiccn/espresso-controller/internal/espresso/temperature"
-	"github.com/luiccn/espresso-controller/internal/fifo"
 	"github.com/luiccn/espresso-controller/internal/log"
 	"github.com/luiccn/espresso-controller/pkg/control"
 	"go.uber.org/zap"
 )
 
 const (
-	errSumLookback   int = 5
-	avgSlopeLookback int = 5
-
-	defaultP float32 = 3
-	defaultI float32 = 1
-	defaultD float32 = 40
+	// Name identifies the PID strategy in configuration
+	Name = "pid"
+
+	ParamP = "p"
+	ParamI = "i"
+	ParamD = "d"
+	// time constant in seconds of the low-pass filter of the derivative
+	ParamDFilter = "d_filter"
+
+	// tuned on the simulated Rancilio Silvia
+	defaultP       float32 = 16
+	defaultI       float32 = 0.15
+	defaultD       float32 = 100
+	defaultDFilter float32 = 5
 )
 
-// PID is a temperature controller that implements PID control. It
-// satisfies the control.Strategy interface.
-// https://en.wikipedia.org/wiki/Bang%E2%80%93bang_control
+// PID is a temperature controller that implements PID control, see controller
+// for the algorithm. It satisfies the control.Strategy interface, and the
+// control.Booster interface by adding the boost as the feed-forward term.
+// https://en.wikipedia.org/wiki/PID_controller
 type PID struct {
-	P                  float32
-	I                  float32
-	D                  float32
+	mu                 sync.RWMutex
+	p                  float32
+	i                  float32
+	d                  float32
+	dFilter            float32
 	targetTemperature  control.TargetTemperature
 	heatingElement     *heating_element.HeatingElement
 	temperatureMonitor *temperature.Monitor
 	powerManager       *power_manager.PowerManager
 	temperatureSubId   uuid.UUID
+	// whether the controller is reset before the next sample
+	resetPending bool
+	// feed-forward boost and when it started
+	boost      control.BoostProfile
+	boostStart time.Time
 }
 
 func NewPid(heatingElem *heating_element.HeatingElement, powerManager *power_manager.PowerManager, sampler *temperature.Monitor) (*PID, error) {
 	return &PID{
-		P:                  defaultP,
-		I:                  defaultI,
-		D:                  defaultD,
+		p:                  defaultP,
+		i:                  defaultI,
+		d:                  defaultD,
+		dFilter:            defaultDFilter,
 		targetTemperature:  control.TargetTemperature{Value: 93, SetAt: time.Now()},
 		heatingElement:     heatingElem,
 		powerManager:       powerManager,
@@ -49,54 +67,86 @@ func NewPid(heatingElem *heating_element.HeatingElement, powerManager *power_man
 }
 
 func (c *PID) Run() error {
-	go func() {
-		subId, subCh := c.temperatureMonitor.Subscribe()
-		c.temperatureSubId = subId
-		prevErrs := fifo.NewFIFO(errSumLookback)
-		prevSlopes := fifo.NewFIFO(avgSlopeLookback)
+	subId, subCh := c.temperatureMonitor.Subscribe()
+	c.temperatureSubId = subId
 
+	go func() {
+		ctrl := newController(c.gains(), float64(c.GetTargetTemperature().Value))
 		for sample := range subCh {
-			if c.powerManager.IsMachinePowerOn() {
-				curErr := c.targetTemperature.Value - sample.Value
-
-				prevSlopes.Push(prevErrs.Last() - curErr)
-				avgSlope := prevSlopes.Average()
-
-				prevErrs.Push(curErr)
-				errSum := prevErrs.Sum()
-
-				rawOut := (c.P*curErr + c.I*(errSum) - c.D*(avgSlope)) / 100
-
-				var out float32
-				if rawOut <= 0 {
-					out = 0
-				} else if rawOut >= 1 {
-					out = 1
-				} else {
-					out = rawOut
-				}
-
-				log.Debug("Setting duty factor",
-					zap.Float32("dutyFactor", out),
-					zap.Float32("curErr", curErr),
-					zap.Float32("errSum", errSum),
-					zap.Float32("avgSlope", avgSlope),
-					zap.Float32("curTemperature", sample.Value),
-					zap.Float32("targetTemperature",
-						c.GetTargetTemperature().Value),
-				)
-				c.heatingElement.SetDutyFactor(out)
-			} else {
-				prevErrs.Clear()
-				prevSlopes.Clear()
+			if !c.powerManager.IsMachinePowerOn() {
+				ctrl.reset()
 				c.heatingElement.SetDutyFactor(0)
+				continue
+			}
+
+			if c.takeReset() {
+				ctrl.reset()
 			}
+			targetTemperature := c.GetTargetTemperature().Value
+			ctrl.setGains(c.gains())
+			ctrl.setSetpoint(float64(targetTemperature))
+			out := ctrl.update(float64(sample.Value), sample.ObservedAt, c.boostAt(sample.ObservedAt))
+
+			log.Debug("Setting duty factor",
+				zap.Float64("dutyFactor", out/100),
+				zap.Float64("p", ctrl.terms.P),
+				zap.Float64("i", ctrl.terms.I),
+				zap.Float64("d", ctrl.terms.D),
+				zap.Float64("boost", ctrl.terms.FeedForward),
+				zap.Float32("curTemperature", sample.Value),
+				zap.Float32("targetTemperature", targetTemperature),
+			)
+			c.heatingElement.SetDutyFactor(float32(out / 100))
 		}
 	}()
 	return nil
 }
 
+func (c *PID) gains() Gains {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return Gains{
+		P:       float64(c.p),
+		I:       float64(c.i),
+		D:       float64(c.d),
+		DFilter: time.Duration(float64(c.dFilter) * float64(time.Second)),
+	}
+}
+
+// Reset forgets the integral and the past measurements, so that a new setpoint
+// is approached at once
+func (c *PID) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.resetPending = true
+}
+
+func (c *PID) takeReset() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	reset := c.resetPending
+	c.resetPending = false
+	return reset
+}
+
+// Boost adds the profile to the output from start on
+func (c *PID) Boost(profile control.BoostProfile, start time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.boost = profile
+	c.boostStart = start
+}
+
+// boostAt returns the output the boost adds at the given time
+func (c *PID) boostAt(at time.Time) float64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return float64(c.boost.At(at.Sub(c.boostStart)))
+}
+
 func (c *PID) GetTargetTemperature() control.TargetTemperature {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.targetTemperature
 }
 
@@ -105,10 +155,52 @@ func (c *PID) SetTargetTemperature(temperature float32) control.TargetTemperatur
 		Value: temperature,
 		SetAt: time.Now(),
 	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.targetTemperature = targetTemperature
 	return targetTemperature
 }
 
+func (c *PID) GetParameters() control.Parameters {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return control.Parameters{
+		ParamP:       c.p,
+		ParamI:       c.i,
+		ParamD:       c.d,
+		ParamDFilter: c.dFilter,
+	}
+}
+
+func (c *PID) SetParameters(params control.Parameters) error {
+	for name, value := range params {
+		switch name {
+		case ParamP, ParamI, ParamD, ParamDFilter:
+			if value < 0 {
+				return fmt.Errorf("pid parameter %s must be >= 0", name)
+			}
+		default:
+			return fmt.Errorf("unknown pid parameter %q", name)
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if v, ok := params[ParamP]; ok {
+		c.p = v
+	}
+	if v, ok := params[ParamI]; ok {
+		c.i = v
+	}
+	if v, ok := params[ParamD]; ok {
+		c.d = v
+	}
+	if v, ok := params[ParamDFilter]; ok {
+		c.dFilter = v
+	}
+	return nil
+}
+
 func (c *PID) Shutdown() error {
 	c.temperatureMonitor.Unsubscribe(c.temperatureSubId)
 	return nil
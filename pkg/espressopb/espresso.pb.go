@@ -254,14 +254,33 @@ func (m *GetConfigurationRequest) XXX_DiscardUnknown() {
 var xxx_messageInfo_GetConfigurationRequest proto.InternalMessageInfo
 
 type Configuration struct {
-	Temperature          float32              `protobuf:"fixed32,1,opt,name=temperature,proto3" json:"temperature,omitempty"`
-	P                    float32              `protobuf:"fixed32,2,opt,name=p,proto3" json:"p,omitempty"`
-	I                    float32              `protobuf:"fixed32,3,opt,name=i,proto3" json:"i,omitempty"`
-	D                    float32              `protobuf:"fixed32,4,opt,name=d,proto3" json:"d,omitempty"`
-	SetAt                *timestamp.Timestamp `protobuf:"bytes,5,opt,name=set_at,json=setAt,proto3" json:"set_at,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
-	XXX_unrecognized     []byte               `json:"-"`
-	XXX_sizecache        int32                `json:"-"`
+	Temperature float32 `protobuf:"fixed32,1,opt,name=temperature,proto3" json:"temperature,omitempty"`
+	// gains of the pid strategy, also present in parameters. Set by
+	// SetConfiguration when parameters is empty, otherwise only when non-zero
+	// and not in parameters.
+	P     float32              `protobuf:"fixed32,2,opt,name=p,proto3" json:"p,omitempty"`
+	I     float32              `protobuf:"fixed32,3,opt,name=i,proto3" json:"i,omitempty"`
+	D     float32              `protobuf:"fixed32,4,opt,name=d,proto3" json:"d,omitempty"`
+	SetAt *timestamp.Timestamp `protobuf:"bytes,5,opt,name=set_at,json=setAt,proto3" json:"set_at,omitempty"`
+	// temperature control strategy, e.g. "pid" or "bangbang". Left unchanged
+	// when empty.
+	Strategy string `protobuf:"bytes,6,opt,name=strategy,proto3" json:"strategy,omitempty"`
+	// tunable parameters of the strategy by name. Parameters that are not set
+	// are left unchanged.
+	Parameters map[string]float32 `protobuf:"bytes,7,rep,name=parameters,proto3" json:"parameters,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"fixed32,2,opt,name=value,proto3"`
+	// "brew" or "steam", see SetMode. While steaming, the temperature and
+	// parameters above are those used again once brewing. Ignored by
+	// SetConfiguration.
+	Mode string `protobuf:"bytes,8,opt,name=mode,proto3" json:"mode,omitempty"`
+	// Left unchanged by SetConfiguration when unset
+	Steam *SteamConfiguration `protobuf:"bytes,9,opt,name=steam,proto3" json:"steam,omitempty"`
+	// Feed-forward heater boost at the start of shots started by the brew
+	// switch or detected from the temperature, applied by the pid strategy.
+	// Left unchanged by SetConfiguration when unset, none if it has no steps.
+	Boost                *BoostProfile `protobuf:"bytes,10,opt,name=boost,proto3" json:"boost,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
 }
 
 func (m *Configuration) Reset()         { *m = Configuration{} }
@@ -324,222 +343,4414 @@ func (m *Configuration) GetSetAt() *timestamp.Timestamp {
 	return nil
 }
 
-func init() {
-	proto.RegisterType((*TemperatureSample)(nil), "espressopb.TemperatureSample")
-	proto.RegisterType((*TemperatureHistory)(nil), "espressopb.TemperatureHistory")
-	proto.RegisterType((*TemperatureStreamRequest)(nil), "espressopb.TemperatureStreamRequest")
-	proto.RegisterType((*TemperatureStreamResponse)(nil), "espressopb.TemperatureStreamResponse")
-	proto.RegisterType((*GetConfigurationRequest)(nil), "espressopb.GetConfigurationRequest")
-	proto.RegisterType((*Configuration)(nil), "espressopb.Configuration")
+func (m *Configuration) GetStrategy() string {
+	if m != nil {
+		return m.Strategy
+	}
+	return ""
 }
 
-func init() {
-	proto.RegisterFile("espresso.proto", fileDescriptor_445399412d1702d2)
+func (m *Configuration) GetParameters() map[string]float32 {
+	if m != nil {
+		return m.Parameters
+	}
+	return nil
 }
 
-var fileDescriptor_445399412d1702d2 = []byte{
-	// 394 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x51, 0xc1, 0x6e, 0xd3, 0x40,
-	0x10, 0xed, 0xba, 0x8d, 0x8b, 0xc6, 0x80, 0xda, 0x15, 0x12, 0x8e, 0x25, 0xc0, 0x32, 0x20, 0xe5,
-	0xe4, 0x82, 0x39, 0x54, 0x82, 0x53, 0x8b, 0x10, 0xbe, 0x70, 0xd9, 0xf6, 0x8e, 0xd6, 0xf2, 0x24,
-	0x58, 0xb2, 0xb3, 0xcb, 0xee, 0xb8, 0x12, 0xff, 0x80, 0x38, 0xf3, 0xb9, 0x28, 0x5e, 0x5b, 0x75,
-	0x42, 0x9d, 0x1c, 0xdf, 0xce, 0xdb, 0x79, 0xef, 0xcd, 0x83, 0xa7, 0x68, 0xb5, 0x41, 0x6b, 0x55,
-	0xaa, 0x8d, 0x22, 0xc5, 0x61, 0xc0, 0xba, 0x88, 0x5e, 0xad, 0x94, 0x5a, 0xd5, 0x78, 0xd1, 0x4d,
-	0x8a, 0x76, 0x79, 0x41, 0x55, 0x83, 0x96, 0x64, 0xa3, 0x1d, 0x39, 0x59, 0xc2, 0xf9, 0x2d, 0x36,
-	0x1a, 0x8d, 0xa4, 0xd6, 0xe0, 0x8d, 0x6c, 0x74, 0x8d, 0xfc, 0x19, 0xcc, 0xee, 0x64, 0xdd, 0x62,
-	0xc8, 0x62, 0xb6, 0xf0, 0x84, 0x03, 0xfc, 0x13, 0x04, 0xaa, 0xb0, 0x68, 0xee, 0xb0, 0xfc, 0x2e,
-	0x29, 0xf4, 0x62, 0xb6, 0x08, 0xb2, 0x28, 0x75, 0x0a, 0xe9, 0xa0, 0x90, 0xde, 0x0e, 0x0a, 0x02,
-	0x06, 0xfa, 0x15, 0x25, 0xdf, 0x80, 0x8f, 0x74, 0xf2, 0xca, 0x92, 0x32, 0xbf, 0xf8, 0x25, 0x9c,
-	0xda, 0x4e, 0xd2, 0x86, 0x2c, 0x3e, 0x5e, 0x04, 0xd9, 0x8b, 0xf4, 0xde, 0x7c, 0xfa, 0x9f, 0x31,
-	0x31, 0xb0, 0x93, 0x08, 0xc2, 0xf1, 0x94, 0x0c, 0xca, 0x46, 0xe0, 0xcf, 0x16, 0x2d, 0x25, 0x7f,
-	0x19, 0xcc, 0x1f, 0x18, 0x5a, 0xad, 0xd6, 0x16, 0xf9, 0x47, 0x38, 0xfd, 0xe1, 0xd4, 0xbb, 0x74,
-	0x41, 0xf6, 0x72, 0x42, 0xb2, 0xf7, 0x98, 0x1f, 0x89, 0xe1, 0x03, 0xbf, 0x04, 0xdf, 0x19, 0xe8,
-	0xc3, 0xef, 0x77, 0x9b, 0x1f, 0x89, 0x9e, 0x7e, 0xed, 0xc3, 0x49, 0x29, 0x49, 0x26, 0x73, 0x78,
-	0xfe, 0x15, 0xe9, 0xb3, 0x5a, 0x2f, 0xab, 0x55, 0x6b, 0x24, 0x55, 0x6a, 0x3d, 0xb8, 0xfe, 0xc3,
-	0xe0, 0xc9, 0xd6, 0x80, 0xc7, 0x10, 0xd0, 0xfd, 0xce, 0xbe, 0x8b, 0xf1, 0x13, 0x7f, 0x0c, 0x4c,
-	0x77, 0x56, 0x3c, 0xc1, 0xf4, 0x06, 0x55, 0xe1, 0xb1, 0x43, 0xd5, 0x06, 0x95, 0xe1, 0x89, 0x43,
-	0x25, 0x7f, 0x0f, 0xbe, 0x45, 0xda, 0xd4, 0x36, 0x3b, 0x58, 0xdb, 0xcc, 0x22, 0x5d, 0x51, 0xf6,
-	0xdb, 0x83, 0x47, 0x5f, 0xfa, 0x78, 0xbc, 0x80, 0xf3, 0x6b, 0x55, 0xd5, 0x68, 0x46, 0x29, 0xf9,
-	0x9b, 0xa9, 0xf8, 0xe3, 0x3a, 0xa2, 0xb7, 0x07, 0x58, 0xae, 0x97, 0x77, 0x8c, 0x0b, 0x38, 0xdb,
-	0x3d, 0x0e, 0x7f, 0x3d, 0xfe, 0x3c, 0x71, 0xba, 0x68, 0x3e, 0x26, 0x6d, 0xff, 0xcf, 0xe1, 0xec,
-	0x66, 0x77, 0xe7, 0x34, 0x7d, 0xcf, 0xa6, 0xc2, 0xef, 0x2e, 0xf5, 0xe1, 0x5f, 0x00, 0x00, 0x00,
-	0xff, 0xff, 0xd9, 0x86, 0x01, 0x1c, 0x6e, 0x03, 0x00, 0x00,
+func (m *Configuration) GetMode() string {
+	if m != nil {
+		return m.Mode
+	}
+	return ""
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConnInterface
+func (m *Configuration) GetSteam() *SteamConfiguration {
+	if m != nil {
+		return m.Steam
+	}
+	return nil
+}
 
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion6
+func (m *Configuration) GetBoost() *BoostProfile {
+	if m != nil {
+		return m.Boost
+	}
+	return nil
+}
 
-// EspressoClient is the client API for Espresso service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type EspressoClient interface {
-	BoilerTemperature(ctx context.Context, in *TemperatureStreamRequest, opts ...grpc.CallOption) (Espresso_BoilerTemperatureClient, error)
-	GetConfiguration(ctx context.Context, in *GetConfigurationRequest, opts ...grpc.CallOption) (*Configuration, error)
-	SetConfiguration(ctx context.Context, in *Configuration, opts ...grpc.CallOption) (*Configuration, error)
+type BoostProfile struct {
+	// applied one after the other from the start of the shot
+	Steps                []*BoostStep `protobuf:"bytes,1,rep,name=steps,proto3" json:"steps,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
 }
 
-type espressoClient struct {
-	cc grpc.ClientConnInterface
+func (m *BoostProfile) Reset()         { *m = BoostProfile{} }
+func (m *BoostProfile) String() string { return proto.CompactTextString(m) }
+func (*BoostProfile) ProtoMessage()    {}
+func (*BoostProfile) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{6}
 }
 
-func NewEspressoClient(cc grpc.ClientConnInterface) EspressoClient {
-	return &espressoClient{cc}
+func (m *BoostProfile) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BoostProfile.Unmarshal(m, b)
+}
+func (m *BoostProfile) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BoostProfile.Marshal(b, m, deterministic)
+}
+func (m *BoostProfile) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BoostProfile.Merge(m, src)
+}
+func (m *BoostProfile) XXX_Size() int {
+	return xxx_messageInfo_BoostProfile.Size(m)
+}
+func (m *BoostProfile) XXX_DiscardUnknown() {
+	xxx_messageInfo_BoostProfile.DiscardUnknown(m)
 }
 
-func (c *espressoClient) BoilerTemperature(ctx context.Context, in *TemperatureStreamRequest, opts ...grpc.CallOption) (Espresso_BoilerTemperatureClient, error) {
-	stream, err := c.cc.NewStream(ctx, &_Espresso_serviceDesc.Streams[0], "/espressopb.Espresso/BoilerTemperature", opts...)
-	if err != nil {
-		return nil, err
+var xxx_messageInfo_BoostProfile proto.InternalMessageInfo
+
+func (m *BoostProfile) GetSteps() []*BoostStep {
+	if m != nil {
+		return m.Steps
 	}
-	x := &espressoBoilerTemperatureClient{stream}
-	if err := x.ClientStream.SendMsg(in); err != nil {
-		return nil, err
+	return nil
+}
+
+type BoostStep struct {
+	Seconds float32 `protobuf:"fixed32,1,opt,name=seconds,proto3" json:"seconds,omitempty"`
+	// percent of the heating element's power added to the strategy's output
+	Output               float32  `protobuf:"fixed32,2,opt,name=output,proto3" json:"output,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BoostStep) Reset()         { *m = BoostStep{} }
+func (m *BoostStep) String() string { return proto.CompactTextString(m) }
+func (*BoostStep) ProtoMessage()    {}
+func (*BoostStep) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{7}
+}
+
+func (m *BoostStep) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BoostStep.Unmarshal(m, b)
+}
+func (m *BoostStep) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BoostStep.Marshal(b, m, deterministic)
+}
+func (m *BoostStep) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BoostStep.Merge(m, src)
+}
+func (m *BoostStep) XXX_Size() int {
+	return xxx_messageInfo_BoostStep.Size(m)
+}
+func (m *BoostStep) XXX_DiscardUnknown() {
+	xxx_messageInfo_BoostStep.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BoostStep proto.InternalMessageInfo
+
+func (m *BoostStep) GetSeconds() float32 {
+	if m != nil {
+		return m.Seconds
 	}
-	if err := x.ClientStream.CloseSend(); err != nil {
-		return nil, err
+	return 0
+}
+
+func (m *BoostStep) GetOutput() float32 {
+	if m != nil {
+		return m.Output
 	}
-	return x, nil
+	return 0
 }
 
-type Espresso_BoilerTemperatureClient interface {
-	Recv() (*TemperatureStreamResponse, error)
-	grpc.ClientStream
+type SteamConfiguration struct {
+	// boiler temperature in °C while steaming
+	Temperature float32 `protobuf:"fixed32,1,opt,name=temperature,proto3" json:"temperature,omitempty"`
+	// gains of the pid strategy while steaming
+	P float32 `protobuf:"fixed32,2,opt,name=p,proto3" json:"p,omitempty"`
+	I float32 `protobuf:"fixed32,3,opt,name=i,proto3" json:"i,omitempty"`
+	D float32 `protobuf:"fixed32,4,opt,name=d,proto3" json:"d,omitempty"`
+	// seconds after which steam mode reverts to brew mode, never if 0
+	Timeout              float32  `protobuf:"fixed32,5,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-type espressoBoilerTemperatureClient struct {
-	grpc.ClientStream
+func (m *SteamConfiguration) Reset()         { *m = SteamConfiguration{} }
+func (m *SteamConfiguration) String() string { return proto.CompactTextString(m) }
+func (*SteamConfiguration) ProtoMessage()    {}
+func (*SteamConfiguration) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{8}
 }
 
-func (x *espressoBoilerTemperatureClient) Recv() (*TemperatureStreamResponse, error) {
-	m := new(TemperatureStreamResponse)
-	if err := x.ClientStream.RecvMsg(m); err != nil {
-		return nil, err
+func (m *SteamConfiguration) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SteamConfiguration.Unmarshal(m, b)
+}
+func (m *SteamConfiguration) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SteamConfiguration.Marshal(b, m, deterministic)
+}
+func (m *SteamConfiguration) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SteamConfiguration.Merge(m, src)
+}
+func (m *SteamConfiguration) XXX_Size() int {
+	return xxx_messageInfo_SteamConfiguration.Size(m)
+}
+func (m *SteamConfiguration) XXX_DiscardUnknown() {
+	xxx_messageInfo_SteamConfiguration.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SteamConfiguration proto.InternalMessageInfo
+
+func (m *SteamConfiguration) GetTemperature() float32 {
+	if m != nil {
+		return m.Temperature
 	}
-	return m, nil
+	return 0
 }
 
-func (c *espressoClient) GetConfiguration(ctx context.Context, in *GetConfigurationRequest, opts ...grpc.CallOption) (*Configuration, error) {
-	out := new(Configuration)
-	err := c.cc.Invoke(ctx, "/espressopb.Espresso/GetConfiguration", in, out, opts...)
-	if err != nil {
-		return nil, err
+func (m *SteamConfiguration) GetP() float32 {
+	if m != nil {
+		return m.P
 	}
-	return out, nil
+	return 0
 }
 
-func (c *espressoClient) SetConfiguration(ctx context.Context, in *Configuration, opts ...grpc.CallOption) (*Configuration, error) {
-	out := new(Configuration)
-	err := c.cc.Invoke(ctx, "/espressopb.Espresso/SetConfiguration", in, out, opts...)
-	if err != nil {
-		return nil, err
+func (m *SteamConfiguration) GetI() float32 {
+	if m != nil {
+		return m.I
 	}
-	return out, nil
+	return 0
 }
 
-// EspressoServer is the server API for Espresso service.
-type EspressoServer interface {
-	BoilerTemperature(*TemperatureStreamRequest, Espresso_BoilerTemperatureServer) error
-	GetConfiguration(context.Context, *GetConfigurationRequest) (*Configuration, error)
-	SetConfiguration(context.Context, *Configuration) (*Configuration, error)
+func (m *SteamConfiguration) GetD() float32 {
+	if m != nil {
+		return m.D
+	}
+	return 0
 }
 
-// UnimplementedEspressoServer can be embedded to have forward compatible implementations.
-type UnimplementedEspressoServer struct {
+func (m *SteamConfiguration) GetTimeout() float32 {
+	if m != nil {
+		return m.Timeout
+	}
+	return 0
 }
 
-func (*UnimplementedEspressoServer) BoilerTemperature(req *TemperatureStreamRequest, srv Espresso_BoilerTemperatureServer) error {
-	return status.Errorf(codes.Unimplemented, "method BoilerTemperature not implemented")
+type AutotuneRequest struct {
+	// temperature to oscillate around, defaults to the target temperature
+	Setpoint float32 `protobuf:"fixed32,1,opt,name=setpoint,proto3" json:"setpoint,omitempty"`
+	// duty factors applied below and above the setpoint, default 1 and 0
+	OutputHigh float32 `protobuf:"fixed32,2,opt,name=output_high,json=outputHigh,proto3" json:"output_high,omitempty"`
+	OutputLow  float32 `protobuf:"fixed32,3,opt,name=output_low,json=outputLow,proto3" json:"output_low,omitempty"`
+	// noise band around the setpoint, default 0.5 °C
+	Hysteresis float32 `protobuf:"fixed32,4,opt,name=hysteresis,proto3" json:"hysteresis,omitempty"`
+	// number of oscillations to measure, default 4
+	Cycles int32 `protobuf:"varint,5,opt,name=cycles,proto3" json:"cycles,omitempty"`
+	// "ziegler-nichols" (default) or "tyreus-luyben"
+	Rule string `protobuf:"bytes,6,opt,name=rule,proto3" json:"rule,omitempty"`
+	// switch to the pid strategy with the resulting gains once done
+	Apply                bool     `protobuf:"varint,7,opt,name=apply,proto3" json:"apply,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
-func (*UnimplementedEspressoServer) GetConfiguration(ctx context.Context, req *GetConfigurationRequest) (*Configuration, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method GetConfiguration not implemented")
+
+func (m *AutotuneRequest) Reset()         { *m = AutotuneRequest{} }
+func (m *AutotuneRequest) String() string { return proto.CompactTextString(m) }
+func (*AutotuneRequest) ProtoMessage()    {}
+func (*AutotuneRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{9}
 }
-func (*UnimplementedEspressoServer) SetConfiguration(ctx context.Context, req *Configuration) (*Configuration, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method SetConfiguration not implemented")
+
+func (m *AutotuneRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AutotuneRequest.Unmarshal(m, b)
+}
+func (m *AutotuneRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AutotuneRequest.Marshal(b, m, deterministic)
+}
+func (m *AutotuneRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AutotuneRequest.Merge(m, src)
+}
+func (m *AutotuneRequest) XXX_Size() int {
+	return xxx_messageInfo_AutotuneRequest.Size(m)
+}
+func (m *AutotuneRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_AutotuneRequest.DiscardUnknown(m)
 }
 
-func RegisterEspressoServer(s *grpc.Server, srv EspressoServer) {
-	s.RegisterService(&_Espresso_serviceDesc, srv)
+var xxx_messageInfo_AutotuneRequest proto.InternalMessageInfo
+
+func (m *AutotuneRequest) GetSetpoint() float32 {
+	if m != nil {
+		return m.Setpoint
+	}
+	return 0
 }
 
-func _Espresso_BoilerTemperature_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(TemperatureStreamRequest)
-	if err := stream.RecvMsg(m); err != nil {
-		return err
+func (m *AutotuneRequest) GetOutputHigh() float32 {
+	if m != nil {
+		return m.OutputHigh
 	}
-	return srv.(EspressoServer).BoilerTemperature(m, &espressoBoilerTemperatureServer{stream})
+	return 0
 }
 
-type Espresso_BoilerTemperatureServer interface {
-	Send(*TemperatureStreamResponse) error
-	grpc.ServerStream
+func (m *AutotuneRequest) GetOutputLow() float32 {
+	if m != nil {
+		return m.OutputLow
+	}
+	return 0
 }
 
-type espressoBoilerTemperatureServer struct {
-	grpc.ServerStream
+func (m *AutotuneRequest) GetHysteresis() float32 {
+	if m != nil {
+		return m.Hysteresis
+	}
+	return 0
 }
 
-func (x *espressoBoilerTemperatureServer) Send(m *TemperatureStreamResponse) error {
-	return x.ServerStream.SendMsg(m)
+func (m *AutotuneRequest) GetCycles() int32 {
+	if m != nil {
+		return m.Cycles
+	}
+	return 0
 }
 
-func _Espresso_GetConfiguration_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetConfigurationRequest)
-	if err := dec(in); err != nil {
-		return nil, err
+func (m *AutotuneRequest) GetRule() string {
+	if m != nil {
+		return m.Rule
 	}
-	if interceptor == nil {
-		return srv.(EspressoServer).GetConfiguration(ctx, in)
+	return ""
+}
+
+func (m *AutotuneRequest) GetApply() bool {
+	if m != nil {
+		return m.Apply
 	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/espressopb.Espresso/GetConfiguration",
+	return false
+}
+
+type AutotuneProgress struct {
+	// "heating" while approaching the setpoint, then "oscillating"
+	Phase                string               `protobuf:"bytes,1,opt,name=phase,proto3" json:"phase,omitempty"`
+	Cycle                int32                `protobuf:"varint,2,opt,name=cycle,proto3" json:"cycle,omitempty"`
+	Cycles               int32                `protobuf:"varint,3,opt,name=cycles,proto3" json:"cycles,omitempty"`
+	Temperature          float32              `protobuf:"fixed32,4,opt,name=temperature,proto3" json:"temperature,omitempty"`
+	DutyFactor           float32              `protobuf:"fixed32,5,opt,name=duty_factor,json=dutyFactor,proto3" json:"duty_factor,omitempty"`
+	ObservedAt           *timestamp.Timestamp `protobuf:"bytes,6,opt,name=observed_at,json=observedAt,proto3" json:"observed_at,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *AutotuneProgress) Reset()         { *m = AutotuneProgress{} }
+func (m *AutotuneProgress) String() string { return proto.CompactTextString(m) }
+func (*AutotuneProgress) ProtoMessage()    {}
+func (*AutotuneProgress) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{10}
+}
+
+func (m *AutotuneProgress) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AutotuneProgress.Unmarshal(m, b)
+}
+func (m *AutotuneProgress) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AutotuneProgress.Marshal(b, m, deterministic)
+}
+func (m *AutotuneProgress) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AutotuneProgress.Merge(m, src)
+}
+func (m *AutotuneProgress) XXX_Size() int {
+	return xxx_messageInfo_AutotuneProgress.Size(m)
+}
+func (m *AutotuneProgress) XXX_DiscardUnknown() {
+	xxx_messageInfo_AutotuneProgress.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AutotuneProgress proto.InternalMessageInfo
+
+func (m *AutotuneProgress) GetPhase() string {
+	if m != nil {
+		return m.Phase
 	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(EspressoServer).GetConfiguration(ctx, req.(*GetConfigurationRequest))
+	return ""
+}
+
+func (m *AutotuneProgress) GetCycle() int32 {
+	if m != nil {
+		return m.Cycle
 	}
-	return interceptor(ctx, in, info, handler)
+	return 0
 }
 
-func _Espresso_SetConfiguration_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(Configuration)
-	if err := dec(in); err != nil {
-		return nil, err
+func (m *AutotuneProgress) GetCycles() int32 {
+	if m != nil {
+		return m.Cycles
 	}
-	if interceptor == nil {
-		return srv.(EspressoServer).SetConfiguration(ctx, in)
+	return 0
+}
+
+func (m *AutotuneProgress) GetTemperature() float32 {
+	if m != nil {
+		return m.Temperature
 	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/espressopb.Espresso/SetConfiguration",
+	return 0
+}
+
+func (m *AutotuneProgress) GetDutyFactor() float32 {
+	if m != nil {
+		return m.DutyFactor
 	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(EspressoServer).SetConfiguration(ctx, req.(*Configuration))
+	return 0
+}
+
+func (m *AutotuneProgress) GetObservedAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.ObservedAt
 	}
-	return interceptor(ctx, in, info, handler)
+	return nil
 }
 
-var _Espresso_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "espressopb.Espresso",
-	HandlerType: (*EspressoServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "GetConfiguration",
-			Handler:    _Espresso_GetConfiguration_Handler,
-		},
+type AutotuneResult struct {
+	Rule string `protobuf:"bytes,1,opt,name=rule,proto3" json:"rule,omitempty"`
+	// ultimate gain in duty factor per °C
+	UltimateGain float32 `protobuf:"fixed32,2,opt,name=ultimate_gain,json=ultimateGain,proto3" json:"ultimate_gain,omitempty"`
+	// ultimate period in seconds
+	UltimatePeriod float32 `protobuf:"fixed32,3,opt,name=ultimate_period,json=ultimatePeriod,proto3" json:"ultimate_period,omitempty"`
+	// amplitude of the oscillation in °C
+	Amplitude float32 `protobuf:"fixed32,4,opt,name=amplitude,proto3" json:"amplitude,omitempty"`
+	// gains for the pid strategy
+	P                    float32  `protobuf:"fixed32,5,opt,name=p,proto3" json:"p,omitempty"`
+	I                    float32  `protobuf:"fixed32,6,opt,name=i,proto3" json:"i,omitempty"`
+	D                    float32  `protobuf:"fixed32,7,opt,name=d,proto3" json:"d,omitempty"`
+	Applied              bool     `protobuf:"varint,8,opt,name=applied,proto3" json:"applied,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AutotuneResult) Reset()         { *m = AutotuneResult{} }
+func (m *AutotuneResult) String() string { return proto.CompactTextString(m) }
+func (*AutotuneResult) ProtoMessage()    {}
+func (*AutotuneResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{11}
+}
+
+func (m *AutotuneResult) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AutotuneResult.Unmarshal(m, b)
+}
+func (m *AutotuneResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AutotuneResult.Marshal(b, m, deterministic)
+}
+func (m *AutotuneResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AutotuneResult.Merge(m, src)
+}
+func (m *AutotuneResult) XXX_Size() int {
+	return xxx_messageInfo_AutotuneResult.Size(m)
+}
+func (m *AutotuneResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_AutotuneResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AutotuneResult proto.InternalMessageInfo
+
+func (m *AutotuneResult) GetRule() string {
+	if m != nil {
+		return m.Rule
+	}
+	return ""
+}
+
+func (m *AutotuneResult) GetUltimateGain() float32 {
+	if m != nil {
+		return m.UltimateGain
+	}
+	return 0
+}
+
+func (m *AutotuneResult) GetUltimatePeriod() float32 {
+	if m != nil {
+		return m.UltimatePeriod
+	}
+	return 0
+}
+
+func (m *AutotuneResult) GetAmplitude() float32 {
+	if m != nil {
+		return m.Amplitude
+	}
+	return 0
+}
+
+func (m *AutotuneResult) GetP() float32 {
+	if m != nil {
+		return m.P
+	}
+	return 0
+}
+
+func (m *AutotuneResult) GetI() float32 {
+	if m != nil {
+		return m.I
+	}
+	return 0
+}
+
+func (m *AutotuneResult) GetD() float32 {
+	if m != nil {
+		return m.D
+	}
+	return 0
+}
+
+func (m *AutotuneResult) GetApplied() bool {
+	if m != nil {
+		return m.Applied
+	}
+	return false
+}
+
+type AutotuneResponse struct {
+	// Types that are valid to be assigned to Data:
+	//	*AutotuneResponse_Progress
+	//	*AutotuneResponse_Result
+	Data                 isAutotuneResponse_Data `protobuf_oneof:"data"`
+	XXX_NoUnkeyedLiteral struct{}                `json:"-"`
+	XXX_unrecognized     []byte                  `json:"-"`
+	XXX_sizecache        int32                   `json:"-"`
+}
+
+func (m *AutotuneResponse) Reset()         { *m = AutotuneResponse{} }
+func (m *AutotuneResponse) String() string { return proto.CompactTextString(m) }
+func (*AutotuneResponse) ProtoMessage()    {}
+func (*AutotuneResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{12}
+}
+
+func (m *AutotuneResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AutotuneResponse.Unmarshal(m, b)
+}
+func (m *AutotuneResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AutotuneResponse.Marshal(b, m, deterministic)
+}
+func (m *AutotuneResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AutotuneResponse.Merge(m, src)
+}
+func (m *AutotuneResponse) XXX_Size() int {
+	return xxx_messageInfo_AutotuneResponse.Size(m)
+}
+func (m *AutotuneResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_AutotuneResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AutotuneResponse proto.InternalMessageInfo
+
+type isAutotuneResponse_Data interface {
+	isAutotuneResponse_Data()
+}
+
+type AutotuneResponse_Progress struct {
+	Progress *AutotuneProgress `protobuf:"bytes,1,opt,name=progress,proto3,oneof"`
+}
+
+type AutotuneResponse_Result struct {
+	Result *AutotuneResult `protobuf:"bytes,2,opt,name=result,proto3,oneof"`
+}
+
+func (*AutotuneResponse_Progress) isAutotuneResponse_Data() {}
+
+func (*AutotuneResponse_Result) isAutotuneResponse_Data() {}
+
+func (m *AutotuneResponse) GetData() isAutotuneResponse_Data {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func (m *AutotuneResponse) GetProgress() *AutotuneProgress {
+	if x, ok := m.GetData().(*AutotuneResponse_Progress); ok {
+		return x.Progress
+	}
+	return nil
+}
+
+func (m *AutotuneResponse) GetResult() *AutotuneResult {
+	if x, ok := m.GetData().(*AutotuneResponse_Result); ok {
+		return x.Result
+	}
+	return nil
+}
+
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*AutotuneResponse) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*AutotuneResponse_Progress)(nil),
+		(*AutotuneResponse_Result)(nil),
+	}
+}
+
+type GetSafetyStatusRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetSafetyStatusRequest) Reset()         { *m = GetSafetyStatusRequest{} }
+func (m *GetSafetyStatusRequest) String() string { return proto.CompactTextString(m) }
+func (*GetSafetyStatusRequest) ProtoMessage()    {}
+func (*GetSafetyStatusRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{13}
+}
+
+func (m *GetSafetyStatusRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetSafetyStatusRequest.Unmarshal(m, b)
+}
+func (m *GetSafetyStatusRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetSafetyStatusRequest.Marshal(b, m, deterministic)
+}
+func (m *GetSafetyStatusRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetSafetyStatusRequest.Merge(m, src)
+}
+func (m *GetSafetyStatusRequest) XXX_Size() int {
+	return xxx_messageInfo_GetSafetyStatusRequest.Size(m)
+}
+func (m *GetSafetyStatusRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetSafetyStatusRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetSafetyStatusRequest proto.InternalMessageInfo
+
+type ResetFaultRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ResetFaultRequest) Reset()         { *m = ResetFaultRequest{} }
+func (m *ResetFaultRequest) String() string { return proto.CompactTextString(m) }
+func (*ResetFaultRequest) ProtoMessage()    {}
+func (*ResetFaultRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{14}
+}
+
+func (m *ResetFaultRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ResetFaultRequest.Unmarshal(m, b)
+}
+func (m *ResetFaultRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ResetFaultRequest.Marshal(b, m, deterministic)
+}
+func (m *ResetFaultRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ResetFaultRequest.Merge(m, src)
+}
+func (m *ResetFaultRequest) XXX_Size() int {
+	return xxx_messageInfo_ResetFaultRequest.Size(m)
+}
+func (m *ResetFaultRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ResetFaultRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ResetFaultRequest proto.InternalMessageInfo
+
+type SafetyStatus struct {
+	// whether the heating element is cut off by a latched fault
+	Faulted bool `protobuf:"varint,1,opt,name=faulted,proto3" json:"faulted,omitempty"`
+	// e.g. "over_temperature", "thermal_runaway", "stuck_reading",
+	// "out_of_range" or "sensor_error"
+	Kind    string `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"`
+	Message string `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	// temperature reading that tripped the fault
+	Temperature          float32              `protobuf:"fixed32,4,opt,name=temperature,proto3" json:"temperature,omitempty"`
+	FaultedAt            *timestamp.Timestamp `protobuf:"bytes,5,opt,name=faulted_at,json=faultedAt,proto3" json:"faulted_at,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *SafetyStatus) Reset()         { *m = SafetyStatus{} }
+func (m *SafetyStatus) String() string { return proto.CompactTextString(m) }
+func (*SafetyStatus) ProtoMessage()    {}
+func (*SafetyStatus) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{15}
+}
+
+func (m *SafetyStatus) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SafetyStatus.Unmarshal(m, b)
+}
+func (m *SafetyStatus) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SafetyStatus.Marshal(b, m, deterministic)
+}
+func (m *SafetyStatus) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SafetyStatus.Merge(m, src)
+}
+func (m *SafetyStatus) XXX_Size() int {
+	return xxx_messageInfo_SafetyStatus.Size(m)
+}
+func (m *SafetyStatus) XXX_DiscardUnknown() {
+	xxx_messageInfo_SafetyStatus.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SafetyStatus proto.InternalMessageInfo
+
+func (m *SafetyStatus) GetFaulted() bool {
+	if m != nil {
+		return m.Faulted
+	}
+	return false
+}
+
+func (m *SafetyStatus) GetKind() string {
+	if m != nil {
+		return m.Kind
+	}
+	return ""
+}
+
+func (m *SafetyStatus) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+func (m *SafetyStatus) GetTemperature() float32 {
+	if m != nil {
+		return m.Temperature
+	}
+	return 0
+}
+
+func (m *SafetyStatus) GetFaultedAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.FaultedAt
+	}
+	return nil
+}
+
+type SetModeRequest struct {
+	// "brew" or "steam"
+	Mode                 string   `protobuf:"bytes,1,opt,name=mode,proto3" json:"mode,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SetModeRequest) Reset()         { *m = SetModeRequest{} }
+func (m *SetModeRequest) String() string { return proto.CompactTextString(m) }
+func (*SetModeRequest) ProtoMessage()    {}
+func (*SetModeRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{16}
+}
+
+func (m *SetModeRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetModeRequest.Unmarshal(m, b)
+}
+func (m *SetModeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetModeRequest.Marshal(b, m, deterministic)
+}
+func (m *SetModeRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetModeRequest.Merge(m, src)
+}
+func (m *SetModeRequest) XXX_Size() int {
+	return xxx_messageInfo_SetModeRequest.Size(m)
+}
+func (m *SetModeRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetModeRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetModeRequest proto.InternalMessageInfo
+
+func (m *SetModeRequest) GetMode() string {
+	if m != nil {
+		return m.Mode
+	}
+	return ""
+}
+
+type WatchModeRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *WatchModeRequest) Reset()         { *m = WatchModeRequest{} }
+func (m *WatchModeRequest) String() string { return proto.CompactTextString(m) }
+func (*WatchModeRequest) ProtoMessage()    {}
+func (*WatchModeRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{17}
+}
+
+func (m *WatchModeRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WatchModeRequest.Unmarshal(m, b)
+}
+func (m *WatchModeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WatchModeRequest.Marshal(b, m, deterministic)
+}
+func (m *WatchModeRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WatchModeRequest.Merge(m, src)
+}
+func (m *WatchModeRequest) XXX_Size() int {
+	return xxx_messageInfo_WatchModeRequest.Size(m)
+}
+func (m *WatchModeRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_WatchModeRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WatchModeRequest proto.InternalMessageInfo
+
+type ModeStatus struct {
+	// "brew" or "steam"
+	Mode string `protobuf:"bytes,1,opt,name=mode,proto3" json:"mode,omitempty"`
+	// why the mode was entered, e.g. "power_button", "steam_switch" or
+	// "timeout"
+	Reason string               `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	Since  *timestamp.Timestamp `protobuf:"bytes,3,opt,name=since,proto3" json:"since,omitempty"`
+	// when steam mode reverts to brew mode, unset if it does not
+	RevertAt             *timestamp.Timestamp `protobuf:"bytes,4,opt,name=revert_at,json=revertAt,proto3" json:"revert_at,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *ModeStatus) Reset()         { *m = ModeStatus{} }
+func (m *ModeStatus) String() string { return proto.CompactTextString(m) }
+func (*ModeStatus) ProtoMessage()    {}
+func (*ModeStatus) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{18}
+}
+
+func (m *ModeStatus) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ModeStatus.Unmarshal(m, b)
+}
+func (m *ModeStatus) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ModeStatus.Marshal(b, m, deterministic)
+}
+func (m *ModeStatus) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ModeStatus.Merge(m, src)
+}
+func (m *ModeStatus) XXX_Size() int {
+	return xxx_messageInfo_ModeStatus.Size(m)
+}
+func (m *ModeStatus) XXX_DiscardUnknown() {
+	xxx_messageInfo_ModeStatus.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ModeStatus proto.InternalMessageInfo
+
+func (m *ModeStatus) GetMode() string {
+	if m != nil {
+		return m.Mode
+	}
+	return ""
+}
+
+func (m *ModeStatus) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+func (m *ModeStatus) GetSince() *timestamp.Timestamp {
+	if m != nil {
+		return m.Since
+	}
+	return nil
+}
+
+func (m *ModeStatus) GetRevertAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.RevertAt
+	}
+	return nil
+}
+
+type WatchShotTimerRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *WatchShotTimerRequest) Reset()         { *m = WatchShotTimerRequest{} }
+func (m *WatchShotTimerRequest) String() string { return proto.CompactTextString(m) }
+func (*WatchShotTimerRequest) ProtoMessage()    {}
+func (*WatchShotTimerRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{19}
+}
+
+func (m *WatchShotTimerRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WatchShotTimerRequest.Unmarshal(m, b)
+}
+func (m *WatchShotTimerRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WatchShotTimerRequest.Marshal(b, m, deterministic)
+}
+func (m *WatchShotTimerRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WatchShotTimerRequest.Merge(m, src)
+}
+func (m *WatchShotTimerRequest) XXX_Size() int {
+	return xxx_messageInfo_WatchShotTimerRequest.Size(m)
+}
+func (m *WatchShotTimerRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_WatchShotTimerRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WatchShotTimerRequest proto.InternalMessageInfo
+
+type ShotTimer struct {
+	// whether the shot is being pulled
+	Running bool `protobuf:"varint,1,opt,name=running,proto3" json:"running,omitempty"`
+	// unset if no shot was pulled yet
+	Start *timestamp.Timestamp `protobuf:"bytes,2,opt,name=start,proto3" json:"start,omitempty"`
+	// unset while the shot is being pulled
+	End *timestamp.Timestamp `protobuf:"bytes,3,opt,name=end,proto3" json:"end,omitempty"`
+	// duration of the shot in seconds, up to now while it is being pulled
+	Seconds float32 `protobuf:"fixed32,4,opt,name=seconds,proto3" json:"seconds,omitempty"`
+	// how the shot was detected, "switch", "temperature" or "button"
+	Source               string   `protobuf:"bytes,5,opt,name=source,proto3" json:"source,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ShotTimer) Reset()         { *m = ShotTimer{} }
+func (m *ShotTimer) String() string { return proto.CompactTextString(m) }
+func (*ShotTimer) ProtoMessage()    {}
+func (*ShotTimer) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{20}
+}
+
+func (m *ShotTimer) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ShotTimer.Unmarshal(m, b)
+}
+func (m *ShotTimer) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ShotTimer.Marshal(b, m, deterministic)
+}
+func (m *ShotTimer) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ShotTimer.Merge(m, src)
+}
+func (m *ShotTimer) XXX_Size() int {
+	return xxx_messageInfo_ShotTimer.Size(m)
+}
+func (m *ShotTimer) XXX_DiscardUnknown() {
+	xxx_messageInfo_ShotTimer.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ShotTimer proto.InternalMessageInfo
+
+func (m *ShotTimer) GetRunning() bool {
+	if m != nil {
+		return m.Running
+	}
+	return false
+}
+
+func (m *ShotTimer) GetStart() *timestamp.Timestamp {
+	if m != nil {
+		return m.Start
+	}
+	return nil
+}
+
+func (m *ShotTimer) GetEnd() *timestamp.Timestamp {
+	if m != nil {
+		return m.End
+	}
+	return nil
+}
+
+func (m *ShotTimer) GetSeconds() float32 {
+	if m != nil {
+		return m.Seconds
+	}
+	return 0
+}
+
+func (m *ShotTimer) GetSource() string {
+	if m != nil {
+		return m.Source
+	}
+	return ""
+}
+
+type ShotNotes struct {
+	// ground coffee in g
+	Dose float32 `protobuf:"fixed32,1,opt,name=dose,proto3" json:"dose,omitempty"`
+	// espresso in the cup in g
+	Yield float32 `protobuf:"fixed32,2,opt,name=yield,proto3" json:"yield,omitempty"`
+	Bean  string  `protobuf:"bytes,3,opt,name=bean,proto3" json:"bean,omitempty"`
+	Grind string  `protobuf:"bytes,4,opt,name=grind,proto3" json:"grind,omitempty"`
+	// 1 to 5, unrated if 0
+	Rating               int32    `protobuf:"varint,5,opt,name=rating,proto3" json:"rating,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ShotNotes) Reset()         { *m = ShotNotes{} }
+func (m *ShotNotes) String() string { return proto.CompactTextString(m) }
+func (*ShotNotes) ProtoMessage()    {}
+func (*ShotNotes) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{21}
+}
+
+func (m *ShotNotes) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ShotNotes.Unmarshal(m, b)
+}
+func (m *ShotNotes) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ShotNotes.Marshal(b, m, deterministic)
+}
+func (m *ShotNotes) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ShotNotes.Merge(m, src)
+}
+func (m *ShotNotes) XXX_Size() int {
+	return xxx_messageInfo_ShotNotes.Size(m)
+}
+func (m *ShotNotes) XXX_DiscardUnknown() {
+	xxx_messageInfo_ShotNotes.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ShotNotes proto.InternalMessageInfo
+
+func (m *ShotNotes) GetDose() float32 {
+	if m != nil {
+		return m.Dose
+	}
+	return 0
+}
+
+func (m *ShotNotes) GetYield() float32 {
+	if m != nil {
+		return m.Yield
+	}
+	return 0
+}
+
+func (m *ShotNotes) GetBean() string {
+	if m != nil {
+		return m.Bean
+	}
+	return ""
+}
+
+func (m *ShotNotes) GetGrind() string {
+	if m != nil {
+		return m.Grind
+	}
+	return ""
+}
+
+func (m *ShotNotes) GetRating() int32 {
+	if m != nil {
+		return m.Rating
+	}
+	return 0
+}
+
+type Shot struct {
+	Id      uint64               `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Start   *timestamp.Timestamp `protobuf:"bytes,2,opt,name=start,proto3" json:"start,omitempty"`
+	End     *timestamp.Timestamp `protobuf:"bytes,3,opt,name=end,proto3" json:"end,omitempty"`
+	Seconds float32              `protobuf:"fixed32,4,opt,name=seconds,proto3" json:"seconds,omitempty"`
+	// how the shot was detected, "switch", "temperature" or "button"
+	Source string `protobuf:"bytes,5,opt,name=source,proto3" json:"source,omitempty"`
+	// setpoint, strategy and its parameters in effect when the shot started
+	Setpoint   float32            `protobuf:"fixed32,6,opt,name=setpoint,proto3" json:"setpoint,omitempty"`
+	Strategy   string             `protobuf:"bytes,7,opt,name=strategy,proto3" json:"strategy,omitempty"`
+	Parameters map[string]float32 `protobuf:"bytes,8,rep,name=parameters,proto3" json:"parameters,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"fixed32,2,opt,name=value,proto3"`
+	// temperatures from shortly before the shot until shortly after it, empty
+	// in ListShots
+	BoilerTrace []*TemperatureSample `protobuf:"bytes,9,rep,name=boiler_trace,json=boilerTrace,proto3" json:"boiler_trace,omitempty"`
+	GroupTrace  []*TemperatureSample `protobuf:"bytes,10,rep,name=group_trace,json=groupTrace,proto3" json:"group_trace,omitempty"`
+	Notes       *ShotNotes           `protobuf:"bytes,11,opt,name=notes,proto3" json:"notes,omitempty"`
+	// feed-forward heater boost applied from the start of the shot, empty if
+	// none was
+	Boost                []*BoostStep `protobuf:"bytes,12,rep,name=boost,proto3" json:"boost,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *Shot) Reset()         { *m = Shot{} }
+func (m *Shot) String() string { return proto.CompactTextString(m) }
+func (*Shot) ProtoMessage()    {}
+func (*Shot) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{22}
+}
+
+func (m *Shot) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Shot.Unmarshal(m, b)
+}
+func (m *Shot) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Shot.Marshal(b, m, deterministic)
+}
+func (m *Shot) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Shot.Merge(m, src)
+}
+func (m *Shot) XXX_Size() int {
+	return xxx_messageInfo_Shot.Size(m)
+}
+func (m *Shot) XXX_DiscardUnknown() {
+	xxx_messageInfo_Shot.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Shot proto.InternalMessageInfo
+
+func (m *Shot) GetId() uint64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *Shot) GetStart() *timestamp.Timestamp {
+	if m != nil {
+		return m.Start
+	}
+	return nil
+}
+
+func (m *Shot) GetEnd() *timestamp.Timestamp {
+	if m != nil {
+		return m.End
+	}
+	return nil
+}
+
+func (m *Shot) GetSeconds() float32 {
+	if m != nil {
+		return m.Seconds
+	}
+	return 0
+}
+
+func (m *Shot) GetSource() string {
+	if m != nil {
+		return m.Source
+	}
+	return ""
+}
+
+func (m *Shot) GetSetpoint() float32 {
+	if m != nil {
+		return m.Setpoint
+	}
+	return 0
+}
+
+func (m *Shot) GetStrategy() string {
+	if m != nil {
+		return m.Strategy
+	}
+	return ""
+}
+
+func (m *Shot) GetParameters() map[string]float32 {
+	if m != nil {
+		return m.Parameters
+	}
+	return nil
+}
+
+func (m *Shot) GetBoilerTrace() []*TemperatureSample {
+	if m != nil {
+		return m.BoilerTrace
+	}
+	return nil
+}
+
+func (m *Shot) GetGroupTrace() []*TemperatureSample {
+	if m != nil {
+		return m.GroupTrace
+	}
+	return nil
+}
+
+func (m *Shot) GetNotes() *ShotNotes {
+	if m != nil {
+		return m.Notes
+	}
+	return nil
+}
+
+func (m *Shot) GetBoost() []*BoostStep {
+	if m != nil {
+		return m.Boost
+	}
+	return nil
+}
+
+type ListShotsRequest struct {
+	// shots started at or after from and before to, unbounded if unset
+	From *timestamp.Timestamp `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To   *timestamp.Timestamp `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	// next_page_token of the previous page, the newest shots if 0
+	PageToken uint64 `protobuf:"varint,3,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	// default 50, at most 1000
+	PageSize             int32    `protobuf:"varint,4,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListShotsRequest) Reset()         { *m = ListShotsRequest{} }
+func (m *ListShotsRequest) String() string { return proto.CompactTextString(m) }
+func (*ListShotsRequest) ProtoMessage()    {}
+func (*ListShotsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{23}
+}
+
+func (m *ListShotsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListShotsRequest.Unmarshal(m, b)
+}
+func (m *ListShotsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListShotsRequest.Marshal(b, m, deterministic)
+}
+func (m *ListShotsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListShotsRequest.Merge(m, src)
+}
+func (m *ListShotsRequest) XXX_Size() int {
+	return xxx_messageInfo_ListShotsRequest.Size(m)
+}
+func (m *ListShotsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListShotsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListShotsRequest proto.InternalMessageInfo
+
+func (m *ListShotsRequest) GetFrom() *timestamp.Timestamp {
+	if m != nil {
+		return m.From
+	}
+	return nil
+}
+
+func (m *ListShotsRequest) GetTo() *timestamp.Timestamp {
+	if m != nil {
+		return m.To
+	}
+	return nil
+}
+
+func (m *ListShotsRequest) GetPageToken() uint64 {
+	if m != nil {
+		return m.PageToken
+	}
+	return 0
+}
+
+func (m *ListShotsRequest) GetPageSize() int32 {
+	if m != nil {
+		return m.PageSize
+	}
+	return 0
+}
+
+type ListShotsResponse struct {
+	Shots []*Shot `protobuf:"bytes,1,rep,name=shots,proto3" json:"shots,omitempty"`
+	// 0 if this is the last page
+	NextPageToken        uint64   `protobuf:"varint,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListShotsResponse) Reset()         { *m = ListShotsResponse{} }
+func (m *ListShotsResponse) String() string { return proto.CompactTextString(m) }
+func (*ListShotsResponse) ProtoMessage()    {}
+func (*ListShotsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{24}
+}
+
+func (m *ListShotsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListShotsResponse.Unmarshal(m, b)
+}
+func (m *ListShotsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListShotsResponse.Marshal(b, m, deterministic)
+}
+func (m *ListShotsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListShotsResponse.Merge(m, src)
+}
+func (m *ListShotsResponse) XXX_Size() int {
+	return xxx_messageInfo_ListShotsResponse.Size(m)
+}
+func (m *ListShotsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListShotsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListShotsResponse proto.InternalMessageInfo
+
+func (m *ListShotsResponse) GetShots() []*Shot {
+	if m != nil {
+		return m.Shots
+	}
+	return nil
+}
+
+func (m *ListShotsResponse) GetNextPageToken() uint64 {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return 0
+}
+
+type GetShotRequest struct {
+	Id                   uint64   `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetShotRequest) Reset()         { *m = GetShotRequest{} }
+func (m *GetShotRequest) String() string { return proto.CompactTextString(m) }
+func (*GetShotRequest) ProtoMessage()    {}
+func (*GetShotRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{25}
+}
+
+func (m *GetShotRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetShotRequest.Unmarshal(m, b)
+}
+func (m *GetShotRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetShotRequest.Marshal(b, m, deterministic)
+}
+func (m *GetShotRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetShotRequest.Merge(m, src)
+}
+func (m *GetShotRequest) XXX_Size() int {
+	return xxx_messageInfo_GetShotRequest.Size(m)
+}
+func (m *GetShotRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetShotRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetShotRequest proto.InternalMessageInfo
+
+func (m *GetShotRequest) GetId() uint64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+type UpdateShotNotesRequest struct {
+	Id                   uint64     `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Notes                *ShotNotes `protobuf:"bytes,2,opt,name=notes,proto3" json:"notes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *UpdateShotNotesRequest) Reset()         { *m = UpdateShotNotesRequest{} }
+func (m *UpdateShotNotesRequest) String() string { return proto.CompactTextString(m) }
+func (*UpdateShotNotesRequest) ProtoMessage()    {}
+func (*UpdateShotNotesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{26}
+}
+
+func (m *UpdateShotNotesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UpdateShotNotesRequest.Unmarshal(m, b)
+}
+func (m *UpdateShotNotesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UpdateShotNotesRequest.Marshal(b, m, deterministic)
+}
+func (m *UpdateShotNotesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UpdateShotNotesRequest.Merge(m, src)
+}
+func (m *UpdateShotNotesRequest) XXX_Size() int {
+	return xxx_messageInfo_UpdateShotNotesRequest.Size(m)
+}
+func (m *UpdateShotNotesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_UpdateShotNotesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UpdateShotNotesRequest proto.InternalMessageInfo
+
+func (m *UpdateShotNotesRequest) GetId() uint64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *UpdateShotNotesRequest) GetNotes() *ShotNotes {
+	if m != nil {
+		return m.Notes
+	}
+	return nil
+}
+
+type DeleteShotRequest struct {
+	Id                   uint64   `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteShotRequest) Reset()         { *m = DeleteShotRequest{} }
+func (m *DeleteShotRequest) String() string { return proto.CompactTextString(m) }
+func (*DeleteShotRequest) ProtoMessage()    {}
+func (*DeleteShotRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{27}
+}
+
+func (m *DeleteShotRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteShotRequest.Unmarshal(m, b)
+}
+func (m *DeleteShotRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteShotRequest.Marshal(b, m, deterministic)
+}
+func (m *DeleteShotRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteShotRequest.Merge(m, src)
+}
+func (m *DeleteShotRequest) XXX_Size() int {
+	return xxx_messageInfo_DeleteShotRequest.Size(m)
+}
+func (m *DeleteShotRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteShotRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteShotRequest proto.InternalMessageInfo
+
+func (m *DeleteShotRequest) GetId() uint64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+type DeleteShotResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteShotResponse) Reset()         { *m = DeleteShotResponse{} }
+func (m *DeleteShotResponse) String() string { return proto.CompactTextString(m) }
+func (*DeleteShotResponse) ProtoMessage()    {}
+func (*DeleteShotResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{28}
+}
+
+func (m *DeleteShotResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteShotResponse.Unmarshal(m, b)
+}
+func (m *DeleteShotResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteShotResponse.Marshal(b, m, deterministic)
+}
+func (m *DeleteShotResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteShotResponse.Merge(m, src)
+}
+func (m *DeleteShotResponse) XXX_Size() int {
+	return xxx_messageInfo_DeleteShotResponse.Size(m)
+}
+func (m *DeleteShotResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteShotResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteShotResponse proto.InternalMessageInfo
+
+type ExportShotsRequest struct {
+	// "csv" (default) or "json"
+	Format string `protobuf:"bytes,1,opt,name=format,proto3" json:"format,omitempty"`
+	// shots started at or after from and before to, unbounded if unset
+	From                 *timestamp.Timestamp `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	To                   *timestamp.Timestamp `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *ExportShotsRequest) Reset()         { *m = ExportShotsRequest{} }
+func (m *ExportShotsRequest) String() string { return proto.CompactTextString(m) }
+func (*ExportShotsRequest) ProtoMessage()    {}
+func (*ExportShotsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{29}
+}
+
+func (m *ExportShotsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ExportShotsRequest.Unmarshal(m, b)
+}
+func (m *ExportShotsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ExportShotsRequest.Marshal(b, m, deterministic)
+}
+func (m *ExportShotsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExportShotsRequest.Merge(m, src)
+}
+func (m *ExportShotsRequest) XXX_Size() int {
+	return xxx_messageInfo_ExportShotsRequest.Size(m)
+}
+func (m *ExportShotsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExportShotsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExportShotsRequest proto.InternalMessageInfo
+
+func (m *ExportShotsRequest) GetFormat() string {
+	if m != nil {
+		return m.Format
+	}
+	return ""
+}
+
+func (m *ExportShotsRequest) GetFrom() *timestamp.Timestamp {
+	if m != nil {
+		return m.From
+	}
+	return nil
+}
+
+func (m *ExportShotsRequest) GetTo() *timestamp.Timestamp {
+	if m != nil {
+		return m.To
+	}
+	return nil
+}
+
+type ExportShotsResponse struct {
+	Data []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	// e.g. "text/csv"
+	ContentType          string   `protobuf:"bytes,2,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ExportShotsResponse) Reset()         { *m = ExportShotsResponse{} }
+func (m *ExportShotsResponse) String() string { return proto.CompactTextString(m) }
+func (*ExportShotsResponse) ProtoMessage()    {}
+func (*ExportShotsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{30}
+}
+
+func (m *ExportShotsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ExportShotsResponse.Unmarshal(m, b)
+}
+func (m *ExportShotsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ExportShotsResponse.Marshal(b, m, deterministic)
+}
+func (m *ExportShotsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExportShotsResponse.Merge(m, src)
+}
+func (m *ExportShotsResponse) XXX_Size() int {
+	return xxx_messageInfo_ExportShotsResponse.Size(m)
+}
+func (m *ExportShotsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExportShotsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExportShotsResponse proto.InternalMessageInfo
+
+func (m *ExportShotsResponse) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func (m *ExportShotsResponse) GetContentType() string {
+	if m != nil {
+		return m.ContentType
+	}
+	return ""
+}
+
+type ListEventsRequest struct {
+	// events at or after from and before to, unbounded if unset
+	From *timestamp.Timestamp `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To   *timestamp.Timestamp `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	// e.g. "power_on", "power_off", "schedule_suppressed", "auto_off",
+	// "total_off", "setpoint_changed", "parameters_changed", "mode_changed",
+	// "fault" or "fault_reset", any if empty
+	Types []string `protobuf:"bytes,3,rep,name=types,proto3" json:"types,omitempty"`
+	// next_page_token of the previous page, the newest events if 0
+	PageToken uint64 `protobuf:"varint,4,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	// default 100, at most 1000
+	PageSize             int32    `protobuf:"varint,5,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListEventsRequest) Reset()         { *m = ListEventsRequest{} }
+func (m *ListEventsRequest) String() string { return proto.CompactTextString(m) }
+func (*ListEventsRequest) ProtoMessage()    {}
+func (*ListEventsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{31}
+}
+
+func (m *ListEventsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListEventsRequest.Unmarshal(m, b)
+}
+func (m *ListEventsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListEventsRequest.Marshal(b, m, deterministic)
+}
+func (m *ListEventsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListEventsRequest.Merge(m, src)
+}
+func (m *ListEventsRequest) XXX_Size() int {
+	return xxx_messageInfo_ListEventsRequest.Size(m)
+}
+func (m *ListEventsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListEventsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListEventsRequest proto.InternalMessageInfo
+
+func (m *ListEventsRequest) GetFrom() *timestamp.Timestamp {
+	if m != nil {
+		return m.From
+	}
+	return nil
+}
+
+func (m *ListEventsRequest) GetTo() *timestamp.Timestamp {
+	if m != nil {
+		return m.To
+	}
+	return nil
+}
+
+func (m *ListEventsRequest) GetTypes() []string {
+	if m != nil {
+		return m.Types
+	}
+	return nil
+}
+
+func (m *ListEventsRequest) GetPageToken() uint64 {
+	if m != nil {
+		return m.PageToken
+	}
+	return 0
+}
+
+func (m *ListEventsRequest) GetPageSize() int32 {
+	if m != nil {
+		return m.PageSize
+	}
+	return 0
+}
+
+type Event struct {
+	Id   uint64               `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	At   *timestamp.Timestamp `protobuf:"bytes,2,opt,name=at,proto3" json:"at,omitempty"`
+	Type string               `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	// why it happened, e.g. "power_button" or "schedule_end"
+	Cause                string            `protobuf:"bytes,4,opt,name=cause,proto3" json:"cause,omitempty"`
+	Message              string            `protobuf:"bytes,5,opt,name=message,proto3" json:"message,omitempty"`
+	Details              map[string]string `protobuf:"bytes,6,rep,name=details,proto3" json:"details,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *Event) Reset()         { *m = Event{} }
+func (m *Event) String() string { return proto.CompactTextString(m) }
+func (*Event) ProtoMessage()    {}
+func (*Event) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{32}
+}
+
+func (m *Event) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Event.Unmarshal(m, b)
+}
+func (m *Event) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Event.Marshal(b, m, deterministic)
+}
+func (m *Event) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Event.Merge(m, src)
+}
+func (m *Event) XXX_Size() int {
+	return xxx_messageInfo_Event.Size(m)
+}
+func (m *Event) XXX_DiscardUnknown() {
+	xxx_messageInfo_Event.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Event proto.InternalMessageInfo
+
+func (m *Event) GetId() uint64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *Event) GetAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.At
+	}
+	return nil
+}
+
+func (m *Event) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *Event) GetCause() string {
+	if m != nil {
+		return m.Cause
+	}
+	return ""
+}
+
+func (m *Event) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+func (m *Event) GetDetails() map[string]string {
+	if m != nil {
+		return m.Details
+	}
+	return nil
+}
+
+type ListEventsResponse struct {
+	Events []*Event `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	// 0 if this is the last page
+	NextPageToken        uint64   `protobuf:"varint,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListEventsResponse) Reset()         { *m = ListEventsResponse{} }
+func (m *ListEventsResponse) String() string { return proto.CompactTextString(m) }
+func (*ListEventsResponse) ProtoMessage()    {}
+func (*ListEventsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{33}
+}
+
+func (m *ListEventsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListEventsResponse.Unmarshal(m, b)
+}
+func (m *ListEventsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListEventsResponse.Marshal(b, m, deterministic)
+}
+func (m *ListEventsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListEventsResponse.Merge(m, src)
+}
+func (m *ListEventsResponse) XXX_Size() int {
+	return xxx_messageInfo_ListEventsResponse.Size(m)
+}
+func (m *ListEventsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListEventsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListEventsResponse proto.InternalMessageInfo
+
+func (m *ListEventsResponse) GetEvents() []*Event {
+	if m != nil {
+		return m.Events
+	}
+	return nil
+}
+
+func (m *ListEventsResponse) GetNextPageToken() uint64 {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return 0
+}
+
+type GetPowerStatusRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetPowerStatusRequest) Reset()         { *m = GetPowerStatusRequest{} }
+func (m *GetPowerStatusRequest) String() string { return proto.CompactTextString(m) }
+func (*GetPowerStatusRequest) ProtoMessage()    {}
+func (*GetPowerStatusRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{34}
+}
+
+func (m *GetPowerStatusRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetPowerStatusRequest.Unmarshal(m, b)
+}
+func (m *GetPowerStatusRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetPowerStatusRequest.Marshal(b, m, deterministic)
+}
+func (m *GetPowerStatusRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetPowerStatusRequest.Merge(m, src)
+}
+func (m *GetPowerStatusRequest) XXX_Size() int {
+	return xxx_messageInfo_GetPowerStatusRequest.Size(m)
+}
+func (m *GetPowerStatusRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetPowerStatusRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetPowerStatusRequest proto.InternalMessageInfo
+
+type PowerStatus struct {
+	// state of the power manager, e.g. "scheduled_on" or "total_off"
+	State string `protobuf:"bytes,1,opt,name=state,proto3" json:"state,omitempty"`
+	// why the current state was entered, e.g. "power_button"
+	Reason  string               `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	Since   *timestamp.Timestamp `protobuf:"bytes,3,opt,name=since,proto3" json:"since,omitempty"`
+	PowerOn bool                 `protobuf:"varint,4,opt,name=power_on,json=powerOn,proto3" json:"power_on,omitempty"`
+	// unset while powered off
+	OnSince *timestamp.Timestamp `protobuf:"bytes,5,opt,name=on_since,json=onSince,proto3" json:"on_since,omitempty"`
+	// when auto-off powers off the machine, unset if it will not
+	AutoOffAt *timestamp.Timestamp `protobuf:"bytes,6,opt,name=auto_off_at,json=autoOffAt,proto3" json:"auto_off_at,omitempty"`
+	// pattern of the power LED, e.g. "slow_blink"
+	LedPattern string `protobuf:"bytes,7,opt,name=led_pattern,json=ledPattern,proto3" json:"led_pattern,omitempty"`
+	// "brew" or "steam"
+	Mode                 string   `protobuf:"bytes,8,opt,name=mode,proto3" json:"mode,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PowerStatus) Reset()         { *m = PowerStatus{} }
+func (m *PowerStatus) String() string { return proto.CompactTextString(m) }
+func (*PowerStatus) ProtoMessage()    {}
+func (*PowerStatus) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{35}
+}
+
+func (m *PowerStatus) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PowerStatus.Unmarshal(m, b)
+}
+func (m *PowerStatus) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PowerStatus.Marshal(b, m, deterministic)
+}
+func (m *PowerStatus) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PowerStatus.Merge(m, src)
+}
+func (m *PowerStatus) XXX_Size() int {
+	return xxx_messageInfo_PowerStatus.Size(m)
+}
+func (m *PowerStatus) XXX_DiscardUnknown() {
+	xxx_messageInfo_PowerStatus.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PowerStatus proto.InternalMessageInfo
+
+func (m *PowerStatus) GetState() string {
+	if m != nil {
+		return m.State
+	}
+	return ""
+}
+
+func (m *PowerStatus) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+func (m *PowerStatus) GetSince() *timestamp.Timestamp {
+	if m != nil {
+		return m.Since
+	}
+	return nil
+}
+
+func (m *PowerStatus) GetPowerOn() bool {
+	if m != nil {
+		return m.PowerOn
+	}
+	return false
+}
+
+func (m *PowerStatus) GetOnSince() *timestamp.Timestamp {
+	if m != nil {
+		return m.OnSince
+	}
+	return nil
+}
+
+func (m *PowerStatus) GetAutoOffAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.AutoOffAt
+	}
+	return nil
+}
+
+func (m *PowerStatus) GetLedPattern() string {
+	if m != nil {
+		return m.LedPattern
+	}
+	return ""
+}
+
+func (m *PowerStatus) GetMode() string {
+	if m != nil {
+		return m.Mode
+	}
+	return ""
+}
+
+type SetPowerRequest struct {
+	On                   bool     `protobuf:"varint,1,opt,name=on,proto3" json:"on,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SetPowerRequest) Reset()         { *m = SetPowerRequest{} }
+func (m *SetPowerRequest) String() string { return proto.CompactTextString(m) }
+func (*SetPowerRequest) ProtoMessage()    {}
+func (*SetPowerRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{36}
+}
+
+func (m *SetPowerRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetPowerRequest.Unmarshal(m, b)
+}
+func (m *SetPowerRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetPowerRequest.Marshal(b, m, deterministic)
+}
+func (m *SetPowerRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetPowerRequest.Merge(m, src)
+}
+func (m *SetPowerRequest) XXX_Size() int {
+	return xxx_messageInfo_SetPowerRequest.Size(m)
+}
+func (m *SetPowerRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetPowerRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetPowerRequest proto.InternalMessageInfo
+
+func (m *SetPowerRequest) GetOn() bool {
+	if m != nil {
+		return m.On
+	}
+	return false
+}
+
+type TotalPowerOffRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TotalPowerOffRequest) Reset()         { *m = TotalPowerOffRequest{} }
+func (m *TotalPowerOffRequest) String() string { return proto.CompactTextString(m) }
+func (*TotalPowerOffRequest) ProtoMessage()    {}
+func (*TotalPowerOffRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{37}
+}
+
+func (m *TotalPowerOffRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TotalPowerOffRequest.Unmarshal(m, b)
+}
+func (m *TotalPowerOffRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TotalPowerOffRequest.Marshal(b, m, deterministic)
+}
+func (m *TotalPowerOffRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TotalPowerOffRequest.Merge(m, src)
+}
+func (m *TotalPowerOffRequest) XXX_Size() int {
+	return xxx_messageInfo_TotalPowerOffRequest.Size(m)
+}
+func (m *TotalPowerOffRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_TotalPowerOffRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TotalPowerOffRequest proto.InternalMessageInfo
+
+type SetScheduleEnabledRequest struct {
+	Enabled              bool     `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SetScheduleEnabledRequest) Reset()         { *m = SetScheduleEnabledRequest{} }
+func (m *SetScheduleEnabledRequest) String() string { return proto.CompactTextString(m) }
+func (*SetScheduleEnabledRequest) ProtoMessage()    {}
+func (*SetScheduleEnabledRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{38}
+}
+
+func (m *SetScheduleEnabledRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetScheduleEnabledRequest.Unmarshal(m, b)
+}
+func (m *SetScheduleEnabledRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetScheduleEnabledRequest.Marshal(b, m, deterministic)
+}
+func (m *SetScheduleEnabledRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetScheduleEnabledRequest.Merge(m, src)
+}
+func (m *SetScheduleEnabledRequest) XXX_Size() int {
+	return xxx_messageInfo_SetScheduleEnabledRequest.Size(m)
+}
+func (m *SetScheduleEnabledRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetScheduleEnabledRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetScheduleEnabledRequest proto.InternalMessageInfo
+
+func (m *SetScheduleEnabledRequest) GetEnabled() bool {
+	if m != nil {
+		return m.Enabled
+	}
+	return false
+}
+
+type WatchPowerStatusRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *WatchPowerStatusRequest) Reset()         { *m = WatchPowerStatusRequest{} }
+func (m *WatchPowerStatusRequest) String() string { return proto.CompactTextString(m) }
+func (*WatchPowerStatusRequest) ProtoMessage()    {}
+func (*WatchPowerStatusRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{39}
+}
+
+func (m *WatchPowerStatusRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WatchPowerStatusRequest.Unmarshal(m, b)
+}
+func (m *WatchPowerStatusRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WatchPowerStatusRequest.Marshal(b, m, deterministic)
+}
+func (m *WatchPowerStatusRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WatchPowerStatusRequest.Merge(m, src)
+}
+func (m *WatchPowerStatusRequest) XXX_Size() int {
+	return xxx_messageInfo_WatchPowerStatusRequest.Size(m)
+}
+func (m *WatchPowerStatusRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_WatchPowerStatusRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WatchPowerStatusRequest proto.InternalMessageInfo
+
+type PowerOnIntervalRequest struct {
+	// english name of the weekday the interval starts on, e.g. "Monday"
+	Weekday              string           `protobuf:"bytes,1,opt,name=weekday,proto3" json:"weekday,omitempty"`
+	Interval             *PowerOnInterval `protobuf:"bytes,2,opt,name=interval,proto3" json:"interval,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *PowerOnIntervalRequest) Reset()         { *m = PowerOnIntervalRequest{} }
+func (m *PowerOnIntervalRequest) String() string { return proto.CompactTextString(m) }
+func (*PowerOnIntervalRequest) ProtoMessage()    {}
+func (*PowerOnIntervalRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{40}
+}
+
+func (m *PowerOnIntervalRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PowerOnIntervalRequest.Unmarshal(m, b)
+}
+func (m *PowerOnIntervalRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PowerOnIntervalRequest.Marshal(b, m, deterministic)
+}
+func (m *PowerOnIntervalRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PowerOnIntervalRequest.Merge(m, src)
+}
+func (m *PowerOnIntervalRequest) XXX_Size() int {
+	return xxx_messageInfo_PowerOnIntervalRequest.Size(m)
+}
+func (m *PowerOnIntervalRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_PowerOnIntervalRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PowerOnIntervalRequest proto.InternalMessageInfo
+
+func (m *PowerOnIntervalRequest) GetWeekday() string {
+	if m != nil {
+		return m.Weekday
+	}
+	return ""
+}
+
+func (m *PowerOnIntervalRequest) GetInterval() *PowerOnInterval {
+	if m != nil {
+		return m.Interval
+	}
+	return nil
+}
+
+type GetPowerScheduleRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetPowerScheduleRequest) Reset()         { *m = GetPowerScheduleRequest{} }
+func (m *GetPowerScheduleRequest) String() string { return proto.CompactTextString(m) }
+func (*GetPowerScheduleRequest) ProtoMessage()    {}
+func (*GetPowerScheduleRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{41}
+}
+
+func (m *GetPowerScheduleRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetPowerScheduleRequest.Unmarshal(m, b)
+}
+func (m *GetPowerScheduleRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetPowerScheduleRequest.Marshal(b, m, deterministic)
+}
+func (m *GetPowerScheduleRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetPowerScheduleRequest.Merge(m, src)
+}
+func (m *GetPowerScheduleRequest) XXX_Size() int {
+	return xxx_messageInfo_GetPowerScheduleRequest.Size(m)
+}
+func (m *GetPowerScheduleRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetPowerScheduleRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetPowerScheduleRequest proto.InternalMessageInfo
+
+// PowerOnInterval is the half-open interval [from, to) of wall clock time in
+// the schedule's timezone. An interval whose to is before its from crosses
+// midnight and ends on the next day.
+type PowerOnInterval struct {
+	// HH:MM, e.g. "06:45"
+	From string `protobuf:"bytes,3,opt,name=from,proto3" json:"from,omitempty"`
+	// HH:MM, "24:00" being the end of the day
+	To                   string   `protobuf:"bytes,4,opt,name=to,proto3" json:"to,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PowerOnInterval) Reset()         { *m = PowerOnInterval{} }
+func (m *PowerOnInterval) String() string { return proto.CompactTextString(m) }
+func (*PowerOnInterval) ProtoMessage()    {}
+func (*PowerOnInterval) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{42}
+}
+
+func (m *PowerOnInterval) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PowerOnInterval.Unmarshal(m, b)
+}
+func (m *PowerOnInterval) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PowerOnInterval.Marshal(b, m, deterministic)
+}
+func (m *PowerOnInterval) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PowerOnInterval.Merge(m, src)
+}
+func (m *PowerOnInterval) XXX_Size() int {
+	return xxx_messageInfo_PowerOnInterval.Size(m)
+}
+func (m *PowerOnInterval) XXX_DiscardUnknown() {
+	xxx_messageInfo_PowerOnInterval.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PowerOnInterval proto.InternalMessageInfo
+
+func (m *PowerOnInterval) GetFrom() string {
+	if m != nil {
+		return m.From
+	}
+	return ""
+}
+
+func (m *PowerOnInterval) GetTo() string {
+	if m != nil {
+		return m.To
+	}
+	return ""
+}
+
+type DaySchedule struct {
+	// english name of the weekday, e.g. "Monday"
+	Weekday              string             `protobuf:"bytes,1,opt,name=weekday,proto3" json:"weekday,omitempty"`
+	Intervals            []*PowerOnInterval `protobuf:"bytes,2,rep,name=intervals,proto3" json:"intervals,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *DaySchedule) Reset()         { *m = DaySchedule{} }
+func (m *DaySchedule) String() string { return proto.CompactTextString(m) }
+func (*DaySchedule) ProtoMessage()    {}
+func (*DaySchedule) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{43}
+}
+
+func (m *DaySchedule) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DaySchedule.Unmarshal(m, b)
+}
+func (m *DaySchedule) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DaySchedule.Marshal(b, m, deterministic)
+}
+func (m *DaySchedule) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DaySchedule.Merge(m, src)
+}
+func (m *DaySchedule) XXX_Size() int {
+	return xxx_messageInfo_DaySchedule.Size(m)
+}
+func (m *DaySchedule) XXX_DiscardUnknown() {
+	xxx_messageInfo_DaySchedule.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DaySchedule proto.InternalMessageInfo
+
+func (m *DaySchedule) GetWeekday() string {
+	if m != nil {
+		return m.Weekday
+	}
+	return ""
+}
+
+func (m *DaySchedule) GetIntervals() []*PowerOnInterval {
+	if m != nil {
+		return m.Intervals
+	}
+	return nil
+}
+
+type PowerSchedule struct {
+	// intervals by the weekday they start on
+	Days []*DaySchedule `protobuf:"bytes,1,rep,name=days,proto3" json:"days,omitempty"`
+	// IANA name of the timezone, e.g. "Europe/Lisbon". The system's timezone
+	// if empty.
+	Timezone string `protobuf:"bytes,2,opt,name=timezone,proto3" json:"timezone,omitempty"`
+	// dates on which the weekly intervals are skipped, e.g. vacations
+	Skips []*DateRange `protobuf:"bytes,3,rep,name=skips,proto3" json:"skips,omitempty"`
+	// intervals in addition to the weekly ones
+	Extras []*OneOffInterval `protobuf:"bytes,4,rep,name=extras,proto3" json:"extras,omitempty"`
+	// YYYY-MM-DD before which the weekly intervals are skipped, not paused if
+	// empty
+	PausedUntil          string   `protobuf:"bytes,5,opt,name=paused_until,json=pausedUntil,proto3" json:"paused_until,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PowerSchedule) Reset()         { *m = PowerSchedule{} }
+func (m *PowerSchedule) String() string { return proto.CompactTextString(m) }
+func (*PowerSchedule) ProtoMessage()    {}
+func (*PowerSchedule) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{44}
+}
+
+func (m *PowerSchedule) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PowerSchedule.Unmarshal(m, b)
+}
+func (m *PowerSchedule) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PowerSchedule.Marshal(b, m, deterministic)
+}
+func (m *PowerSchedule) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PowerSchedule.Merge(m, src)
+}
+func (m *PowerSchedule) XXX_Size() int {
+	return xxx_messageInfo_PowerSchedule.Size(m)
+}
+func (m *PowerSchedule) XXX_DiscardUnknown() {
+	xxx_messageInfo_PowerSchedule.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PowerSchedule proto.InternalMessageInfo
+
+func (m *PowerSchedule) GetDays() []*DaySchedule {
+	if m != nil {
+		return m.Days
+	}
+	return nil
+}
+
+func (m *PowerSchedule) GetTimezone() string {
+	if m != nil {
+		return m.Timezone
+	}
+	return ""
+}
+
+func (m *PowerSchedule) GetSkips() []*DateRange {
+	if m != nil {
+		return m.Skips
+	}
+	return nil
+}
+
+func (m *PowerSchedule) GetExtras() []*OneOffInterval {
+	if m != nil {
+		return m.Extras
+	}
+	return nil
+}
+
+func (m *PowerSchedule) GetPausedUntil() string {
+	if m != nil {
+		return m.PausedUntil
+	}
+	return ""
+}
+
+type DateRange struct {
+	// YYYY-MM-DD, both included
+	From                 string   `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To                   string   `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	Reason               string   `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DateRange) Reset()         { *m = DateRange{} }
+func (m *DateRange) String() string { return proto.CompactTextString(m) }
+func (*DateRange) ProtoMessage()    {}
+func (*DateRange) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{45}
+}
+
+func (m *DateRange) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DateRange.Unmarshal(m, b)
+}
+func (m *DateRange) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DateRange.Marshal(b, m, deterministic)
+}
+func (m *DateRange) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DateRange.Merge(m, src)
+}
+func (m *DateRange) XXX_Size() int {
+	return xxx_messageInfo_DateRange.Size(m)
+}
+func (m *DateRange) XXX_DiscardUnknown() {
+	xxx_messageInfo_DateRange.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DateRange proto.InternalMessageInfo
+
+func (m *DateRange) GetFrom() string {
+	if m != nil {
+		return m.From
+	}
+	return ""
+}
+
+func (m *DateRange) GetTo() string {
+	if m != nil {
+		return m.To
+	}
+	return ""
+}
+
+func (m *DateRange) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+type OneOffInterval struct {
+	// YYYY-MM-DD
+	Date                 string           `protobuf:"bytes,1,opt,name=date,proto3" json:"date,omitempty"`
+	Interval             *PowerOnInterval `protobuf:"bytes,2,opt,name=interval,proto3" json:"interval,omitempty"`
+	Reason               string           `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *OneOffInterval) Reset()         { *m = OneOffInterval{} }
+func (m *OneOffInterval) String() string { return proto.CompactTextString(m) }
+func (*OneOffInterval) ProtoMessage()    {}
+func (*OneOffInterval) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{46}
+}
+
+func (m *OneOffInterval) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_OneOffInterval.Unmarshal(m, b)
+}
+func (m *OneOffInterval) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_OneOffInterval.Marshal(b, m, deterministic)
+}
+func (m *OneOffInterval) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_OneOffInterval.Merge(m, src)
+}
+func (m *OneOffInterval) XXX_Size() int {
+	return xxx_messageInfo_OneOffInterval.Size(m)
+}
+func (m *OneOffInterval) XXX_DiscardUnknown() {
+	xxx_messageInfo_OneOffInterval.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_OneOffInterval proto.InternalMessageInfo
+
+func (m *OneOffInterval) GetDate() string {
+	if m != nil {
+		return m.Date
+	}
+	return ""
+}
+
+func (m *OneOffInterval) GetInterval() *PowerOnInterval {
+	if m != nil {
+		return m.Interval
+	}
+	return nil
+}
+
+func (m *OneOffInterval) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+type GetEffectiveScheduleRequest struct {
+	// number of days to list, default 7
+	Days                 int32    `protobuf:"varint,1,opt,name=days,proto3" json:"days,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetEffectiveScheduleRequest) Reset()         { *m = GetEffectiveScheduleRequest{} }
+func (m *GetEffectiveScheduleRequest) String() string { return proto.CompactTextString(m) }
+func (*GetEffectiveScheduleRequest) ProtoMessage()    {}
+func (*GetEffectiveScheduleRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{47}
+}
+
+func (m *GetEffectiveScheduleRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetEffectiveScheduleRequest.Unmarshal(m, b)
+}
+func (m *GetEffectiveScheduleRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetEffectiveScheduleRequest.Marshal(b, m, deterministic)
+}
+func (m *GetEffectiveScheduleRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetEffectiveScheduleRequest.Merge(m, src)
+}
+func (m *GetEffectiveScheduleRequest) XXX_Size() int {
+	return xxx_messageInfo_GetEffectiveScheduleRequest.Size(m)
+}
+func (m *GetEffectiveScheduleRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetEffectiveScheduleRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetEffectiveScheduleRequest proto.InternalMessageInfo
+
+func (m *GetEffectiveScheduleRequest) GetDays() int32 {
+	if m != nil {
+		return m.Days
+	}
+	return 0
+}
+
+type ScheduledWindow struct {
+	Start *timestamp.Timestamp `protobuf:"bytes,1,opt,name=start,proto3" json:"start,omitempty"`
+	End   *timestamp.Timestamp `protobuf:"bytes,2,opt,name=end,proto3" json:"end,omitempty"`
+	// whether the window comes from a one-off interval
+	OneOff               bool     `protobuf:"varint,3,opt,name=one_off,json=oneOff,proto3" json:"one_off,omitempty"`
+	Reason               string   `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ScheduledWindow) Reset()         { *m = ScheduledWindow{} }
+func (m *ScheduledWindow) String() string { return proto.CompactTextString(m) }
+func (*ScheduledWindow) ProtoMessage()    {}
+func (*ScheduledWindow) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{48}
+}
+
+func (m *ScheduledWindow) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ScheduledWindow.Unmarshal(m, b)
+}
+func (m *ScheduledWindow) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ScheduledWindow.Marshal(b, m, deterministic)
+}
+func (m *ScheduledWindow) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ScheduledWindow.Merge(m, src)
+}
+func (m *ScheduledWindow) XXX_Size() int {
+	return xxx_messageInfo_ScheduledWindow.Size(m)
+}
+func (m *ScheduledWindow) XXX_DiscardUnknown() {
+	xxx_messageInfo_ScheduledWindow.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ScheduledWindow proto.InternalMessageInfo
+
+func (m *ScheduledWindow) GetStart() *timestamp.Timestamp {
+	if m != nil {
+		return m.Start
+	}
+	return nil
+}
+
+func (m *ScheduledWindow) GetEnd() *timestamp.Timestamp {
+	if m != nil {
+		return m.End
+	}
+	return nil
+}
+
+func (m *ScheduledWindow) GetOneOff() bool {
+	if m != nil {
+		return m.OneOff
+	}
+	return false
+}
+
+func (m *ScheduledWindow) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+type EffectiveSchedule struct {
+	Windows              []*ScheduledWindow `protobuf:"bytes,1,rep,name=windows,proto3" json:"windows,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *EffectiveSchedule) Reset()         { *m = EffectiveSchedule{} }
+func (m *EffectiveSchedule) String() string { return proto.CompactTextString(m) }
+func (*EffectiveSchedule) ProtoMessage()    {}
+func (*EffectiveSchedule) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{49}
+}
+
+func (m *EffectiveSchedule) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EffectiveSchedule.Unmarshal(m, b)
+}
+func (m *EffectiveSchedule) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EffectiveSchedule.Marshal(b, m, deterministic)
+}
+func (m *EffectiveSchedule) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EffectiveSchedule.Merge(m, src)
+}
+func (m *EffectiveSchedule) XXX_Size() int {
+	return xxx_messageInfo_EffectiveSchedule.Size(m)
+}
+func (m *EffectiveSchedule) XXX_DiscardUnknown() {
+	xxx_messageInfo_EffectiveSchedule.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EffectiveSchedule proto.InternalMessageInfo
+
+func (m *EffectiveSchedule) GetWindows() []*ScheduledWindow {
+	if m != nil {
+		return m.Windows
+	}
+	return nil
+}
+
+type ImportCalendarRequest struct {
+	// contents of an iCalendar (.ics) file
+	Ics                  []byte   `protobuf:"bytes,1,opt,name=ics,proto3" json:"ics,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ImportCalendarRequest) Reset()         { *m = ImportCalendarRequest{} }
+func (m *ImportCalendarRequest) String() string { return proto.CompactTextString(m) }
+func (*ImportCalendarRequest) ProtoMessage()    {}
+func (*ImportCalendarRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{50}
+}
+
+func (m *ImportCalendarRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ImportCalendarRequest.Unmarshal(m, b)
+}
+func (m *ImportCalendarRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ImportCalendarRequest.Marshal(b, m, deterministic)
+}
+func (m *ImportCalendarRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ImportCalendarRequest.Merge(m, src)
+}
+func (m *ImportCalendarRequest) XXX_Size() int {
+	return xxx_messageInfo_ImportCalendarRequest.Size(m)
+}
+func (m *ImportCalendarRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ImportCalendarRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ImportCalendarRequest proto.InternalMessageInfo
+
+func (m *ImportCalendarRequest) GetIcs() []byte {
+	if m != nil {
+		return m.Ics
+	}
+	return nil
+}
+
+type ImportCalendarResponse struct {
+	// number of events in the calendar, not counting their recurrences
+	Events               int32    `protobuf:"varint,1,opt,name=events,proto3" json:"events,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ImportCalendarResponse) Reset()         { *m = ImportCalendarResponse{} }
+func (m *ImportCalendarResponse) String() string { return proto.CompactTextString(m) }
+func (*ImportCalendarResponse) ProtoMessage()    {}
+func (*ImportCalendarResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{51}
+}
+
+func (m *ImportCalendarResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ImportCalendarResponse.Unmarshal(m, b)
+}
+func (m *ImportCalendarResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ImportCalendarResponse.Marshal(b, m, deterministic)
+}
+func (m *ImportCalendarResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ImportCalendarResponse.Merge(m, src)
+}
+func (m *ImportCalendarResponse) XXX_Size() int {
+	return xxx_messageInfo_ImportCalendarResponse.Size(m)
+}
+func (m *ImportCalendarResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ImportCalendarResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ImportCalendarResponse proto.InternalMessageInfo
+
+func (m *ImportCalendarResponse) GetEvents() int32 {
+	if m != nil {
+		return m.Events
+	}
+	return 0
+}
+
+type RemoveCalendarRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RemoveCalendarRequest) Reset()         { *m = RemoveCalendarRequest{} }
+func (m *RemoveCalendarRequest) String() string { return proto.CompactTextString(m) }
+func (*RemoveCalendarRequest) ProtoMessage()    {}
+func (*RemoveCalendarRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{52}
+}
+
+func (m *RemoveCalendarRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RemoveCalendarRequest.Unmarshal(m, b)
+}
+func (m *RemoveCalendarRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RemoveCalendarRequest.Marshal(b, m, deterministic)
+}
+func (m *RemoveCalendarRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RemoveCalendarRequest.Merge(m, src)
+}
+func (m *RemoveCalendarRequest) XXX_Size() int {
+	return xxx_messageInfo_RemoveCalendarRequest.Size(m)
+}
+func (m *RemoveCalendarRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RemoveCalendarRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RemoveCalendarRequest proto.InternalMessageInfo
+
+type RemoveCalendarResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RemoveCalendarResponse) Reset()         { *m = RemoveCalendarResponse{} }
+func (m *RemoveCalendarResponse) String() string { return proto.CompactTextString(m) }
+func (*RemoveCalendarResponse) ProtoMessage()    {}
+func (*RemoveCalendarResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{53}
+}
+
+func (m *RemoveCalendarResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RemoveCalendarResponse.Unmarshal(m, b)
+}
+func (m *RemoveCalendarResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RemoveCalendarResponse.Marshal(b, m, deterministic)
+}
+func (m *RemoveCalendarResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RemoveCalendarResponse.Merge(m, src)
+}
+func (m *RemoveCalendarResponse) XXX_Size() int {
+	return xxx_messageInfo_RemoveCalendarResponse.Size(m)
+}
+func (m *RemoveCalendarResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_RemoveCalendarResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RemoveCalendarResponse proto.InternalMessageInfo
+
+type WatchAutoOffWarningsRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *WatchAutoOffWarningsRequest) Reset()         { *m = WatchAutoOffWarningsRequest{} }
+func (m *WatchAutoOffWarningsRequest) String() string { return proto.CompactTextString(m) }
+func (*WatchAutoOffWarningsRequest) ProtoMessage()    {}
+func (*WatchAutoOffWarningsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{54}
+}
+
+func (m *WatchAutoOffWarningsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WatchAutoOffWarningsRequest.Unmarshal(m, b)
+}
+func (m *WatchAutoOffWarningsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WatchAutoOffWarningsRequest.Marshal(b, m, deterministic)
+}
+func (m *WatchAutoOffWarningsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WatchAutoOffWarningsRequest.Merge(m, src)
+}
+func (m *WatchAutoOffWarningsRequest) XXX_Size() int {
+	return xxx_messageInfo_WatchAutoOffWarningsRequest.Size(m)
+}
+func (m *WatchAutoOffWarningsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_WatchAutoOffWarningsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WatchAutoOffWarningsRequest proto.InternalMessageInfo
+
+type AutoOffWarning struct {
+	At *timestamp.Timestamp `protobuf:"bytes,1,opt,name=at,proto3" json:"at,omitempty"`
+	// when the machine will be powered off unless it is used in the meantime
+	OffAt                *timestamp.Timestamp `protobuf:"bytes,2,opt,name=off_at,json=offAt,proto3" json:"off_at,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *AutoOffWarning) Reset()         { *m = AutoOffWarning{} }
+func (m *AutoOffWarning) String() string { return proto.CompactTextString(m) }
+func (*AutoOffWarning) ProtoMessage()    {}
+func (*AutoOffWarning) Descriptor() ([]byte, []int) {
+	return fileDescriptor_445399412d1702d2, []int{55}
+}
+
+func (m *AutoOffWarning) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AutoOffWarning.Unmarshal(m, b)
+}
+func (m *AutoOffWarning) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AutoOffWarning.Marshal(b, m, deterministic)
+}
+func (m *AutoOffWarning) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AutoOffWarning.Merge(m, src)
+}
+func (m *AutoOffWarning) XXX_Size() int {
+	return xxx_messageInfo_AutoOffWarning.Size(m)
+}
+func (m *AutoOffWarning) XXX_DiscardUnknown() {
+	xxx_messageInfo_AutoOffWarning.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AutoOffWarning proto.InternalMessageInfo
+
+func (m *AutoOffWarning) GetAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.At
+	}
+	return nil
+}
+
+func (m *AutoOffWarning) GetOffAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.OffAt
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*TemperatureSample)(nil), "espressopb.TemperatureSample")
+	proto.RegisterType((*TemperatureHistory)(nil), "espressopb.TemperatureHistory")
+	proto.RegisterType((*TemperatureStreamRequest)(nil), "espressopb.TemperatureStreamRequest")
+	proto.RegisterType((*TemperatureStreamResponse)(nil), "espressopb.TemperatureStreamResponse")
+	proto.RegisterType((*GetConfigurationRequest)(nil), "espressopb.GetConfigurationRequest")
+	proto.RegisterType((*Configuration)(nil), "espressopb.Configuration")
+	proto.RegisterMapType((map[string]float32)(nil), "espressopb.Configuration.ParametersEntry")
+	proto.RegisterType((*BoostProfile)(nil), "espressopb.BoostProfile")
+	proto.RegisterType((*BoostStep)(nil), "espressopb.BoostStep")
+	proto.RegisterType((*SteamConfiguration)(nil), "espressopb.SteamConfiguration")
+	proto.RegisterType((*AutotuneRequest)(nil), "espressopb.AutotuneRequest")
+	proto.RegisterType((*AutotuneProgress)(nil), "espressopb.AutotuneProgress")
+	proto.RegisterType((*AutotuneResult)(nil), "espressopb.AutotuneResult")
+	proto.RegisterType((*AutotuneResponse)(nil), "espressopb.AutotuneResponse")
+	proto.RegisterType((*GetSafetyStatusRequest)(nil), "espressopb.GetSafetyStatusRequest")
+	proto.RegisterType((*ResetFaultRequest)(nil), "espressopb.ResetFaultRequest")
+	proto.RegisterType((*SafetyStatus)(nil), "espressopb.SafetyStatus")
+	proto.RegisterType((*SetModeRequest)(nil), "espressopb.SetModeRequest")
+	proto.RegisterType((*WatchModeRequest)(nil), "espressopb.WatchModeRequest")
+	proto.RegisterType((*ModeStatus)(nil), "espressopb.ModeStatus")
+	proto.RegisterType((*WatchShotTimerRequest)(nil), "espressopb.WatchShotTimerRequest")
+	proto.RegisterType((*ShotTimer)(nil), "espressopb.ShotTimer")
+	proto.RegisterType((*ShotNotes)(nil), "espressopb.ShotNotes")
+	proto.RegisterType((*Shot)(nil), "espressopb.Shot")
+	proto.RegisterMapType((map[string]float32)(nil), "espressopb.Shot.ParametersEntry")
+	proto.RegisterType((*ListShotsRequest)(nil), "espressopb.ListShotsRequest")
+	proto.RegisterType((*ListShotsResponse)(nil), "espressopb.ListShotsResponse")
+	proto.RegisterType((*GetShotRequest)(nil), "espressopb.GetShotRequest")
+	proto.RegisterType((*UpdateShotNotesRequest)(nil), "espressopb.UpdateShotNotesRequest")
+	proto.RegisterType((*DeleteShotRequest)(nil), "espressopb.DeleteShotRequest")
+	proto.RegisterType((*DeleteShotResponse)(nil), "espressopb.DeleteShotResponse")
+	proto.RegisterType((*ExportShotsRequest)(nil), "espressopb.ExportShotsRequest")
+	proto.RegisterType((*ExportShotsResponse)(nil), "espressopb.ExportShotsResponse")
+	proto.RegisterType((*ListEventsRequest)(nil), "espressopb.ListEventsRequest")
+	proto.RegisterType((*Event)(nil), "espressopb.Event")
+	proto.RegisterMapType((map[string]string)(nil), "espressopb.Event.DetailsEntry")
+	proto.RegisterType((*ListEventsResponse)(nil), "espressopb.ListEventsResponse")
+	proto.RegisterType((*GetPowerStatusRequest)(nil), "espressopb.GetPowerStatusRequest")
+	proto.RegisterType((*PowerStatus)(nil), "espressopb.PowerStatus")
+	proto.RegisterType((*SetPowerRequest)(nil), "espressopb.SetPowerRequest")
+	proto.RegisterType((*TotalPowerOffRequest)(nil), "espressopb.TotalPowerOffRequest")
+	proto.RegisterType((*SetScheduleEnabledRequest)(nil), "espressopb.SetScheduleEnabledRequest")
+	proto.RegisterType((*WatchPowerStatusRequest)(nil), "espressopb.WatchPowerStatusRequest")
+	proto.RegisterType((*PowerOnIntervalRequest)(nil), "espressopb.PowerOnIntervalRequest")
+	proto.RegisterType((*GetPowerScheduleRequest)(nil), "espressopb.GetPowerScheduleRequest")
+	proto.RegisterType((*PowerOnInterval)(nil), "espressopb.PowerOnInterval")
+	proto.RegisterType((*DaySchedule)(nil), "espressopb.DaySchedule")
+	proto.RegisterType((*PowerSchedule)(nil), "espressopb.PowerSchedule")
+	proto.RegisterType((*DateRange)(nil), "espressopb.DateRange")
+	proto.RegisterType((*OneOffInterval)(nil), "espressopb.OneOffInterval")
+	proto.RegisterType((*GetEffectiveScheduleRequest)(nil), "espressopb.GetEffectiveScheduleRequest")
+	proto.RegisterType((*ScheduledWindow)(nil), "espressopb.ScheduledWindow")
+	proto.RegisterType((*EffectiveSchedule)(nil), "espressopb.EffectiveSchedule")
+	proto.RegisterType((*ImportCalendarRequest)(nil), "espressopb.ImportCalendarRequest")
+	proto.RegisterType((*ImportCalendarResponse)(nil), "espressopb.ImportCalendarResponse")
+	proto.RegisterType((*RemoveCalendarRequest)(nil), "espressopb.RemoveCalendarRequest")
+	proto.RegisterType((*RemoveCalendarResponse)(nil), "espressopb.RemoveCalendarResponse")
+	proto.RegisterType((*WatchAutoOffWarningsRequest)(nil), "espressopb.WatchAutoOffWarningsRequest")
+	proto.RegisterType((*AutoOffWarning)(nil), "espressopb.AutoOffWarning")
+}
+
+func init() {
+	proto.RegisterFile("espresso.proto", fileDescriptor_445399412d1702d2)
+}
+
+var fileDescriptor_445399412d1702d2 = []byte{
+	// 2608 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xcc, 0x59, 0x4b, 0x73, 0x1b, 0xc7,
+	0x11, 0xf6, 0xe2, 0x8d, 0x06, 0xc5, 0xc7, 0x98, 0x22, 0x21, 0xc8, 0x92, 0xe8, 0x95, 0x1f, 0xb2,
+	0x9d, 0xa2, 0x69, 0xc6, 0x2e, 0xd9, 0x72, 0x39, 0x65, 0xda, 0xa2, 0x49, 0xc9, 0xb2, 0xc9, 0x5a,
+	0x50, 0xd1, 0x29, 0x41, 0x0d, 0xb1, 0x0d, 0x70, 0x8b, 0xc0, 0xce, 0x66, 0x77, 0x96, 0x14, 0xf4,
+	0x0b, 0x72, 0xc8, 0x0f, 0xc8, 0x39, 0xb9, 0xe5, 0x90, 0x4a, 0x55, 0x0e, 0x39, 0xf9, 0x90, 0x5f,
+	0x90, 0x1c, 0x52, 0x39, 0xe5, 0xaf, 0xa4, 0x2a, 0x35, 0x8f, 0x5d, 0xec, 0x2c, 0x1e, 0x84, 0x52,
+	0x72, 0x2a, 0xb7, 0xed, 0x99, 0x9e, 0x9e, 0x9e, 0xee, 0x9e, 0xee, 0xaf, 0x67, 0x61, 0x19, 0xa3,
+	0x20, 0xc4, 0x28, 0x62, 0xdb, 0x41, 0xc8, 0x38, 0x23, 0x90, 0xd0, 0xc1, 0x69, 0xeb, 0x4e, 0x9f,
+	0xb1, 0xfe, 0x00, 0x3f, 0x94, 0x33, 0xa7, 0x71, 0xef, 0x43, 0xee, 0x0d, 0x31, 0xe2, 0x74, 0x18,
+	0x28, 0x66, 0xbb, 0x07, 0x6b, 0x27, 0x38, 0x0c, 0x30, 0xa4, 0x3c, 0x0e, 0xb1, 0x4d, 0x87, 0xc1,
+	0x00, 0xc9, 0x3a, 0x94, 0x2f, 0xe8, 0x20, 0xc6, 0xa6, 0xb5, 0x65, 0xdd, 0x2b, 0x38, 0x8a, 0x20,
+	0x9f, 0x43, 0x83, 0x9d, 0x46, 0x18, 0x5e, 0xa0, 0xdb, 0xa1, 0xbc, 0x59, 0xd8, 0xb2, 0xee, 0x35,
+	0x76, 0x5b, 0xdb, 0x6a, 0x87, 0xed, 0x64, 0x87, 0xed, 0x93, 0x64, 0x07, 0x07, 0x12, 0xf6, 0x3d,
+	0x6e, 0x7f, 0x07, 0x24, 0xb3, 0xcf, 0xa1, 0x17, 0x71, 0x16, 0x8e, 0xc8, 0x7d, 0xa8, 0x46, 0x72,
+	0xcb, 0xa8, 0x69, 0x6d, 0x15, 0xef, 0x35, 0x76, 0x6f, 0x6d, 0x8f, 0x95, 0xdf, 0x9e, 0x50, 0xcc,
+	0x49, 0xb8, 0xed, 0x16, 0x34, 0xb3, 0xb3, 0x3c, 0x44, 0x3a, 0x74, 0xf0, 0x57, 0x31, 0x46, 0xdc,
+	0xfe, 0xad, 0x05, 0x37, 0xa6, 0x4c, 0x46, 0x01, 0xf3, 0x23, 0x24, 0x0f, 0xa0, 0x7a, 0xa6, 0x76,
+	0x97, 0xa7, 0x6b, 0xec, 0xde, 0x9e, 0xb1, 0xa5, 0xd6, 0xf1, 0xf0, 0x35, 0x27, 0x59, 0x40, 0xee,
+	0x43, 0x45, 0x29, 0xa0, 0x0f, 0x3f, 0x5f, 0xdb, 0xc3, 0xd7, 0x1c, 0xcd, 0xfe, 0x55, 0x05, 0x4a,
+	0x2e, 0xe5, 0xd4, 0xbe, 0x01, 0x9b, 0x07, 0xc8, 0xbf, 0x66, 0x7e, 0xcf, 0xeb, 0xc7, 0x21, 0xe5,
+	0x1e, 0xf3, 0x13, 0xad, 0xff, 0x58, 0x84, 0x6b, 0xc6, 0x04, 0xd9, 0x82, 0x06, 0x1f, 0xcb, 0xd4,
+	0xbe, 0xc8, 0x0e, 0x91, 0x25, 0xb0, 0x02, 0xa9, 0x4a, 0xc1, 0xb1, 0x02, 0x41, 0x79, 0xcd, 0xa2,
+	0xa2, 0x3c, 0x41, 0xb9, 0xcd, 0x92, 0xa2, 0x5c, 0xf2, 0x11, 0x54, 0x22, 0xe4, 0xc2, 0x6d, 0xe5,
+	0x2b, 0xdd, 0x56, 0x8e, 0x90, 0xef, 0x71, 0xd2, 0x82, 0x5a, 0xc4, 0x43, 0xca, 0xb1, 0x3f, 0x6a,
+	0x56, 0xb6, 0xac, 0x7b, 0x75, 0x27, 0xa5, 0xc9, 0x23, 0x80, 0x80, 0x86, 0x74, 0x88, 0x1c, 0xc3,
+	0xa8, 0x59, 0x95, 0xae, 0x7b, 0x2f, 0x6b, 0x0c, 0xe3, 0x24, 0xdb, 0xc7, 0x29, 0xef, 0xbe, 0xcf,
+	0xc3, 0x91, 0x93, 0x59, 0x4c, 0x08, 0x94, 0x86, 0xcc, 0xc5, 0x66, 0x4d, 0x6e, 0x21, 0xbf, 0xc9,
+	0xc7, 0x50, 0x8e, 0x38, 0xd2, 0x61, 0xb3, 0x3e, 0xe9, 0xa1, 0xb6, 0x98, 0x30, 0x2d, 0xa8, 0x98,
+	0xc9, 0x36, 0x94, 0x4f, 0x19, 0x8b, 0x78, 0x13, 0xe4, 0xaa, 0x66, 0x76, 0xd5, 0x57, 0x62, 0xe2,
+	0x38, 0x64, 0x3d, 0x6f, 0x80, 0x8e, 0x62, 0x6b, 0x7d, 0x01, 0x2b, 0x39, 0xc5, 0xc8, 0x2a, 0x14,
+	0xcf, 0x51, 0x05, 0x46, 0xdd, 0x11, 0x9f, 0xe3, 0xab, 0x50, 0xc8, 0x5c, 0x85, 0x07, 0x85, 0x4f,
+	0x2d, 0xfb, 0x73, 0x58, 0xca, 0x4a, 0x25, 0x1f, 0x48, 0xa5, 0x83, 0x24, 0x92, 0xaf, 0x4f, 0x6c,
+	0xdf, 0xe6, 0x18, 0x38, 0x8a, 0xc7, 0xfe, 0x02, 0xea, 0xe9, 0x18, 0x69, 0x42, 0x35, 0xc2, 0x2e,
+	0xf3, 0xdd, 0x48, 0x3b, 0x39, 0x21, 0xc9, 0x06, 0x54, 0x58, 0xcc, 0x83, 0x98, 0xeb, 0xed, 0x35,
+	0x65, 0xbf, 0x00, 0x32, 0x69, 0x87, 0x57, 0x1a, 0x30, 0x4d, 0xa8, 0x8a, 0x54, 0xc1, 0x62, 0x15,
+	0x31, 0x05, 0x27, 0x21, 0xed, 0xbf, 0x59, 0xb0, 0xb2, 0x17, 0x73, 0xc6, 0x63, 0x1f, 0x75, 0xf0,
+	0xca, 0x58, 0x41, 0x1e, 0x30, 0xcf, 0xe7, 0x7a, 0xdb, 0x94, 0x26, 0x77, 0xa0, 0xa1, 0xb4, 0xee,
+	0x9c, 0x79, 0xfd, 0x33, 0xbd, 0x3b, 0xa8, 0xa1, 0x43, 0xaf, 0x7f, 0x46, 0x6e, 0x81, 0xa6, 0x3a,
+	0x03, 0x76, 0xa9, 0xf5, 0xa9, 0xab, 0x91, 0x27, 0xec, 0x92, 0xdc, 0x06, 0x38, 0x1b, 0x45, 0x1c,
+	0x43, 0x8c, 0xbc, 0x48, 0x2b, 0x98, 0x19, 0x11, 0x36, 0xea, 0x8e, 0xba, 0x22, 0x85, 0x08, 0x45,
+	0xcb, 0x8e, 0xa6, 0x44, 0x60, 0x85, 0xf1, 0x00, 0x75, 0xec, 0xca, 0x6f, 0xe1, 0x4d, 0x1a, 0x04,
+	0x83, 0x51, 0xb3, 0xba, 0x65, 0xdd, 0xab, 0x39, 0x8a, 0xb0, 0xff, 0x69, 0xc1, 0x6a, 0x72, 0xa2,
+	0xe3, 0x90, 0xf5, 0x85, 0xd7, 0x04, 0x6b, 0x70, 0x46, 0x23, 0xd4, 0xc1, 0xa0, 0x08, 0x31, 0x2a,
+	0xc5, 0xcb, 0x63, 0x94, 0x1d, 0x45, 0x64, 0x54, 0x28, 0x1a, 0x2a, 0xe4, 0x1c, 0x52, 0x9a, 0x74,
+	0xc8, 0x1d, 0x68, 0xb8, 0x31, 0x1f, 0x75, 0x7a, 0xb4, 0xcb, 0x59, 0xa8, 0x4d, 0x0d, 0x62, 0xe8,
+	0x1b, 0x39, 0x92, 0x4f, 0xba, 0x95, 0x97, 0x4a, 0xba, 0xff, 0xb0, 0x60, 0x79, 0xec, 0xaa, 0x28,
+	0x1e, 0xf0, 0xd4, 0x2a, 0x56, 0xc6, 0x2a, 0x77, 0xe1, 0x5a, 0x3c, 0xe0, 0xde, 0x90, 0x72, 0xec,
+	0xf4, 0xa9, 0xe7, 0x6b, 0x1f, 0x2d, 0x25, 0x83, 0x07, 0xd4, 0xf3, 0xc9, 0xbb, 0xb0, 0x92, 0x32,
+	0x05, 0x18, 0x7a, 0xcc, 0xd5, 0xae, 0x5a, 0x4e, 0x86, 0x8f, 0xe5, 0x28, 0x79, 0x03, 0xea, 0x22,
+	0xe9, 0x79, 0x3c, 0x76, 0x93, 0x23, 0x8f, 0x07, 0x54, 0x04, 0x96, 0x8d, 0x08, 0xac, 0x18, 0x11,
+	0x58, 0xcd, 0x44, 0xa0, 0x70, 0x8f, 0x87, 0xae, 0xcc, 0x0d, 0x35, 0x27, 0x21, 0xed, 0xdf, 0x64,
+	0xfc, 0x95, 0xc9, 0xeb, 0xb5, 0x40, 0xfb, 0x4e, 0x27, 0xf6, 0x37, 0xb2, 0x37, 0x30, 0xef, 0xdf,
+	0xc3, 0xd7, 0x9c, 0x94, 0x9f, 0x7c, 0x0c, 0x95, 0x50, 0x9a, 0x27, 0x2d, 0x6a, 0x53, 0x56, 0x2a,
+	0x03, 0x8a, 0xa4, 0xae, 0x78, 0xd3, 0xa4, 0xde, 0x84, 0x8d, 0x03, 0xe4, 0x6d, 0xda, 0x43, 0x3e,
+	0x6a, 0x73, 0xca, 0xe3, 0x28, 0xc9, 0xe9, 0xaf, 0xc3, 0x9a, 0x83, 0x11, 0xf2, 0x6f, 0x68, 0x3c,
+	0xe0, 0xc9, 0xe0, 0x9f, 0x2d, 0x58, 0xca, 0x32, 0x8b, 0x83, 0xf6, 0x04, 0x03, 0xba, 0x52, 0xf1,
+	0x9a, 0x93, 0x90, 0xc2, 0x59, 0xe7, 0x9e, 0xef, 0x4a, 0xad, 0xea, 0x8e, 0xfc, 0x16, 0xdc, 0x43,
+	0x8c, 0x22, 0xda, 0x47, 0x69, 0xff, 0xba, 0x93, 0x90, 0x0b, 0x44, 0xdb, 0x67, 0x00, 0x5a, 0xf4,
+	0x62, 0x95, 0xa0, 0xae, 0xb9, 0xf7, 0xb8, 0xfd, 0x16, 0x2c, 0xb7, 0x91, 0x7f, 0xc7, 0xdc, 0xf4,
+	0xce, 0x27, 0x89, 0xdb, 0x1a, 0x27, 0x6e, 0x9b, 0xc0, 0xea, 0x33, 0xca, 0xbb, 0x67, 0x19, 0x3e,
+	0xfb, 0xf7, 0x16, 0x80, 0xa0, 0xf5, 0x69, 0xa7, 0x2c, 0x13, 0xf7, 0x27, 0x44, 0x1a, 0x31, 0x5f,
+	0x9f, 0x54, 0x53, 0x64, 0x07, 0xca, 0x91, 0xe7, 0x77, 0xd5, 0x49, 0xaf, 0x2a, 0x5a, 0x82, 0x91,
+	0xdc, 0x87, 0x7a, 0x88, 0x17, 0x18, 0xca, 0x52, 0x57, 0xba, 0x72, 0x55, 0x4d, 0x31, 0xef, 0x71,
+	0x7b, 0x13, 0xae, 0x4b, 0xcd, 0xdb, 0x67, 0x8c, 0x8b, 0xf9, 0x30, 0x51, 0xff, 0x2f, 0x16, 0xd4,
+	0xd3, 0x41, 0x61, 0xfd, 0x30, 0xf6, 0x7d, 0xcf, 0xef, 0x27, 0xbe, 0xd2, 0xa4, 0xd4, 0x95, 0xd3,
+	0x70, 0x11, 0x5c, 0xa4, 0x18, 0xc9, 0x4f, 0xa0, 0x88, 0xbe, 0xbb, 0xc0, 0xd9, 0x04, 0x5b, 0xb6,
+	0x48, 0x94, 0x26, 0x8a, 0x44, 0xc4, 0xe2, 0xb0, 0x8b, 0xd2, 0xa3, 0x75, 0x47, 0x53, 0xf6, 0xa5,
+	0x52, 0xfc, 0x7b, 0xc6, 0x55, 0x36, 0x74, 0x59, 0x94, 0x14, 0x05, 0xf9, 0x2d, 0x92, 0xd9, 0xc8,
+	0xc3, 0x81, 0x9b, 0xd4, 0x36, 0x49, 0x08, 0xce, 0x53, 0xa4, 0xbe, 0x8e, 0x2e, 0xf9, 0x2d, 0x38,
+	0xfb, 0xa1, 0x88, 0xc4, 0x92, 0x4a, 0x86, 0x92, 0x90, 0x6e, 0xa3, 0x5c, 0xd8, 0x42, 0x67, 0x5e,
+	0x45, 0xd9, 0x3f, 0x94, 0xa0, 0x24, 0x76, 0x26, 0xcb, 0x50, 0xf0, 0x54, 0x50, 0x97, 0x9c, 0x82,
+	0xe7, 0xfe, 0xff, 0xd9, 0xc8, 0x28, 0x5c, 0x95, 0x5c, 0xe1, 0xca, 0x02, 0xa0, 0x6a, 0x0e, 0x00,
+	0x7d, 0x69, 0x00, 0xa0, 0x9a, 0xac, 0xf8, 0x5b, 0x06, 0x4c, 0x39, 0x63, 0x7c, 0x2e, 0xee, 0xf9,
+	0x12, 0x96, 0x4e, 0x99, 0x37, 0xc0, 0xb0, 0xc3, 0x43, 0xda, 0xc5, 0x66, 0x7d, 0x11, 0xfc, 0xdb,
+	0x50, 0x4b, 0x4e, 0xc4, 0x0a, 0xf2, 0x33, 0x68, 0xf4, 0x43, 0x16, 0x07, 0x5a, 0x00, 0x2c, 0x22,
+	0x00, 0xe4, 0x0a, 0xb5, 0xfe, 0x03, 0x28, 0xfb, 0x8c, 0x63, 0xd4, 0x6c, 0x6c, 0x59, 0x79, 0xc0,
+	0x92, 0x06, 0x8e, 0xa3, 0x78, 0x04, 0xb3, 0x02, 0x57, 0x4b, 0x73, 0xd1, 0xcd, 0x2b, 0x41, 0x56,
+	0x7f, 0xb0, 0x60, 0xf5, 0x89, 0x17, 0x71, 0xa1, 0x44, 0x92, 0x4b, 0xc9, 0x36, 0x94, 0x7a, 0x21,
+	0x1b, 0x36, 0xad, 0x2b, 0x43, 0x41, 0xf2, 0x91, 0xf7, 0xa1, 0xc0, 0xd9, 0x02, 0x81, 0x56, 0xe0,
+	0x4c, 0x20, 0x90, 0x80, 0xf6, 0xb1, 0xc3, 0xd9, 0x39, 0xaa, 0xc0, 0x2f, 0x39, 0x75, 0x31, 0x72,
+	0x22, 0x06, 0xc8, 0x4d, 0x90, 0x44, 0x27, 0xf2, 0x5e, 0xa8, 0xb4, 0x5a, 0x76, 0x6a, 0x62, 0xa0,
+	0xed, 0xbd, 0x40, 0xbb, 0x0b, 0x6b, 0x19, 0x5d, 0x75, 0x31, 0x7a, 0x07, 0xca, 0x91, 0x18, 0xd0,
+	0x58, 0x70, 0x35, 0x6f, 0x5a, 0x47, 0x4d, 0x93, 0x77, 0x60, 0xc5, 0xc7, 0xe7, 0xbc, 0x93, 0xd9,
+	0xbd, 0x20, 0x77, 0xbf, 0x26, 0x86, 0x8f, 0x13, 0x0d, 0xec, 0x2d, 0x58, 0x16, 0x25, 0x46, 0xac,
+	0xd4, 0xe6, 0xc8, 0x5d, 0x2d, 0xfb, 0x29, 0x6c, 0x3c, 0x0d, 0x5c, 0xca, 0x71, 0xec, 0xb9, 0xe9,
+	0x9c, 0x63, 0xb7, 0x17, 0xae, 0x76, 0xbb, 0x7d, 0x17, 0xd6, 0x1e, 0xe2, 0x00, 0x95, 0xd8, 0x59,
+	0x7b, 0xaf, 0x03, 0xc9, 0x32, 0x29, 0x1b, 0xd8, 0xbf, 0xb6, 0x80, 0xec, 0x3f, 0x0f, 0x58, 0x68,
+	0xfa, 0x71, 0x03, 0x2a, 0x3d, 0x16, 0x0e, 0x29, 0xd7, 0xb1, 0xa0, 0xa9, 0xd4, 0xbf, 0x85, 0x97,
+	0xf2, 0x6f, 0x71, 0x11, 0xff, 0xda, 0x4f, 0xe0, 0x75, 0x43, 0x13, 0xed, 0x25, 0xa2, 0x0a, 0xb8,
+	0x54, 0x64, 0xc9, 0x91, 0xdf, 0xe4, 0x4d, 0x58, 0xea, 0x32, 0x9f, 0xa3, 0xcf, 0x3b, 0x7c, 0x14,
+	0xa0, 0x2e, 0x48, 0x0d, 0x3d, 0x76, 0x32, 0x0a, 0xd0, 0xfe, 0xab, 0xa5, 0x5c, 0xbe, 0x7f, 0x81,
+	0xfe, 0xff, 0x26, 0x3e, 0xd7, 0xa1, 0x2c, 0x94, 0x11, 0xf0, 0xb2, 0x28, 0xd2, 0xaf, 0x24, 0x72,
+	0x51, 0x5b, 0x9a, 0x1b, 0xb5, 0xe5, 0x5c, 0xd4, 0xfe, 0xdb, 0x82, 0xb2, 0xd4, 0x7f, 0x22, 0x3c,
+	0xde, 0x87, 0xc2, 0x42, 0xcd, 0x7d, 0x81, 0x4a, 0x08, 0x20, 0x8d, 0xa4, 0x4b, 0x85, 0xf8, 0x96,
+	0x08, 0x99, 0xc6, 0x11, 0x26, 0xa5, 0x42, 0x12, 0x59, 0xd4, 0x52, 0x36, 0x51, 0xcb, 0xa7, 0x50,
+	0x75, 0x91, 0x53, 0x6f, 0x10, 0x35, 0x2b, 0x5b, 0xc5, 0x7c, 0xb7, 0x27, 0x75, 0xdc, 0x7e, 0xa8,
+	0x18, 0x54, 0x12, 0x4d, 0xd8, 0x5b, 0x0f, 0x60, 0x29, 0x3b, 0x71, 0x55, 0x8a, 0xa9, 0x67, 0x53,
+	0x4c, 0x1f, 0x48, 0xd6, 0x85, 0x3a, 0x20, 0xde, 0x83, 0x0a, 0xca, 0x11, 0x7d, 0x6f, 0xd7, 0x26,
+	0x54, 0x71, 0x34, 0xc3, 0xc2, 0x37, 0x77, 0x13, 0xae, 0x1f, 0x20, 0x3f, 0x66, 0x97, 0x18, 0x9a,
+	0xd8, 0xf0, 0x4f, 0x05, 0x68, 0x64, 0x86, 0x85, 0xae, 0x11, 0xa7, 0x3c, 0xed, 0x37, 0x24, 0xf1,
+	0x0a, 0x91, 0xd1, 0x0d, 0xa8, 0x05, 0x62, 0xbb, 0x0e, 0x53, 0xb1, 0x52, 0x73, 0xaa, 0x92, 0x3e,
+	0xf2, 0xc9, 0x27, 0x50, 0x63, 0x7e, 0x47, 0xc9, 0xbb, 0x1a, 0x14, 0x56, 0x99, 0xdf, 0x96, 0x12,
+	0x1f, 0x40, 0x83, 0xc6, 0x9c, 0x75, 0x58, 0xaf, 0xb7, 0x58, 0x6b, 0x52, 0x17, 0xec, 0x47, 0xbd,
+	0xde, 0x9e, 0x6c, 0x0a, 0x07, 0xe8, 0x76, 0x02, 0xca, 0x39, 0x86, 0xbe, 0x2e, 0xaf, 0x30, 0x40,
+	0xf7, 0x58, 0x8d, 0x4c, 0x7b, 0x16, 0xb0, 0xdf, 0x84, 0x95, 0xb6, 0xb6, 0x65, 0x26, 0x15, 0x31,
+	0x5f, 0x43, 0xb1, 0x02, 0xf3, 0xed, 0x0d, 0x58, 0x3f, 0x61, 0x9c, 0x0e, 0x24, 0xd3, 0x51, 0xaf,
+	0x97, 0x58, 0xfb, 0x13, 0xb8, 0xd1, 0x46, 0xde, 0xee, 0x9e, 0xa1, 0x1b, 0x0f, 0x70, 0xdf, 0xa7,
+	0xa7, 0x03, 0x74, 0x13, 0x21, 0x4d, 0xa8, 0xa2, 0x1a, 0x49, 0x40, 0x9d, 0x26, 0xc5, 0x7b, 0x8d,
+	0x44, 0x85, 0x53, 0xfc, 0x77, 0x0e, 0x1b, 0x6a, 0x13, 0xff, 0x91, 0xcf, 0x31, 0xbc, 0xa0, 0x83,
+	0x8c, 0xb8, 0x4b, 0xc4, 0x73, 0x97, 0x26, 0xb1, 0x98, 0x90, 0xe4, 0x3e, 0xd4, 0x3c, 0xcd, 0xac,
+	0x6f, 0xd8, 0xcd, 0x6c, 0x84, 0xe5, 0xe5, 0xa5, 0xcc, 0xfa, 0xdd, 0x48, 0x69, 0xa1, 0xcf, 0x90,
+	0xe8, 0xb1, 0x07, 0x2b, 0xb9, 0x75, 0xc2, 0x76, 0x32, 0x15, 0xe9, 0x6b, 0x29, 0xbe, 0x85, 0xa1,
+	0x38, 0xd3, 0x77, 0xb2, 0xc0, 0xd9, 0xe3, 0x52, 0xcd, 0x5a, 0x2d, 0x3c, 0x2e, 0xd5, 0x0a, 0xab,
+	0x45, 0xfb, 0x14, 0x1a, 0x0f, 0xe9, 0x28, 0x11, 0x3c, 0x47, 0xff, 0xcf, 0xa0, 0x9e, 0xa8, 0x24,
+	0xca, 0x47, 0xf1, 0xaa, 0x03, 0x8c, 0xb9, 0xed, 0x7f, 0x59, 0x70, 0xcd, 0xd0, 0x9f, 0x7c, 0x20,
+	0xb2, 0xef, 0x28, 0xb9, 0x6a, 0x9b, 0x59, 0x39, 0x19, 0x6d, 0x1c, 0xc9, 0x24, 0xb0, 0x98, 0x78,
+	0x7f, 0x78, 0xc1, 0xfc, 0xe4, 0x32, 0xa7, 0xb4, 0x7c, 0x78, 0x39, 0xf7, 0x02, 0x95, 0x1d, 0x73,
+	0x05, 0xed, 0x21, 0xe5, 0xe8, 0x50, 0xbf, 0x8f, 0x8e, 0xe2, 0x21, 0xbb, 0x50, 0xc1, 0xe7, 0x3c,
+	0xa4, 0x02, 0x21, 0x16, 0xf3, 0xad, 0xde, 0x91, 0x8f, 0x47, 0xbd, 0x5e, 0xaa, 0xbe, 0xe6, 0x14,
+	0x35, 0x21, 0x10, 0x59, 0xcc, 0xed, 0xc4, 0x3e, 0xf7, 0x06, 0x3a, 0x83, 0x35, 0xd4, 0xd8, 0x53,
+	0x31, 0x64, 0x1f, 0x40, 0x3d, 0xdd, 0x2a, 0xb5, 0xbf, 0x35, 0x61, 0xff, 0x42, 0x62, 0xff, 0xcc,
+	0xc5, 0x2e, 0x66, 0x2f, 0xb6, 0x1d, 0xc3, 0xb2, 0xa9, 0x85, 0xae, 0x52, 0x69, 0xc3, 0x24, 0xbe,
+	0xff, 0xeb, 0x40, 0x9a, 0xb9, 0xed, 0x47, 0x70, 0xf3, 0x00, 0xf9, 0x7e, 0xaf, 0x87, 0x5d, 0xee,
+	0x5d, 0x60, 0x2e, 0xc8, 0x08, 0x49, 0x7d, 0x25, 0xca, 0x88, 0xfc, 0xb6, 0x7f, 0x67, 0xc1, 0x4a,
+	0xc2, 0xe7, 0x3e, 0xf3, 0x7c, 0x97, 0x5d, 0x8e, 0x01, 0xbe, 0xf5, 0x92, 0x00, 0xbf, 0xb0, 0x18,
+	0xc0, 0xdf, 0x84, 0x2a, 0xf3, 0x51, 0x64, 0x1c, 0xa9, 0x7f, 0xcd, 0xa9, 0x30, 0x69, 0xac, 0xcc,
+	0xb9, 0x4a, 0xc6, 0xb9, 0x1e, 0xc3, 0xda, 0xc4, 0xa1, 0xc8, 0x27, 0x50, 0xbd, 0x94, 0xfa, 0x26,
+	0xc1, 0x67, 0x18, 0x2f, 0x77, 0x26, 0x27, 0xe1, 0xb5, 0xdf, 0x83, 0xeb, 0x8f, 0x86, 0x02, 0x45,
+	0x7c, 0x4d, 0x07, 0xe8, 0xbb, 0x34, 0x4d, 0x42, 0xab, 0x50, 0xf4, 0xba, 0x91, 0x86, 0x11, 0xe2,
+	0xd3, 0xde, 0x81, 0x8d, 0x3c, 0xab, 0x2e, 0x31, 0x1b, 0x99, 0x12, 0x23, 0x7b, 0x26, 0x45, 0x89,
+	0x3a, 0xe1, 0xe0, 0x90, 0x5d, 0x60, 0x4e, 0xb8, 0x78, 0x5d, 0xc8, 0x4f, 0x68, 0x80, 0x75, 0x0b,
+	0x6e, 0xca, 0xe4, 0xb4, 0xa7, 0xb2, 0xea, 0x33, 0x1a, 0x8a, 0x46, 0x34, 0x4d, 0x50, 0x0c, 0x96,
+	0xcd, 0x19, 0x5d, 0xda, 0xad, 0x85, 0x4a, 0xfb, 0x47, 0x50, 0xd1, 0x79, 0x7d, 0x81, 0x5e, 0x8d,
+	0x89, 0x9c, 0xbe, 0xfb, 0x03, 0x40, 0x6d, 0x5f, 0xdb, 0x91, 0x9c, 0xc2, 0xda, 0x57, 0xaa, 0x57,
+	0xc9, 0xbc, 0x3f, 0xbc, 0x35, 0xab, 0x39, 0xc9, 0xbe, 0xdf, 0xb7, 0xde, 0xbe, 0x82, 0x4b, 0x1d,
+	0x7f, 0xc7, 0x22, 0x14, 0x56, 0x0f, 0x64, 0x3b, 0xf3, 0xe3, 0x6d, 0xd1, 0x05, 0xb2, 0x37, 0x3c,
+	0xf5, 0x04, 0xf4, 0xfb, 0xf1, 0x36, 0x71, 0x60, 0x35, 0xff, 0x57, 0x80, 0xdc, 0xcd, 0x2e, 0x9e,
+	0xf1, 0xcf, 0xa0, 0x75, 0x63, 0xe6, 0x93, 0x3b, 0x39, 0x84, 0xd5, 0x76, 0x5e, 0xe6, 0x6c, 0xf6,
+	0x79, 0x92, 0x0e, 0xa0, 0x96, 0x3c, 0x81, 0x91, 0x9b, 0xd3, 0x1f, 0xc6, 0x94, 0x36, 0x6f, 0x4c,
+	0x9f, 0x4c, 0x8f, 0x79, 0x04, 0x2b, 0xb9, 0x77, 0x32, 0x62, 0xe7, 0x4e, 0x39, 0xe5, 0x11, 0xad,
+	0x65, 0xbc, 0xe3, 0x1b, 0xab, 0xf7, 0x01, 0xc6, 0xcf, 0x6b, 0xc4, 0xe8, 0x7c, 0x27, 0x9e, 0xdd,
+	0xe6, 0x88, 0xf9, 0x16, 0x60, 0x8c, 0x05, 0x4d, 0x31, 0x13, 0x30, 0xbf, 0x75, 0x7b, 0xd6, 0xb4,
+	0xbe, 0xdf, 0x5f, 0x40, 0x55, 0xbf, 0x93, 0x11, 0xa3, 0xb4, 0x98, 0x8f, 0x67, 0xad, 0x8d, 0xec,
+	0x5c, 0xe6, 0x75, 0xec, 0x6b, 0xa8, 0xa7, 0x0f, 0x68, 0xc4, 0x30, 0x68, 0xfe, 0x5d, 0x6d, 0x96,
+	0x88, 0x1d, 0x8b, 0x3c, 0x81, 0x65, 0xf3, 0x2d, 0x8b, 0xbc, 0x39, 0x21, 0x29, 0xff, 0xce, 0xd5,
+	0x9a, 0xe8, 0x03, 0xe5, 0xec, 0x8e, 0x45, 0x0e, 0xa1, 0x9e, 0x36, 0xb8, 0xa6, 0x4a, 0xf9, 0x1e,
+	0xbd, 0x75, 0x6b, 0xc6, 0xac, 0xb6, 0xcd, 0x7d, 0xa8, 0xea, 0x2e, 0xd6, 0xb4, 0x8d, 0xd9, 0xda,
+	0xb6, 0x26, 0xba, 0x65, 0x72, 0x00, 0x2b, 0xb9, 0xe6, 0xd6, 0x8c, 0x9c, 0xe9, 0x9d, 0xef, 0x14,
+	0x41, 0xdf, 0x02, 0x8c, 0x3b, 0x55, 0xd3, 0xd5, 0x13, 0x6d, 0x6e, 0xeb, 0xf6, 0xac, 0x69, 0x7d,
+	0x9c, 0xef, 0xa1, 0x91, 0xe9, 0x2a, 0x89, 0xd9, 0xb7, 0x4c, 0x34, 0xbe, 0xad, 0x3b, 0x33, 0xe7,
+	0x95, 0xbc, 0xdd, 0xbf, 0xd7, 0x60, 0x49, 0x41, 0x24, 0x0c, 0x2f, 0xbc, 0x2e, 0x0a, 0x3f, 0x9a,
+	0xbd, 0x83, 0xe9, 0xc7, 0xa9, 0x7d, 0x45, 0x6b, 0x73, 0x02, 0x08, 0xe8, 0xb5, 0x5f, 0x42, 0x2d,
+	0x41, 0xcf, 0xe6, 0x3d, 0xce, 0x61, 0xea, 0xd9, 0x12, 0x1e, 0xc3, 0x35, 0x03, 0x5c, 0x13, 0xe3,
+	0xc5, 0x6b, 0x1a, 0xee, 0x9e, 0x2d, 0xeb, 0x04, 0xc8, 0x24, 0x20, 0x27, 0x6f, 0xe7, 0xf4, 0x9a,
+	0x0e, 0xd8, 0x67, 0x4b, 0x75, 0xf4, 0xfb, 0x73, 0x76, 0xec, 0xee, 0x44, 0xec, 0xbf, 0x84, 0xd5,
+	0xd2, 0xec, 0x6c, 0x62, 0xd7, 0xbb, 0x53, 0xfd, 0x60, 0x82, 0x26, 0x33, 0xa7, 0x9a, 0xeb, 0x55,
+	0x76, 0x36, 0xc7, 0x66, 0xb3, 0xcf, 0x93, 0xf4, 0x4b, 0x58, 0x9f, 0x06, 0xdc, 0xc8, 0xbb, 0x39,
+	0x0d, 0x67, 0x41, 0x3b, 0xf3, 0xce, 0x4e, 0xca, 0x69, 0x03, 0xd9, 0x73, 0xdd, 0x7c, 0x87, 0x61,
+	0xcf, 0x43, 0x9b, 0x57, 0x1f, 0xff, 0xe7, 0x70, 0x5d, 0xdd, 0xa7, 0x57, 0x2c, 0xf7, 0x19, 0x2c,
+	0x9b, 0xb0, 0xcb, 0xbc, 0x30, 0x53, 0xd1, 0x5b, 0xcb, 0x9e, 0xc7, 0xa2, 0xaf, 0xfa, 0x33, 0x58,
+	0x36, 0x41, 0x98, 0x29, 0x78, 0x2a, 0x72, 0x6b, 0xd9, 0xf3, 0x58, 0xb4, 0xe0, 0x5f, 0xc0, 0xfa,
+	0x34, 0x0c, 0x67, 0xba, 0x6f, 0x0e, 0xca, 0x6b, 0x4d, 0xfc, 0xaa, 0x1a, 0xf3, 0xec, 0x58, 0xa7,
+	0x15, 0x89, 0xd6, 0x7e, 0xfa, 0x9f, 0x01, 0x00, 0x68, 0xf1, 0xf3, 0xdc, 0x23, 0x22, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// EspressoClient is the client API for Espresso service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type EspressoClient interface {
+	BoilerTemperature(ctx context.Context, in *TemperatureStreamRequest, opts ...grpc.CallOption) (Espresso_BoilerTemperatureClient, error)
+	// GroupTemperature and AmbientTemperature stream the optional ds18b20
+	// thermometers like BoilerTemperature, failing when none is configured
+	GroupTemperature(ctx context.Context, in *TemperatureStreamRequest, opts ...grpc.CallOption) (Espresso_GroupTemperatureClient, error)
+	AmbientTemperature(ctx context.Context, in *TemperatureStreamRequest, opts ...grpc.CallOption) (Espresso_AmbientTemperatureClient, error)
+	GetConfiguration(ctx context.Context, in *GetConfigurationRequest, opts ...grpc.CallOption) (*Configuration, error)
+	SetConfiguration(ctx context.Context, in *Configuration, opts ...grpc.CallOption) (*Configuration, error)
+	// Autotune finds pid gains by oscillating the boiler temperature around the
+	// setpoint. Temperature control is suspended until it finishes, the first
+	// messages report progress and the last one the result.
+	Autotune(ctx context.Context, in *AutotuneRequest, opts ...grpc.CallOption) (Espresso_AutotuneClient, error)
+	GetSafetyStatus(ctx context.Context, in *GetSafetyStatusRequest, opts ...grpc.CallOption) (*SafetyStatus, error)
+	// ResetFault clears a latched safety fault and enables the heating element
+	// again
+	ResetFault(ctx context.Context, in *ResetFaultRequest, opts ...grpc.CallOption) (*SafetyStatus, error)
+	// ListEvents pages through the log of power, configuration and safety
+	// events, newest first
+	ListEvents(ctx context.Context, in *ListEventsRequest, opts ...grpc.CallOption) (*ListEventsResponse, error)
+	// SetMode switches between brewing and steaming, which heats the boiler to
+	// the steam setpoint with the steam gains until the steam timeout. Steaming
+	// fails while the machine is powered off.
+	SetMode(ctx context.Context, in *SetModeRequest, opts ...grpc.CallOption) (*ModeStatus, error)
+	// WatchMode streams the current mode followed by the mode after every change
+	WatchMode(ctx context.Context, in *WatchModeRequest, opts ...grpc.CallOption) (Espresso_WatchModeClient, error)
+	// WatchShotTimer streams the shot being pulled, or the last one, followed by
+	// its elapsed time every 100ms while it is being pulled and by every shot
+	// started or stopped
+	WatchShotTimer(ctx context.Context, in *WatchShotTimerRequest, opts ...grpc.CallOption) (Espresso_WatchShotTimerClient, error)
+	// ListShots pages through the shots pulled, newest first, without their
+	// temperature traces
+	ListShots(ctx context.Context, in *ListShotsRequest, opts ...grpc.CallOption) (*ListShotsResponse, error)
+	GetShot(ctx context.Context, in *GetShotRequest, opts ...grpc.CallOption) (*Shot, error)
+	// UpdateShotNotes replaces the notes of a shot
+	UpdateShotNotes(ctx context.Context, in *UpdateShotNotesRequest, opts ...grpc.CallOption) (*Shot, error)
+	DeleteShot(ctx context.Context, in *DeleteShotRequest, opts ...grpc.CallOption) (*DeleteShotResponse, error)
+	// ExportShots writes the shots as csv, a row per shot without the
+	// temperature traces, or as json with them
+	ExportShots(ctx context.Context, in *ExportShotsRequest, opts ...grpc.CallOption) (*ExportShotsResponse, error)
+}
+
+type espressoClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewEspressoClient(cc grpc.ClientConnInterface) EspressoClient {
+	return &espressoClient{cc}
+}
+
+func (c *espressoClient) BoilerTemperature(ctx context.Context, in *TemperatureStreamRequest, opts ...grpc.CallOption) (Espresso_BoilerTemperatureClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Espresso_serviceDesc.Streams[0], "/espressopb.Espresso/BoilerTemperature", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &espressoBoilerTemperatureClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Espresso_BoilerTemperatureClient interface {
+	Recv() (*TemperatureStreamResponse, error)
+	grpc.ClientStream
+}
+
+type espressoBoilerTemperatureClient struct {
+	grpc.ClientStream
+}
+
+func (x *espressoBoilerTemperatureClient) Recv() (*TemperatureStreamResponse, error) {
+	m := new(TemperatureStreamResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *espressoClient) GroupTemperature(ctx context.Context, in *TemperatureStreamRequest, opts ...grpc.CallOption) (Espresso_GroupTemperatureClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Espresso_serviceDesc.Streams[1], "/espressopb.Espresso/GroupTemperature", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &espressoGroupTemperatureClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Espresso_GroupTemperatureClient interface {
+	Recv() (*TemperatureStreamResponse, error)
+	grpc.ClientStream
+}
+
+type espressoGroupTemperatureClient struct {
+	grpc.ClientStream
+}
+
+func (x *espressoGroupTemperatureClient) Recv() (*TemperatureStreamResponse, error) {
+	m := new(TemperatureStreamResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *espressoClient) AmbientTemperature(ctx context.Context, in *TemperatureStreamRequest, opts ...grpc.CallOption) (Espresso_AmbientTemperatureClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Espresso_serviceDesc.Streams[2], "/espressopb.Espresso/AmbientTemperature", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &espressoAmbientTemperatureClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Espresso_AmbientTemperatureClient interface {
+	Recv() (*TemperatureStreamResponse, error)
+	grpc.ClientStream
+}
+
+type espressoAmbientTemperatureClient struct {
+	grpc.ClientStream
+}
+
+func (x *espressoAmbientTemperatureClient) Recv() (*TemperatureStreamResponse, error) {
+	m := new(TemperatureStreamResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *espressoClient) GetConfiguration(ctx context.Context, in *GetConfigurationRequest, opts ...grpc.CallOption) (*Configuration, error) {
+	out := new(Configuration)
+	err := c.cc.Invoke(ctx, "/espressopb.Espresso/GetConfiguration", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *espressoClient) SetConfiguration(ctx context.Context, in *Configuration, opts ...grpc.CallOption) (*Configuration, error) {
+	out := new(Configuration)
+	err := c.cc.Invoke(ctx, "/espressopb.Espresso/SetConfiguration", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *espressoClient) Autotune(ctx context.Context, in *AutotuneRequest, opts ...grpc.CallOption) (Espresso_AutotuneClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Espresso_serviceDesc.Streams[3], "/espressopb.Espresso/Autotune", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &espressoAutotuneClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Espresso_AutotuneClient interface {
+	Recv() (*AutotuneResponse, error)
+	grpc.ClientStream
+}
+
+type espressoAutotuneClient struct {
+	grpc.ClientStream
+}
+
+func (x *espressoAutotuneClient) Recv() (*AutotuneResponse, error) {
+	m := new(AutotuneResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *espressoClient) GetSafetyStatus(ctx context.Context, in *GetSafetyStatusRequest, opts ...grpc.CallOption) (*SafetyStatus, error) {
+	out := new(SafetyStatus)
+	err := c.cc.Invoke(ctx, "/espressopb.Espresso/GetSafetyStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *espressoClient) ResetFault(ctx context.Context, in *ResetFaultRequest, opts ...grpc.CallOption) (*SafetyStatus, error) {
+	out := new(SafetyStatus)
+	err := c.cc.Invoke(ctx, "/espressopb.Espresso/ResetFault", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *espressoClient) ListEvents(ctx context.Context, in *ListEventsRequest, opts ...grpc.CallOption) (*ListEventsResponse, error) {
+	out := new(ListEventsResponse)
+	err := c.cc.Invoke(ctx, "/espressopb.Espresso/ListEvents", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *espressoClient) SetMode(ctx context.Context, in *SetModeRequest, opts ...grpc.CallOption) (*ModeStatus, error) {
+	out := new(ModeStatus)
+	err := c.cc.Invoke(ctx, "/espressopb.Espresso/SetMode", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *espressoClient) WatchMode(ctx context.Context, in *WatchModeRequest, opts ...grpc.CallOption) (Espresso_WatchModeClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Espresso_serviceDesc.Streams[4], "/espressopb.Espresso/WatchMode", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &espressoWatchModeClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Espresso_WatchModeClient interface {
+	Recv() (*ModeStatus, error)
+	grpc.ClientStream
+}
+
+type espressoWatchModeClient struct {
+	grpc.ClientStream
+}
+
+func (x *espressoWatchModeClient) Recv() (*ModeStatus, error) {
+	m := new(ModeStatus)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *espressoClient) WatchShotTimer(ctx context.Context, in *WatchShotTimerRequest, opts ...grpc.CallOption) (Espresso_WatchShotTimerClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Espresso_serviceDesc.Streams[5], "/espressopb.Espresso/WatchShotTimer", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &espressoWatchShotTimerClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Espresso_WatchShotTimerClient interface {
+	Recv() (*ShotTimer, error)
+	grpc.ClientStream
+}
+
+type espressoWatchShotTimerClient struct {
+	grpc.ClientStream
+}
+
+func (x *espressoWatchShotTimerClient) Recv() (*ShotTimer, error) {
+	m := new(ShotTimer)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *espressoClient) ListShots(ctx context.Context, in *ListShotsRequest, opts ...grpc.CallOption) (*ListShotsResponse, error) {
+	out := new(ListShotsResponse)
+	err := c.cc.Invoke(ctx, "/espressopb.Espresso/ListShots", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *espressoClient) GetShot(ctx context.Context, in *GetShotRequest, opts ...grpc.CallOption) (*Shot, error) {
+	out := new(Shot)
+	err := c.cc.Invoke(ctx, "/espressopb.Espresso/GetShot", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *espressoClient) UpdateShotNotes(ctx context.Context, in *UpdateShotNotesRequest, opts ...grpc.CallOption) (*Shot, error) {
+	out := new(Shot)
+	err := c.cc.Invoke(ctx, "/espressopb.Espresso/UpdateShotNotes", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *espressoClient) DeleteShot(ctx context.Context, in *DeleteShotRequest, opts ...grpc.CallOption) (*DeleteShotResponse, error) {
+	out := new(DeleteShotResponse)
+	err := c.cc.Invoke(ctx, "/espressopb.Espresso/DeleteShot", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *espressoClient) ExportShots(ctx context.Context, in *ExportShotsRequest, opts ...grpc.CallOption) (*ExportShotsResponse, error) {
+	out := new(ExportShotsResponse)
+	err := c.cc.Invoke(ctx, "/espressopb.Espresso/ExportShots", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// EspressoServer is the server API for Espresso service.
+type EspressoServer interface {
+	BoilerTemperature(*TemperatureStreamRequest, Espresso_BoilerTemperatureServer) error
+	// GroupTemperature and AmbientTemperature stream the optional ds18b20
+	// thermometers like BoilerTemperature, failing when none is configured
+	GroupTemperature(*TemperatureStreamRequest, Espresso_GroupTemperatureServer) error
+	AmbientTemperature(*TemperatureStreamRequest, Espresso_AmbientTemperatureServer) error
+	GetConfiguration(context.Context, *GetConfigurationRequest) (*Configuration, error)
+	SetConfiguration(context.Context, *Configuration) (*Configuration, error)
+	// Autotune finds pid gains by oscillating the boiler temperature around the
+	// setpoint. Temperature control is suspended until it finishes, the first
+	// messages report progress and the last one the result.
+	Autotune(*AutotuneRequest, Espresso_AutotuneServer) error
+	GetSafetyStatus(context.Context, *GetSafetyStatusRequest) (*SafetyStatus, error)
+	// ResetFault clears a latched safety fault and enables the heating element
+	// again
+	ResetFault(context.Context, *ResetFaultRequest) (*SafetyStatus, error)
+	// ListEvents pages through the log of power, configuration and safety
+	// events, newest first
+	ListEvents(context.Context, *ListEventsRequest) (*ListEventsResponse, error)
+	// SetMode switches between brewing and steaming, which heats the boiler to
+	// the steam setpoint with the steam gains until the steam timeout. Steaming
+	// fails while the machine is powered off.
+	SetMode(context.Context, *SetModeRequest) (*ModeStatus, error)
+	// WatchMode streams the current mode followed by the mode after every change
+	WatchMode(*WatchModeRequest, Espresso_WatchModeServer) error
+	// WatchShotTimer streams the shot being pulled, or the last one, followed by
+	// its elapsed time every 100ms while it is being pulled and by every shot
+	// started or stopped
+	WatchShotTimer(*WatchShotTimerRequest, Espresso_WatchShotTimerServer) error
+	// ListShots pages through the shots pulled, newest first, without their
+	// temperature traces
+	ListShots(context.Context, *ListShotsRequest) (*ListShotsResponse, error)
+	GetShot(context.Context, *GetShotRequest) (*Shot, error)
+	// UpdateShotNotes replaces the notes of a shot
+	UpdateShotNotes(context.Context, *UpdateShotNotesRequest) (*Shot, error)
+	DeleteShot(context.Context, *DeleteShotRequest) (*DeleteShotResponse, error)
+	// ExportShots writes the shots as csv, a row per shot without the
+	// temperature traces, or as json with them
+	ExportShots(context.Context, *ExportShotsRequest) (*ExportShotsResponse, error)
+}
+
+// UnimplementedEspressoServer can be embedded to have forward compatible implementations.
+type UnimplementedEspressoServer struct {
+}
+
+func (*UnimplementedEspressoServer) BoilerTemperature(req *TemperatureStreamRequest, srv Espresso_BoilerTemperatureServer) error {
+	return status.Errorf(codes.Unimplemented, "method BoilerTemperature not implemented")
+}
+func (*UnimplementedEspressoServer) GroupTemperature(req *TemperatureStreamRequest, srv Espresso_GroupTemperatureServer) error {
+	return status.Errorf(codes.Unimplemented, "method GroupTemperature not implemented")
+}
+func (*UnimplementedEspressoServer) AmbientTemperature(req *TemperatureStreamRequest, srv Espresso_AmbientTemperatureServer) error {
+	return status.Errorf(codes.Unimplemented, "method AmbientTemperature not implemented")
+}
+func (*UnimplementedEspressoServer) GetConfiguration(ctx context.Context, req *GetConfigurationRequest) (*Configuration, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetConfiguration not implemented")
+}
+func (*UnimplementedEspressoServer) SetConfiguration(ctx context.Context, req *Configuration) (*Configuration, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetConfiguration not implemented")
+}
+func (*UnimplementedEspressoServer) Autotune(req *AutotuneRequest, srv Espresso_AutotuneServer) error {
+	return status.Errorf(codes.Unimplemented, "method Autotune not implemented")
+}
+func (*UnimplementedEspressoServer) GetSafetyStatus(ctx context.Context, req *GetSafetyStatusRequest) (*SafetyStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSafetyStatus not implemented")
+}
+func (*UnimplementedEspressoServer) ResetFault(ctx context.Context, req *ResetFaultRequest) (*SafetyStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResetFault not implemented")
+}
+func (*UnimplementedEspressoServer) ListEvents(ctx context.Context, req *ListEventsRequest) (*ListEventsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListEvents not implemented")
+}
+func (*UnimplementedEspressoServer) SetMode(ctx context.Context, req *SetModeRequest) (*ModeStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetMode not implemented")
+}
+func (*UnimplementedEspressoServer) WatchMode(req *WatchModeRequest, srv Espresso_WatchModeServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchMode not implemented")
+}
+func (*UnimplementedEspressoServer) WatchShotTimer(req *WatchShotTimerRequest, srv Espresso_WatchShotTimerServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchShotTimer not implemented")
+}
+func (*UnimplementedEspressoServer) ListShots(ctx context.Context, req *ListShotsRequest) (*ListShotsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListShots not implemented")
+}
+func (*UnimplementedEspressoServer) GetShot(ctx context.Context, req *GetShotRequest) (*Shot, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetShot not implemented")
+}
+func (*UnimplementedEspressoServer) UpdateShotNotes(ctx context.Context, req *UpdateShotNotesRequest) (*Shot, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateShotNotes not implemented")
+}
+func (*UnimplementedEspressoServer) DeleteShot(ctx context.Context, req *DeleteShotRequest) (*DeleteShotResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteShot not implemented")
+}
+func (*UnimplementedEspressoServer) ExportShots(ctx context.Context, req *ExportShotsRequest) (*ExportShotsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportShots not implemented")
+}
+
+func RegisterEspressoServer(s *grpc.Server, srv EspressoServer) {
+	s.RegisterService(&_Espresso_serviceDesc, srv)
+}
+
+func _Espresso_BoilerTemperature_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TemperatureStreamRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(EspressoServer).BoilerTemperature(m, &espressoBoilerTemperatureServer{stream})
+}
+
+type Espresso_BoilerTemperatureServer interface {
+	Send(*TemperatureStreamResponse) error
+	grpc.ServerStream
+}
+
+type espressoBoilerTemperatureServer struct {
+	grpc.ServerStream
+}
+
+func (x *espressoBoilerTemperatureServer) Send(m *TemperatureStreamResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Espresso_GroupTemperature_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TemperatureStreamRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(EspressoServer).GroupTemperature(m, &espressoGroupTemperatureServer{stream})
+}
+
+type Espresso_GroupTemperatureServer interface {
+	Send(*TemperatureStreamResponse) error
+	grpc.ServerStream
+}
+
+type espressoGroupTemperatureServer struct {
+	grpc.ServerStream
+}
+
+func (x *espressoGroupTemperatureServer) Send(m *TemperatureStreamResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Espresso_AmbientTemperature_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TemperatureStreamRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(EspressoServer).AmbientTemperature(m, &espressoAmbientTemperatureServer{stream})
+}
+
+type Espresso_AmbientTemperatureServer interface {
+	Send(*TemperatureStreamResponse) error
+	grpc.ServerStream
+}
+
+type espressoAmbientTemperatureServer struct {
+	grpc.ServerStream
+}
+
+func (x *espressoAmbientTemperatureServer) Send(m *TemperatureStreamResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Espresso_GetConfiguration_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetConfigurationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EspressoServer).GetConfiguration(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/espressopb.Espresso/GetConfiguration",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EspressoServer).GetConfiguration(ctx, req.(*GetConfigurationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Espresso_SetConfiguration_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Configuration)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EspressoServer).SetConfiguration(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/espressopb.Espresso/SetConfiguration",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EspressoServer).SetConfiguration(ctx, req.(*Configuration))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Espresso_Autotune_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(AutotuneRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(EspressoServer).Autotune(m, &espressoAutotuneServer{stream})
+}
+
+type Espresso_AutotuneServer interface {
+	Send(*AutotuneResponse) error
+	grpc.ServerStream
+}
+
+type espressoAutotuneServer struct {
+	grpc.ServerStream
+}
+
+func (x *espressoAutotuneServer) Send(m *AutotuneResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Espresso_GetSafetyStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSafetyStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EspressoServer).GetSafetyStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/espressopb.Espresso/GetSafetyStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EspressoServer).GetSafetyStatus(ctx, req.(*GetSafetyStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Espresso_ResetFault_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResetFaultRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EspressoServer).ResetFault(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/espressopb.Espresso/ResetFault",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EspressoServer).ResetFault(ctx, req.(*ResetFaultRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Espresso_ListEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EspressoServer).ListEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/espressopb.Espresso/ListEvents",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EspressoServer).ListEvents(ctx, req.(*ListEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Espresso_SetMode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetModeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EspressoServer).SetMode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/espressopb.Espresso/SetMode",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EspressoServer).SetMode(ctx, req.(*SetModeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Espresso_WatchMode_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchModeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(EspressoServer).WatchMode(m, &espressoWatchModeServer{stream})
+}
+
+type Espresso_WatchModeServer interface {
+	Send(*ModeStatus) error
+	grpc.ServerStream
+}
+
+type espressoWatchModeServer struct {
+	grpc.ServerStream
+}
+
+func (x *espressoWatchModeServer) Send(m *ModeStatus) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Espresso_WatchShotTimer_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchShotTimerRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(EspressoServer).WatchShotTimer(m, &espressoWatchShotTimerServer{stream})
+}
+
+type Espresso_WatchShotTimerServer interface {
+	Send(*ShotTimer) error
+	grpc.ServerStream
+}
+
+type espressoWatchShotTimerServer struct {
+	grpc.ServerStream
+}
+
+func (x *espressoWatchShotTimerServer) Send(m *ShotTimer) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Espresso_ListShots_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListShotsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EspressoServer).ListShots(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/espressopb.Espresso/ListShots",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EspressoServer).ListShots(ctx, req.(*ListShotsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Espresso_GetShot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetShotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EspressoServer).GetShot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/espressopb.Espresso/GetShot",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EspressoServer).GetShot(ctx, req.(*GetShotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Espresso_UpdateShotNotes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateShotNotesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EspressoServer).UpdateShotNotes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/espressopb.Espresso/UpdateShotNotes",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EspressoServer).UpdateShotNotes(ctx, req.(*UpdateShotNotesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Espresso_DeleteShot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteShotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EspressoServer).DeleteShot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/espressopb.Espresso/DeleteShot",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EspressoServer).DeleteShot(ctx, req.(*DeleteShotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Espresso_ExportShots_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportShotsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EspressoServer).ExportShots(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/espressopb.Espresso/ExportShots",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EspressoServer).ExportShots(ctx, req.(*ExportShotsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Espresso_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "espressopb.Espresso",
+	HandlerType: (*EspressoServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetConfiguration",
+			Handler:    _Espresso_GetConfiguration_Handler,
+		},
+		{
+			MethodName: "SetConfiguration",
+			Handler:    _Espresso_SetConfiguration_Handler,
+		},
+		{
+			MethodName: "GetSafetyStatus",
+			Handler:    _Espresso_GetSafetyStatus_Handler,
+		},
+		{
+			MethodName: "ResetFault",
+			Handler:    _Espresso_ResetFault_Handler,
+		},
+		{
+			MethodName: "ListEvents",
+			Handler:    _Espresso_ListEvents_Handler,
+		},
+		{
+			MethodName: "SetMode",
+			Handler:    _Espresso_SetMode_Handler,
+		},
+		{
+			MethodName: "ListShots",
+			Handler:    _Espresso_ListShots_Handler,
+		},
+		{
+			MethodName: "GetShot",
+			Handler:    _Espresso_GetShot_Handler,
+		},
+		{
+			MethodName: "UpdateShotNotes",
+			Handler:    _Espresso_UpdateShotNotes_Handler,
+		},
+		{
+			MethodName: "DeleteShot",
+			Handler:    _Espresso_DeleteShot_Handler,
+		},
+		{
+			MethodName: "ExportShots",
+			Handler:    _Espresso_ExportShots_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "BoilerTemperature",
+			Handler:       _Espresso_BoilerTemperature_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "GroupTemperature",
+			Handler:       _Espresso_GroupTemperature_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "AmbientTemperature",
+			Handler:       _Espresso_AmbientTemperature_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Autotune",
+			Handler:       _Espresso_Autotune_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchMode",
+			Handler:       _Espresso_WatchMode_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchShotTimer",
+			Handler:       _Espresso_WatchShotTimer_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "espresso.proto",
+}
+
+// PowerServiceClient is the client API for PowerService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type PowerServiceClient interface {
+	GetPowerStatus(ctx context.Context, in *GetPowerStatusRequest, opts ...grpc.CallOption) (*PowerStatus, error)
+	// SetPower powers the machine on until auto-off, also after a total power
+	// off, or off, suppressing the current scheduled window. Powering on fails
+	// during a safety fault.
+	SetPower(ctx context.Context, in *SetPowerRequest, opts ...grpc.CallOption) (*PowerStatus, error)
+	// TotalPowerOff powers off the machine and ignores the schedule until it is
+	// powered on again
+	TotalPowerOff(ctx context.Context, in *TotalPowerOffRequest, opts ...grpc.CallOption) (*PowerStatus, error)
+	// SetScheduleEnabled resumes or suppresses the current scheduled window.
+	// It fails with FailedPrecondition outside of a scheduled window.
+	SetScheduleEnabled(ctx context.Context, in *SetScheduleEnabledRequest, opts ...grpc.CallOption) (*PowerStatus, error)
+	// WatchPowerStatus streams the current status followed by the status after
+	// every change
+	WatchPowerStatus(ctx context.Context, in *WatchPowerStatusRequest, opts ...grpc.CallOption) (PowerService_WatchPowerStatusClient, error)
+	GetPowerSchedule(ctx context.Context, in *GetPowerScheduleRequest, opts ...grpc.CallOption) (*PowerSchedule, error)
+	// SetPowerSchedule replaces the weekly power schedule and saves it so it
+	// survives restarts. Intervals out of range or overlapping are rejected.
+	SetPowerSchedule(ctx context.Context, in *PowerSchedule, opts ...grpc.CallOption) (*PowerSchedule, error)
+	// GetEffectiveSchedule lists the power-on windows of the coming days, with
+	// skipped dates and one-off intervals applied
+	GetEffectiveSchedule(ctx context.Context, in *GetEffectiveScheduleRequest, opts ...grpc.CallOption) (*EffectiveSchedule, error)
+	// AddPowerOnInterval adds an interval to the weekly schedule, rejecting it if
+	// it overlaps another one
+	AddPowerOnInterval(ctx context.Context, in *PowerOnIntervalRequest, opts ...grpc.CallOption) (*PowerSchedule, error)
+	DeletePowerOnInterval(ctx context.Context, in *PowerOnIntervalRequest, opts ...grpc.CallOption) (*PowerSchedule, error)
+	// ImportCalendar replaces the calendar whose events power on the machine in
+	// addition to the weekly schedule. Invalid calendars are rejected.
+	ImportCalendar(ctx context.Context, in *ImportCalendarRequest, opts ...grpc.CallOption) (*ImportCalendarResponse, error)
+	RemoveCalendar(ctx context.Context, in *RemoveCalendarRequest, opts ...grpc.CallOption) (*RemoveCalendarResponse, error)
+	// WatchAutoOffWarnings streams a warning shortly before a machine powered on
+	// outside of the schedule is powered off automatically
+	WatchAutoOffWarnings(ctx context.Context, in *WatchAutoOffWarningsRequest, opts ...grpc.CallOption) (PowerService_WatchAutoOffWarningsClient, error)
+}
+
+type powerServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPowerServiceClient(cc grpc.ClientConnInterface) PowerServiceClient {
+	return &powerServiceClient{cc}
+}
+
+func (c *powerServiceClient) GetPowerStatus(ctx context.Context, in *GetPowerStatusRequest, opts ...grpc.CallOption) (*PowerStatus, error) {
+	out := new(PowerStatus)
+	err := c.cc.Invoke(ctx, "/espressopb.PowerService/GetPowerStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *powerServiceClient) SetPower(ctx context.Context, in *SetPowerRequest, opts ...grpc.CallOption) (*PowerStatus, error) {
+	out := new(PowerStatus)
+	err := c.cc.Invoke(ctx, "/espressopb.PowerService/SetPower", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *powerServiceClient) TotalPowerOff(ctx context.Context, in *TotalPowerOffRequest, opts ...grpc.CallOption) (*PowerStatus, error) {
+	out := new(PowerStatus)
+	err := c.cc.Invoke(ctx, "/espressopb.PowerService/TotalPowerOff", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *powerServiceClient) SetScheduleEnabled(ctx context.Context, in *SetScheduleEnabledRequest, opts ...grpc.CallOption) (*PowerStatus, error) {
+	out := new(PowerStatus)
+	err := c.cc.Invoke(ctx, "/espressopb.PowerService/SetScheduleEnabled", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *powerServiceClient) WatchPowerStatus(ctx context.Context, in *WatchPowerStatusRequest, opts ...grpc.CallOption) (PowerService_WatchPowerStatusClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_PowerService_serviceDesc.Streams[0], "/espressopb.PowerService/WatchPowerStatus", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &powerServiceWatchPowerStatusClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type PowerService_WatchPowerStatusClient interface {
+	Recv() (*PowerStatus, error)
+	grpc.ClientStream
+}
+
+type powerServiceWatchPowerStatusClient struct {
+	grpc.ClientStream
+}
+
+func (x *powerServiceWatchPowerStatusClient) Recv() (*PowerStatus, error) {
+	m := new(PowerStatus)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *powerServiceClient) GetPowerSchedule(ctx context.Context, in *GetPowerScheduleRequest, opts ...grpc.CallOption) (*PowerSchedule, error) {
+	out := new(PowerSchedule)
+	err := c.cc.Invoke(ctx, "/espressopb.PowerService/GetPowerSchedule", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *powerServiceClient) SetPowerSchedule(ctx context.Context, in *PowerSchedule, opts ...grpc.CallOption) (*PowerSchedule, error) {
+	out := new(PowerSchedule)
+	err := c.cc.Invoke(ctx, "/espressopb.PowerService/SetPowerSchedule", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *powerServiceClient) GetEffectiveSchedule(ctx context.Context, in *GetEffectiveScheduleRequest, opts ...grpc.CallOption) (*EffectiveSchedule, error) {
+	out := new(EffectiveSchedule)
+	err := c.cc.Invoke(ctx, "/espressopb.PowerService/GetEffectiveSchedule", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *powerServiceClient) AddPowerOnInterval(ctx context.Context, in *PowerOnIntervalRequest, opts ...grpc.CallOption) (*PowerSchedule, error) {
+	out := new(PowerSchedule)
+	err := c.cc.Invoke(ctx, "/espressopb.PowerService/AddPowerOnInterval", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *powerServiceClient) DeletePowerOnInterval(ctx context.Context, in *PowerOnIntervalRequest, opts ...grpc.CallOption) (*PowerSchedule, error) {
+	out := new(PowerSchedule)
+	err := c.cc.Invoke(ctx, "/espressopb.PowerService/DeletePowerOnInterval", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *powerServiceClient) ImportCalendar(ctx context.Context, in *ImportCalendarRequest, opts ...grpc.CallOption) (*ImportCalendarResponse, error) {
+	out := new(ImportCalendarResponse)
+	err := c.cc.Invoke(ctx, "/espressopb.PowerService/ImportCalendar", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *powerServiceClient) RemoveCalendar(ctx context.Context, in *RemoveCalendarRequest, opts ...grpc.CallOption) (*RemoveCalendarResponse, error) {
+	out := new(RemoveCalendarResponse)
+	err := c.cc.Invoke(ctx, "/espressopb.PowerService/RemoveCalendar", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *powerServiceClient) WatchAutoOffWarnings(ctx context.Context, in *WatchAutoOffWarningsRequest, opts ...grpc.CallOption) (PowerService_WatchAutoOffWarningsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_PowerService_serviceDesc.Streams[1], "/espressopb.PowerService/WatchAutoOffWarnings", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &powerServiceWatchAutoOffWarningsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type PowerService_WatchAutoOffWarningsClient interface {
+	Recv() (*AutoOffWarning, error)
+	grpc.ClientStream
+}
+
+type powerServiceWatchAutoOffWarningsClient struct {
+	grpc.ClientStream
+}
+
+func (x *powerServiceWatchAutoOffWarningsClient) Recv() (*AutoOffWarning, error) {
+	m := new(AutoOffWarning)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// PowerServiceServer is the server API for PowerService service.
+type PowerServiceServer interface {
+	GetPowerStatus(context.Context, *GetPowerStatusRequest) (*PowerStatus, error)
+	// SetPower powers the machine on until auto-off, also after a total power
+	// off, or off, suppressing the current scheduled window. Powering on fails
+	// during a safety fault.
+	SetPower(context.Context, *SetPowerRequest) (*PowerStatus, error)
+	// TotalPowerOff powers off the machine and ignores the schedule until it is
+	// powered on again
+	TotalPowerOff(context.Context, *TotalPowerOffRequest) (*PowerStatus, error)
+	// SetScheduleEnabled resumes or suppresses the current scheduled window.
+	// It fails with FailedPrecondition outside of a scheduled window.
+	SetScheduleEnabled(context.Context, *SetScheduleEnabledRequest) (*PowerStatus, error)
+	// WatchPowerStatus streams the current status followed by the status after
+	// every change
+	WatchPowerStatus(*WatchPowerStatusRequest, PowerService_WatchPowerStatusServer) error
+	GetPowerSchedule(context.Context, *GetPowerScheduleRequest) (*PowerSchedule, error)
+	// SetPowerSchedule replaces the weekly power schedule and saves it so it
+	// survives restarts. Intervals out of range or overlapping are rejected.
+	SetPowerSchedule(context.Context, *PowerSchedule) (*PowerSchedule, error)
+	// GetEffectiveSchedule lists the power-on windows of the coming days, with
+	// skipped dates and one-off intervals applied
+	GetEffectiveSchedule(context.Context, *GetEffectiveScheduleRequest) (*EffectiveSchedule, error)
+	// AddPowerOnInterval adds an interval to the weekly schedule, rejecting it if
+	// it overlaps another one
+	AddPowerOnInterval(context.Context, *PowerOnIntervalRequest) (*PowerSchedule, error)
+	DeletePowerOnInterval(context.Context, *PowerOnIntervalRequest) (*PowerSchedule, error)
+	// ImportCalendar replaces the calendar whose events power on the machine in
+	// addition to the weekly schedule. Invalid calendars are rejected.
+	ImportCalendar(context.Context, *ImportCalendarRequest) (*ImportCalendarResponse, error)
+	RemoveCalendar(context.Context, *RemoveCalendarRequest) (*RemoveCalendarResponse, error)
+	// WatchAutoOffWarnings streams a warning shortly before a machine powered on
+	// outside of the schedule is powered off automatically
+	WatchAutoOffWarnings(*WatchAutoOffWarningsRequest, PowerService_WatchAutoOffWarningsServer) error
+}
+
+// UnimplementedPowerServiceServer can be embedded to have forward compatible implementations.
+type UnimplementedPowerServiceServer struct {
+}
+
+func (*UnimplementedPowerServiceServer) GetPowerStatus(ctx context.Context, req *GetPowerStatusRequest) (*PowerStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPowerStatus not implemented")
+}
+func (*UnimplementedPowerServiceServer) SetPower(ctx context.Context, req *SetPowerRequest) (*PowerStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetPower not implemented")
+}
+func (*UnimplementedPowerServiceServer) TotalPowerOff(ctx context.Context, req *TotalPowerOffRequest) (*PowerStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TotalPowerOff not implemented")
+}
+func (*UnimplementedPowerServiceServer) SetScheduleEnabled(ctx context.Context, req *SetScheduleEnabledRequest) (*PowerStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetScheduleEnabled not implemented")
+}
+func (*UnimplementedPowerServiceServer) WatchPowerStatus(req *WatchPowerStatusRequest, srv PowerService_WatchPowerStatusServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchPowerStatus not implemented")
+}
+func (*UnimplementedPowerServiceServer) GetPowerSchedule(ctx context.Context, req *GetPowerScheduleRequest) (*PowerSchedule, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPowerSchedule not implemented")
+}
+func (*UnimplementedPowerServiceServer) SetPowerSchedule(ctx context.Context, req *PowerSchedule) (*PowerSchedule, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetPowerSchedule not implemented")
+}
+func (*UnimplementedPowerServiceServer) GetEffectiveSchedule(ctx context.Context, req *GetEffectiveScheduleRequest) (*EffectiveSchedule, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetEffectiveSchedule not implemented")
+}
+func (*UnimplementedPowerServiceServer) AddPowerOnInterval(ctx context.Context, req *PowerOnIntervalRequest) (*PowerSchedule, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddPowerOnInterval not implemented")
+}
+func (*UnimplementedPowerServiceServer) DeletePowerOnInterval(ctx context.Context, req *PowerOnIntervalRequest) (*PowerSchedule, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeletePowerOnInterval not implemented")
+}
+func (*UnimplementedPowerServiceServer) ImportCalendar(ctx context.Context, req *ImportCalendarRequest) (*ImportCalendarResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ImportCalendar not implemented")
+}
+func (*UnimplementedPowerServiceServer) RemoveCalendar(ctx context.Context, req *RemoveCalendarRequest) (*RemoveCalendarResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveCalendar not implemented")
+}
+func (*UnimplementedPowerServiceServer) WatchAutoOffWarnings(req *WatchAutoOffWarningsRequest, srv PowerService_WatchAutoOffWarningsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchAutoOffWarnings not implemented")
+}
+
+func RegisterPowerServiceServer(s *grpc.Server, srv PowerServiceServer) {
+	s.RegisterService(&_PowerService_serviceDesc, srv)
+}
+
+func _PowerService_GetPowerStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPowerStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PowerServiceServer).GetPowerStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/espressopb.PowerService/GetPowerStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PowerServiceServer).GetPowerStatus(ctx, req.(*GetPowerStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PowerService_SetPower_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetPowerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PowerServiceServer).SetPower(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/espressopb.PowerService/SetPower",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PowerServiceServer).SetPower(ctx, req.(*SetPowerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PowerService_TotalPowerOff_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TotalPowerOffRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PowerServiceServer).TotalPowerOff(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/espressopb.PowerService/TotalPowerOff",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PowerServiceServer).TotalPowerOff(ctx, req.(*TotalPowerOffRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PowerService_SetScheduleEnabled_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetScheduleEnabledRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PowerServiceServer).SetScheduleEnabled(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/espressopb.PowerService/SetScheduleEnabled",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PowerServiceServer).SetScheduleEnabled(ctx, req.(*SetScheduleEnabledRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PowerService_WatchPowerStatus_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchPowerStatusRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PowerServiceServer).WatchPowerStatus(m, &powerServiceWatchPowerStatusServer{stream})
+}
+
+type PowerService_WatchPowerStatusServer interface {
+	Send(*PowerStatus) error
+	grpc.ServerStream
+}
+
+type powerServiceWatchPowerStatusServer struct {
+	grpc.ServerStream
+}
+
+func (x *powerServiceWatchPowerStatusServer) Send(m *PowerStatus) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _PowerService_GetPowerSchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPowerScheduleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PowerServiceServer).GetPowerSchedule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/espressopb.PowerService/GetPowerSchedule",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PowerServiceServer).GetPowerSchedule(ctx, req.(*GetPowerScheduleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PowerService_SetPowerSchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PowerSchedule)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PowerServiceServer).SetPowerSchedule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/espressopb.PowerService/SetPowerSchedule",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PowerServiceServer).SetPowerSchedule(ctx, req.(*PowerSchedule))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PowerService_GetEffectiveSchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetEffectiveScheduleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PowerServiceServer).GetEffectiveSchedule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/espressopb.PowerService/GetEffectiveSchedule",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PowerServiceServer).GetEffectiveSchedule(ctx, req.(*GetEffectiveScheduleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PowerService_AddPowerOnInterval_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PowerOnIntervalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PowerServiceServer).AddPowerOnInterval(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/espressopb.PowerService/AddPowerOnInterval",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PowerServiceServer).AddPowerOnInterval(ctx, req.(*PowerOnIntervalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PowerService_DeletePowerOnInterval_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PowerOnIntervalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PowerServiceServer).DeletePowerOnInterval(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/espressopb.PowerService/DeletePowerOnInterval",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PowerServiceServer).DeletePowerOnInterval(ctx, req.(*PowerOnIntervalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PowerService_ImportCalendar_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImportCalendarRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PowerServiceServer).ImportCalendar(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/espressopb.PowerService/ImportCalendar",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PowerServiceServer).ImportCalendar(ctx, req.(*ImportCalendarRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PowerService_RemoveCalendar_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveCalendarRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PowerServiceServer).RemoveCalendar(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/espressopb.PowerService/RemoveCalendar",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PowerServiceServer).RemoveCalendar(ctx, req.(*RemoveCalendarRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PowerService_WatchAutoOffWarnings_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchAutoOffWarningsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PowerServiceServer).WatchAutoOffWarnings(m, &powerServiceWatchAutoOffWarningsServer{stream})
+}
+
+type PowerService_WatchAutoOffWarningsServer interface {
+	Send(*AutoOffWarning) error
+	grpc.ServerStream
+}
+
+type powerServiceWatchAutoOffWarningsServer struct {
+	grpc.ServerStream
+}
+
+func (x *powerServiceWatchAutoOffWarningsServer) Send(m *AutoOffWarning) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _PowerService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "espressopb.PowerService",
+	HandlerType: (*PowerServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
 		{
-			MethodName: "SetConfiguration",
-			Handler:    _Espresso_SetConfiguration_Handler,
+			MethodName: "GetPowerStatus",
+			Handler:    _PowerService_GetPowerStatus_Handler,
+		},
+		{
+			MethodName: "SetPower",
+			Handler:    _PowerService_SetPower_Handler,
+		},
+		{
+			MethodName: "TotalPowerOff",
+			Handler:    _PowerService_TotalPowerOff_Handler,
+		},
+		{
+			MethodName: "SetScheduleEnabled",
+			Handler:    _PowerService_SetScheduleEnabled_Handler,
+		},
+		{
+			MethodName: "GetPowerSchedule",
+			Handler:    _PowerService_GetPowerSchedule_Handler,
+		},
+		{
+			MethodName: "SetPowerSchedule",
+			Handler:    _PowerService_SetPowerSchedule_Handler,
+		},
+		{
+			MethodName: "GetEffectiveSchedule",
+			Handler:    _PowerService_GetEffectiveSchedule_Handler,
+		},
+		{
+			MethodName: "AddPowerOnInterval",
+			Handler:    _PowerService_AddPowerOnInterval_Handler,
+		},
+		{
+			MethodName: "DeletePowerOnInterval",
+			Handler:    _PowerService_DeletePowerOnInterval_Handler,
+		},
+		{
+			MethodName: "ImportCalendar",
+			Handler:    _PowerService_ImportCalendar_Handler,
+		},
+		{
+			MethodName: "RemoveCalendar",
+			Handler:    _PowerService_RemoveCalendar_Handler,
 		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
-			StreamName:    "BoilerTemperature",
-			Handler:       _Espresso_BoilerTemperature_Handler,
+			StreamName:    "WatchPowerStatus",
+			Handler:       _PowerService_WatchPowerStatus_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchAutoOffWarnings",
+			Handler:       _PowerService_WatchAutoOffWarnings_Handler,
 			ServerStreams: true,
 		},
 	},